@@ -1,13 +1,12 @@
 package main
 
 import (
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -30,17 +29,19 @@ import (
 	"github.com/konflux-ci/e2e-tests/magefiles/upgrade"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/gitlab"
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/slack"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/sprayproxy"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
 	"github.com/konflux-ci/e2e-tests/pkg/testspecs"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/build"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/gc"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
 	"github.com/konflux-ci/image-controller/pkg/quay"
 	"github.com/magefile/mage/sh"
 	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
-	gl "github.com/xanzy/go-gitlab"
 )
 
 const (
@@ -58,7 +59,11 @@ var (
 	// can be periodic, presubmit or postsubmit
 	jobType                    = utils.GetEnv("JOB_TYPE", "")
 	reposToDeleteDefaultRegexp = "jvm-build|e2e-dotnet|build-suite|e2e|pet-clinic-e2e|test-app|e2e-quayio|petclinic|test-app|integ-app|^dockerfile-|new-|^python|my-app|^test-|^multi-component"
-	repositoriesWithWebhooks   = []string{"devfile-sample-hello-world", "hacbs-test-project", "secret-lookup-sample-repo-two"}
+	// branches created by tests and by PaC for their pull requests
+	testBranchesDefaultRegexp = "^(appstudio-|konflux-|base-|pr-branch-|e2e-)"
+	// users (and their namespaces) created by tests with utils.GetGeneratedNamespace
+	testUsersDefaultRegexp   = "^(build-e2e|happy-path|ex-registry|multi-platform|push-pyxis|integration|gitlab-rep|stat-rep|group|konflux-task-runner|rel-plan|rp-ownerref|neg-rp|plan-and-admission|tenant-dev)"
+	repositoriesWithWebhooks = []string{"devfile-sample-hello-world", "hacbs-test-project", "secret-lookup-sample-repo-two"}
 	// determine whether CI will run tests that require to register SprayProxy
 	// in order to run tests that require PaC application
 	requiresSprayProxyRegistering bool
@@ -202,42 +207,13 @@ func (Local) CleanupGithubOrg() error {
 	if err != nil {
 		return err
 	}
-	repos, err := ghClient.GetAllRepositories()
+	policy, err := gc.NewPolicy(utils.GetEnv("REPO_REGEX", reposToDeleteDefaultRegexp), 24*time.Hour)
 	if err != nil {
 		return err
 	}
-	var reposToDelete []*gh.Repository
-
-	// Filter repos by regex & time check
-	r, err := regexp.Compile(utils.GetEnv("REPO_REGEX", reposToDeleteDefaultRegexp))
-	if err != nil {
-		return fmt.Errorf("unable to compile regex: %s", err)
-	}
-	for _, repo := range repos {
-		// Add only repos older than 24 hours
-		dayDuration, _ := time.ParseDuration("24h")
-		if time.Since(repo.GetCreatedAt().Time) > dayDuration {
-			// Add only repos matching the regex
-			if r.MatchString(repo.GetName()) || repo.GetDescription() == gitopsRepository {
-				reposToDelete = append(reposToDelete, repo)
-			}
-		}
-	}
-
-	if dryRun {
-		klog.Info("Dry run enabled. Listing repositories that would be deleted:")
-	}
-
-	// Delete repos
-	for _, repo := range reposToDelete {
-		if dryRun {
-			klog.Infof("\t%s", repo.GetName())
-		} else {
-			err := ghClient.DeleteRepository(repo)
-			if err != nil {
-				klog.Warningf("error deleting repository: %s\n", err)
-			}
-		}
+	// Delete repos matching the regex or description of gitops repositories, which are older than 24 hours
+	if err := runGarbageCollector(gcConfig{DryRun: dryRun}, &gc.GithubRepositories{Client: ghClient, Organization: githubOrgName, Policy: policy, Description: gitopsRepository}); err != nil {
+		klog.Warningf("error deleting repositories: %s\n", err)
 	}
 	if dryRun {
 		klog.Info("If you really want to delete these repositories, run `DRY_RUN=false [REGEXP=<regexp>] mage local:cleanupGithubOrg`")
@@ -315,6 +291,9 @@ func (ci CI) TestE2E() error {
 	if pr.RepoName == "e2e-tests" || pr.RepoName == "integration-service" ||
 		pr.RepoName == "release-service" || pr.RepoName == "image-controller" ||
 		pr.RepoName == "build-service" || pr.RepoName == "release-service-catalog" {
+		if err := engine.LoadDeclarativeCatalogs(); err != nil {
+			return fmt.Errorf("error when loading declarative rule catalogs: %v", err)
+		}
 		return engine.MageEngine.RunRulesOfCategory("ci", rctx)
 	}
 
@@ -345,6 +324,26 @@ func (ci CI) TestE2E() error {
 	return nil
 }
 
+// PreviewTestPlan prints the rules and ginkgo commands CI would execute for the current job, without executing them.
+// The plan is also stored as JSON in the artifact directory.
+func (ci CI) PreviewTestPlan() error {
+
+	if err := ci.init(); err != nil {
+		return fmt.Errorf("error when running ci init: %v", err)
+	}
+
+	if err := engine.LoadDeclarativeCatalogs(); err != nil {
+		return fmt.Errorf("error when loading declarative rule catalogs: %v", err)
+	}
+
+	plan, err := engine.MageEngine.BuildPlan(rctx, "ci")
+	if err != nil {
+		return fmt.Errorf("error when building the test plan: %v", err)
+	}
+
+	return exportTestPlan(plan)
+}
+
 func (ci CI) UnregisterSprayproxy() {
 	err := unregisterPacServer()
 	if err != nil {
@@ -360,6 +359,9 @@ func RunE2ETests() error {
 	if err != nil {
 		return err
 	}
+	if err := engine.LoadDeclarativeCatalogs(); err != nil {
+		return fmt.Errorf("error when loading declarative rule catalogs: %v", err)
+	}
 	switch rctx.RepoName {
 	case "release-service-catalog":
 		rctx.IsPaired = isPRPairingRequired("release-service")
@@ -805,22 +807,7 @@ func CleanGitHubWebHooks() error {
 	if err != nil {
 		return err
 	}
-	for _, repo := range repositoriesWithWebhooks {
-		webhookList, err := gh.ListRepoWebhooks(repo)
-		if err != nil {
-			return err
-		}
-		for _, wh := range webhookList {
-			dayDuration, _ := time.ParseDuration("24h")
-			if time.Since(wh.GetCreatedAt()) > dayDuration {
-				klog.Infof("removing webhook: %s, git_organization: %s, git_repository: %s", wh.GetName(), githubOrg, repo)
-				if err := gh.DeleteWebhook(repo, wh.GetID()); err != nil {
-					return fmt.Errorf("failed to delete webhook: %v, repo: %s", wh.Name, repo)
-				}
-			}
-		}
-	}
-	return nil
+	return runGarbageCollector(gcConfig{DryRun: false}, &gc.GithubWebhooks{Client: gh, Organization: githubOrg, Repositories: repositoriesWithWebhooks, Policy: gc.Policy{MaxAge: 24 * time.Hour}})
 }
 
 // Remove all webhooks older than 1 day from GitLab repo.
@@ -834,25 +821,118 @@ func CleanGitLabWebHooks() error {
 		return fmt.Errorf("empty GITLAB_PROJECT_ID env variable. Please provide a valid GitLab Project ID")
 	}
 	gitlabURL := utils.GetEnv(constants.GITLAB_API_URL_ENV, constants.DefaultGitLabAPIURL)
-	gc, err := gitlab.NewGitlabClient(gcToken, gitlabURL)
+	gitlabClient, err := gitlab.NewGitlabClient(gcToken, gitlabURL)
 	if err != nil {
 		return err
 	}
-	webhooks, _, err := gc.GetClient().Projects.ListProjectHooks(projectID, &gl.ListProjectHooksOptions{PerPage: 100})
+	return runGarbageCollector(gcConfig{DryRun: false}, &gc.GitlabWebhooks{Client: gitlabClient, ProjectIDs: []string{projectID}, Policy: gc.Policy{MaxAge: 24 * time.Hour}})
+}
+
+// Deletes resources leaked by e2e tests across GitHub, GitLab, Quay, SprayProxy and the cluster, the services are
+// cleaned up only when their credentials are set. Resources are selected by naming convention and age (GC_MAX_AGE, defaults to 24h).
+// Env vars to configure this target: DRY_RUN (defaults to true), GC_GITHUB_REPOSITORIES (comma separated repositories with
+// test branches, pull requests and webhooks), GC_WORKERS, GC_RATE_LIMIT (deletions per second for each service),
+// GC_REPORT_FILE (defaults to $ARTIFACT_DIR/gc-report.json)
+func GarbageCollect() error {
+	dryRun, err := strconv.ParseBool(utils.GetEnv("DRY_RUN", "true"))
 	if err != nil {
-		return fmt.Errorf("failed to list project hooks: %v", err)
-	}
-	// Delete webhooks that are older than 1 day
-	for _, webhook := range webhooks {
-		dayDuration, _ := time.ParseDuration("24h")
-		if time.Since(*webhook.CreatedAt) > dayDuration {
-			klog.Infof("removing webhookURL: %s", webhook.URL)
-			if _, err := gc.GetClient().Projects.DeleteProjectHook(projectID, webhook.ID); err != nil {
-				return fmt.Errorf("failed to delete webhook (URL: %s): %v", webhook.URL, err)
-			}
+		return fmt.Errorf("unable to parse DRY_RUN env var\n\t%s", err)
+	}
+	maxAge, err := time.ParseDuration(utils.GetEnv("GC_MAX_AGE", "24h"))
+	if err != nil {
+		return fmt.Errorf("unable to parse GC_MAX_AGE env var: %+v", err)
+	}
+	branches, err := gc.NewPolicy(testBranchesDefaultRegexp, maxAge)
+	if err != nil {
+		return err
+	}
+	var collectors []gc.Collector
+
+	if githubToken := os.Getenv(constants.GITHUB_TOKEN_ENV); githubToken != "" {
+		githubOrg := utils.GetEnv(constants.GITHUB_E2E_ORGANIZATION_ENV, "redhat-appstudio-qe")
+		ghClient, err := github.NewGithubClient(githubToken, githubOrg)
+		if err != nil {
+			return err
 		}
+		repos, err := gc.NewPolicy(utils.GetEnv("REPO_REGEX", reposToDeleteDefaultRegexp), maxAge)
+		if err != nil {
+			return err
+		}
+		repositories := strings.Split(utils.GetEnv("GC_GITHUB_REPOSITORIES", strings.Join(repositoriesWithWebhooks, ",")), ",")
+		collectors = append(collectors,
+			&gc.GithubRepositories{Client: ghClient, Organization: githubOrg, Policy: repos, Description: gitopsRepository},
+			&gc.GithubPullRequests{Client: ghClient, Organization: githubOrg, Repositories: repositories, Policy: branches},
+			&gc.GithubBranches{Client: ghClient, Organization: githubOrg, Repositories: repositories, Policy: branches},
+			&gc.GithubWebhooks{Client: ghClient, Organization: githubOrg, Repositories: repositories, Policy: gc.Policy{MaxAge: maxAge}},
+		)
+	} else {
+		klog.Infof("%s env var is not set, skipping GitHub", constants.GITHUB_TOKEN_ENV)
 	}
-	return nil
+
+	gitlabToken := os.Getenv(constants.GITLAB_BOT_TOKEN_ENV)
+	gitlabProjectID := os.Getenv(constants.GITLAB_PROJECT_ID_ENV)
+	if gitlabToken != "" && gitlabProjectID != "" {
+		gitlabClient, err := gitlab.NewGitlabClient(gitlabToken, utils.GetEnv(constants.GITLAB_API_URL_ENV, constants.DefaultGitLabAPIURL))
+		if err != nil {
+			return err
+		}
+		projectIDs := []string{gitlabProjectID}
+		collectors = append(collectors,
+			&gc.GitlabMergeRequests{Client: gitlabClient, ProjectIDs: projectIDs, Policy: branches},
+			&gc.GitlabBranches{Client: gitlabClient, ProjectIDs: projectIDs, Policy: branches},
+			&gc.GitlabWebhooks{Client: gitlabClient, ProjectIDs: projectIDs, Policy: gc.Policy{MaxAge: maxAge}},
+		)
+	} else {
+		klog.Infof("%s or %s env var is not set, skipping GitLab", constants.GITLAB_BOT_TOKEN_ENV, constants.GITLAB_PROJECT_ID_ENV)
+	}
+
+	if quayOrgToken := os.Getenv("DEFAULT_QUAY_ORG_TOKEN"); quayOrgToken != "" {
+		quayOrg := utils.GetEnv("DEFAULT_QUAY_ORG", "redhat-appstudio-qe")
+		quayClient := quay.NewQuayClient(&http.Client{Transport: &http.Transport{}}, quayOrgToken, quayApiUrl)
+		robots, err := gc.NewPolicy(fmt.Sprintf(`^(%s)`, quayPrefixesToDeleteRegexp), maxAge)
+		if err != nil {
+			return err
+		}
+		privateRepos, err := gc.NewPolicy(`^(build-e2e|konflux|multi-platform|jvm-build-service)`, 7*24*time.Hour)
+		if err != nil {
+			return err
+		}
+		collectors = append(collectors,
+			&gc.QuayRobots{Service: quayClient, Organization: quayOrg, Policy: robots},
+			&gc.QuayRepositories{Service: quayClient, Organization: quayOrg, Policy: privateRepos, PrivateOnly: true},
+			&gc.QuayTags{Service: quayClient, Organization: quayOrg, Repository: "test-images", Policy: gc.Policy{MaxAge: 7 * 24 * time.Hour}},
+		)
+	} else {
+		klog.Info(quayTokenNotFoundError + ", skipping Quay")
+	}
+
+	if sprayProxy, err := newSprayProxy(); err == nil {
+		collectors = append(collectors, &gc.SprayProxyServers{Config: sprayProxy})
+	} else {
+		klog.Infof("%s, skipping SprayProxy", err)
+	}
+
+	if kubeClient, err := kubeCl.NewAdminKubernetesClient(); err == nil {
+		users, err := gc.NewPolicy(utils.GetEnv("GC_USERS_REGEX", testUsersDefaultRegexp), maxAge)
+		if err != nil {
+			return err
+		}
+		cluster := kubeClient.KubeInterface().CoreV1().RESTClient().Get().URL().Host
+		if utils.GetEnv(constants.FRAMEWORK_MODE_ENV, constants.FrameworkModeSandbox) == constants.FrameworkModeTenant {
+			tenantLabel := fmt.Sprintf("%s=%s", sandbox.TenantNamespaceLabelKey, sandbox.TenantNamespaceLabelValue)
+			collectors = append(collectors, &gc.Namespaces{KubeClient: kubeClient.KubeInterface(), Cluster: cluster, Policy: users, LabelSelector: tenantLabel})
+		} else {
+			collectors = append(collectors, &gc.UserSignups{KubeRest: kubeClient.KubeRest(), Cluster: cluster, Policy: users})
+		}
+	} else {
+		klog.Infof("failed to create kubernetes client, skipping the cluster: %+v", err)
+	}
+
+	return runGarbageCollector(gcConfig{
+		DryRun:     dryRun,
+		RateLimit:  gc.DefaultRateLimit,
+		ReportFile: filepath.Join(artifactDir, "gc-report.json"),
+	}, collectors...)
 }
 
 // Generate a Text Outline file from a Ginkgo Spec
@@ -1232,21 +1312,14 @@ func CleanupRegisteredPacServers() error {
 		return fmt.Errorf("failed to initialize SprayProxy config: %+v", err)
 	}
 
-	servers, err := sprayProxyConfig.GetServers()
-	if err != nil {
-		return fmt.Errorf("failed to get registered PaC servers from SprayProxy: %+v", err)
+	klog.Infof("Before cleaningup Pac servers...")
+	if err := printRegisteredPacServers(); err != nil {
+		klog.Error(err)
 	}
-	klog.Infof("Before cleaningup Pac servers, the registered PaC servers: %v", servers)
 
-	for _, server := range strings.Split(servers, ",") {
-		// Verify if the server is a valid host, if not, unregister it
-		if !isValidPacHost(server) {
-			_, err := sprayProxyConfig.UnregisterServer(strings.TrimSpace(server))
-			if err != nil {
-				return fmt.Errorf("error when unregistering PaC server %s from SprayProxy server %s: %+v", server, sprayProxyConfig.BaseURL, err)
-			}
-			klog.Infof("Cleanup invalid PaC server: %s", server)
-		}
+	// Unregister servers which are not valid hosts anymore
+	if err := runGarbageCollector(gcConfig{DryRun: false}, &gc.SprayProxyServers{Config: sprayProxyConfig}); err != nil {
+		return err
 	}
 	klog.Infof("After cleaningup Pac servers...")
 	err = printRegisteredPacServers()
@@ -1256,12 +1329,6 @@ func CleanupRegisteredPacServers() error {
 	return nil
 }
 
-func isValidPacHost(server string) bool {
-	httpClient := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
-	_, err := httpClient.Get(strings.TrimSpace(server))
-	return err == nil
-}
-
 func (Local) PreviewTestSelection() error {
 
 	rctx := rulesengine.NewRuleCtx()
@@ -1273,6 +1340,10 @@ func (Local) PreviewTestSelection() error {
 	rctx.DiffFiles = files
 	rctx.DryRun = true
 
+	if err := engine.LoadDeclarativeCatalogs(); err != nil {
+		return err
+	}
+
 	err = engine.MageEngine.RunRules(rctx, "tests", "e2e-repo")
 
 	if err != nil {
@@ -1282,6 +1353,64 @@ func (Local) PreviewTestSelection() error {
 	return nil
 }
 
+// PreviewTestPlan prints the rules and ginkgo commands that would be executed for the e2e-repo changes, without executing them.
+func (Local) PreviewTestPlan() error {
+
+	rctx := rulesengine.NewRuleCtx()
+	files, err := utils.GetChangedFiles("e2e-tests")
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	rctx.DiffFiles = files
+
+	if err := engine.LoadDeclarativeCatalogs(); err != nil {
+		return err
+	}
+
+	plan, err := engine.MageEngine.BuildPlan(rctx, "tests", "e2e-repo")
+	if err != nil {
+		return err
+	}
+
+	return exportTestPlan(plan)
+}
+
+// ExplainTestSelection prints why each e2e-repo test selection rule did or didn't match the changed files.
+// Set EXPLAIN_OUTPUT_FORMAT to 'json' to get the evaluation tree as JSON instead of an indented tree.
+func (Local) ExplainTestSelection() error {
+
+	rctx := rulesengine.NewRuleCtx()
+	files, err := utils.GetChangedFiles("e2e-tests")
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	rctx.DiffFiles = files
+
+	if err := engine.LoadDeclarativeCatalogs(); err != nil {
+		return err
+	}
+
+	trace, err := engine.MageEngine.Explain(rctx, "tests", "e2e-repo")
+	if err != nil {
+		return err
+	}
+
+	if utils.GetEnv("EXPLAIN_OUTPUT_FORMAT", "tree") == "json" {
+		out, err := trace.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	}
+
+	fmt.Print(trace.Tree())
+
+	return nil
+}
+
 func (Local) RunRuleDemo() error {
 	rctx := rulesengine.NewRuleCtx()
 	files, err := utils.GetChangedFiles("e2e-tests")
@@ -1292,6 +1421,10 @@ func (Local) RunRuleDemo() error {
 	rctx.DiffFiles = files
 	rctx.DryRun = true
 
+	if err := engine.LoadDeclarativeCatalogs(); err != nil {
+		return err
+	}
+
 	err = engine.MageEngine.RunRulesOfCategory("demo", rctx)
 
 	if err != nil {
@@ -1318,6 +1451,10 @@ func (Local) RunInfraDeploymentsRuleDemo() error {
 	}
 	rctx.DiffFiles = files
 
+	if err := engine.LoadDeclarativeCatalogs(); err != nil {
+		return err
+	}
+
 	// filtering the rule engine to load only infra-deployments rule catalog within the test category
 	return engine.MageEngine.RunRules(rctx, "tests", "infra-deployments")
 }
@@ -1,27 +1,31 @@
 package engine
 
 import (
-	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"sync"
+
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine/repos"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
 )
 
-var MageEngine = rulesengine.RuleEngine{
-	"tests": {
-		"e2e-repo":                repos.E2ETestRulesCatalog,
-		"infra-deployments":       repos.InfraDeploymentsRulesCatalog,
-	},
-	"demo": {
-		"local-workflow": repos.DemoCatalog,
-	},
-
-	"ci": {
-		"e2e-repo":                repos.E2ECIChainCatalog,
-		"release-service":         repos.ReleaseServiceCICatalog,
-		"release-service-catalog": repos.ReleaseServiceCatalogCICatalog,
-		"integration-service":     repos.IntegrationServiceCICatalog,
-		"image-controller":        repos.ImageControllerCICatalog,
-		"build-service":           repos.BuildServiceCICatalog,
-		// TODO: to be implemented in a follow-up PR
-		//"infra-deployments": repos.InfraDeploymentsCIChainCatalog,
-	},
+// DefaultCatalogDir is where declarative (YAML/JSON) rule catalogs are looked up
+// when RULES_CATALOG_DIR is not set. Paths are relative to the repository root.
+const DefaultCatalogDir = "magefiles/rulesengine/catalogs"
+
+var MageEngine = repos.NewRuleEngine()
+
+var (
+	loadCatalogsOnce sync.Once
+	loadCatalogsErr  error
+)
+
+// LoadDeclarativeCatalogs merges the declarative catalogs found in RULES_CATALOG_DIR
+// into MageEngine. It is safe to call multiple times, the files are only loaded once.
+func LoadDeclarativeCatalogs() error {
+
+	loadCatalogsOnce.Do(func() {
+		dir := utils.GetEnv("RULES_CATALOG_DIR", DefaultCatalogDir)
+		loadCatalogsErr = MageEngine.LoadCatalogDir(dir, repos.CatalogRegistry)
+	})
+
+	return loadCatalogsErr
 }
@@ -0,0 +1,321 @@
+package rulesengine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+// Registry holds the named conditionals and actions that a declarative
+// catalog file is allowed to reference. Go catalogs register their reusable
+// ConditionFuncs, ActionFuncs and rule chains here so that YAML/JSON rules
+// can be composed out of the same building blocks.
+type Registry struct {
+	Conditions map[string]Conditional
+	Actions    map[string]Action
+}
+
+// CatalogSpec is the declarative representation of a RuleCatalog. A single
+// file describes one catalog and the category it gets registered under.
+type CatalogSpec struct {
+	Category string     `json:"category"`
+	Catalog  string     `json:"catalog"`
+	Rules    []RuleSpec `json:"rules"`
+}
+
+type RuleSpec struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Condition   *ConditionSpec `json:"condition"`
+	Actions     []ActionSpec   `json:"actions,omitempty"`
+}
+
+// ConditionSpec is a node of the condition tree. Exactly one of its
+// fields has to be set per node.
+type ConditionSpec struct {
+	All   []ConditionSpec `json:"all,omitempty"`
+	Any   []ConditionSpec `json:"any,omitempty"`
+	None  []ConditionSpec `json:"none,omitempty"`
+	Func  string          `json:"func,omitempty"`
+	Files *FilesSpec      `json:"files,omitempty"`
+}
+
+// FilesSpec is a built-in predicate over RuleCtx.DiffFiles. It evaluates
+// to true when at least one changed file matches all of the set filters.
+type FilesSpec struct {
+	Glob     string `json:"glob,omitempty"`
+	Contains string `json:"contains,omitempty"`
+	Status   string `json:"status,omitempty"`
+}
+
+// ActionSpec references a registered action by name or uses the built-in
+// labelFilter action which appends a label to RuleCtx.LabelFilter.
+type ActionSpec struct {
+	Func        string `json:"func,omitempty"`
+	LabelFilter string `json:"labelFilter,omitempty"`
+}
+
+func (fs *FilesSpec) Check(rctx *RuleCtx) (bool, error) {
+
+	return traceCheck(rctx, "Files", fs.String(), func() (bool, error) { return fs.check(rctx) })
+}
+
+func (fs *FilesSpec) check(rctx *RuleCtx) (bool, error) {
+
+	files := rctx.DiffFiles
+	if fs.Glob != "" {
+		files = files.FilterByDirGlob(fs.Glob)
+	}
+	if fs.Contains != "" {
+		files = files.FilterByDirString(fs.Contains)
+	}
+	if fs.Status != "" {
+		files = files.FilterByStatus(fs.Status)
+	}
+	// files are recorded by the filters, but without any filter all files match
+	traceFilteredFiles(files)
+
+	return len(files) != 0, nil
+}
+
+func (fs *FilesSpec) String() string {
+
+	var filters []string
+	if fs.Glob != "" {
+		filters = append(filters, fmt.Sprintf("glob=%s", fs.Glob))
+	}
+	if fs.Contains != "" {
+		filters = append(filters, fmt.Sprintf("contains=%s", fs.Contains))
+	}
+	if fs.Status != "" {
+		filters = append(filters, fmt.Sprintf("status=%s", fs.Status))
+	}
+
+	return fmt.Sprintf("files(%s)", strings.Join(filters, ", "))
+}
+
+// ParseCatalog decodes a YAML or JSON catalog definition and builds the
+// RuleCatalog it describes, resolving named references against the registry.
+func ParseCatalog(data []byte, reg Registry) (*CatalogSpec, RuleCatalog, error) {
+
+	spec := &CatalogSpec{}
+	if err := yaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode catalog: %+v", err)
+	}
+
+	if spec.Category == "" {
+		return nil, nil, fmt.Errorf("catalog is missing the 'category' field")
+	}
+	if spec.Catalog == "" {
+		return nil, nil, fmt.Errorf("catalog is missing the 'catalog' field")
+	}
+	if len(spec.Rules) == 0 {
+		return nil, nil, fmt.Errorf("catalog %s does not define any rules", spec.Catalog)
+	}
+
+	var catalog RuleCatalog
+	for i, rs := range spec.Rules {
+		rule, err := rs.build(reg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("catalog %s, rule #%d: %+v", spec.Catalog, i, err)
+		}
+		catalog = append(catalog, rule)
+	}
+
+	return spec, catalog, nil
+}
+
+// LoadCatalogFile reads a single catalog definition from disk.
+func LoadCatalogFile(path string, reg Registry) (*CatalogSpec, RuleCatalog, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read catalog file %s: %+v", path, err)
+	}
+
+	spec, catalog, err := ParseCatalog(data, reg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid catalog file %s: %+v", path, err)
+	}
+
+	return spec, catalog, nil
+}
+
+// LoadCatalogDir loads every *.yaml, *.yml and *.json catalog file found in dir
+// and registers them into the engine. A missing directory is not an error.
+func (e *RuleEngine) LoadCatalogDir(dir string, reg Registry) error {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read catalog directory %s: %+v", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		spec, catalog, err := LoadCatalogFile(path, reg)
+		if err != nil {
+			return err
+		}
+		if err := e.AddCatalog(spec.Category, spec.Catalog, catalog); err != nil {
+			return fmt.Errorf("failed to register catalog file %s: %+v", path, err)
+		}
+		klog.Infof("Loaded the catalog, %s, into category, %s, from %s", spec.Catalog, spec.Category, path)
+	}
+
+	return nil
+}
+
+// AddCatalog registers a catalog under the given category. Registering a
+// catalog name that already exists in the category is an error so that a
+// declarative catalog can never silently shadow a Go one.
+func (e *RuleEngine) AddCatalog(category, name string, catalog RuleCatalog) error {
+
+	if *e == nil {
+		*e = RuleEngine{}
+	}
+
+	if _, ok := (*e)[category]; !ok {
+		(*e)[category] = map[string]RuleCatalog{}
+	}
+
+	if _, ok := (*e)[category][name]; ok {
+		return fmt.Errorf("catalog %s is already registered in category %s", name, category)
+	}
+
+	(*e)[category][name] = catalog
+
+	return nil
+}
+
+func (rs *RuleSpec) build(reg Registry) (Rule, error) {
+
+	if rs.Name == "" {
+		return Rule{}, fmt.Errorf("rule is missing the 'name' field")
+	}
+	if rs.Condition == nil {
+		return Rule{}, fmt.Errorf("rule %q is missing the 'condition' field", rs.Name)
+	}
+
+	cond, err := rs.Condition.build(reg)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %+v", rs.Name, err)
+	}
+
+	var actions []Action
+	for _, as := range rs.Actions {
+		action, err := as.build(reg)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %+v", rs.Name, err)
+		}
+		actions = append(actions, action)
+	}
+
+	return Rule{Name: rs.Name, Description: rs.Description, Condition: cond, Actions: actions}, nil
+}
+
+func (cs *ConditionSpec) build(reg Registry) (Conditional, error) {
+
+	set := 0
+	for _, isSet := range []bool{cs.All != nil, cs.Any != nil, cs.None != nil, cs.Func != "", cs.Files != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("a condition must set exactly one of 'all', 'any', 'none', 'func' or 'files', found %d", set)
+	}
+
+	switch {
+	case cs.All != nil:
+		conds, err := buildConditions(cs.All, reg)
+		return All(conds), err
+	case cs.Any != nil:
+		conds, err := buildConditions(cs.Any, reg)
+		return Any(conds), err
+	case cs.None != nil:
+		conds, err := buildConditions(cs.None, reg)
+		return None(conds), err
+	case cs.Files != nil:
+		if cs.Files.Glob == "" && cs.Files.Contains == "" && cs.Files.Status == "" {
+			return nil, fmt.Errorf("a files condition must set at least one of 'glob', 'contains' or 'status'")
+		}
+		return cs.Files, nil
+	}
+
+	cond, ok := reg.Conditions[cs.Func]
+	if !ok {
+		return nil, fmt.Errorf("condition %q is not registered", cs.Func)
+	}
+
+	return cond, nil
+}
+
+func buildConditions(specs []ConditionSpec, reg Registry) ([]Conditional, error) {
+
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("'all', 'any' and 'none' conditions cannot be empty")
+	}
+
+	var conds []Conditional
+	for _, spec := range specs {
+		cond, err := spec.build(reg)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+
+	return conds, nil
+}
+
+func (as *ActionSpec) build(reg Registry) (Action, error) {
+
+	if (as.Func == "") == (as.LabelFilter == "") {
+		return nil, fmt.Errorf("an action must set exactly one of 'func' or 'labelFilter'")
+	}
+
+	if as.LabelFilter != "" {
+		label := as.LabelFilter
+		return ActionFunc(func(rctx *RuleCtx) error {
+			if rctx.LabelFilter == "" {
+				rctx.LabelFilter = label
+			} else if !labelFilterContains(rctx.LabelFilter, label) {
+				rctx.LabelFilter = fmt.Sprintf("%s,%s", rctx.LabelFilter, label)
+			}
+			return nil
+		}), nil
+	}
+
+	action, ok := reg.Actions[as.Func]
+	if !ok {
+		return nil, fmt.Errorf("action %q is not registered", as.Func)
+	}
+
+	return action, nil
+}
+
+// labelFilterContains returns true when the label is one of the terms of the Ginkgo label filter
+func labelFilterContains(filter, label string) bool {
+	for _, term := range strings.FieldsFunc(strings.ReplaceAll(filter, "||", ","), func(r rune) bool { return r == ',' }) {
+		if strings.TrimSpace(term) == label {
+			return true
+		}
+	}
+	return false
+}
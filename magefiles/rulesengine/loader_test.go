@@ -0,0 +1,107 @@
+package rulesengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRegistry = Registry{
+	Conditions: map[string]Conditional{
+		"IsPeriodicJob": ConditionFunc(func(rctx *RuleCtx) (bool, error) {
+			return rctx.JobType == "periodic", nil
+		}),
+	},
+	Actions: map[string]Action{
+		"Noop": ActionFunc(func(rctx *RuleCtx) error {
+			return nil
+		}),
+	},
+}
+
+const testCatalog = `
+category: tests
+catalog: my-operator
+rules:
+  - name: My Operator Tests
+    description: Run my-operator suite when controllers change on non periodic jobs
+    condition:
+      all:
+        - files:
+            glob: "controllers/**/*.go"
+        - none:
+            - func: IsPeriodicJob
+            - files:
+                status: D
+    actions:
+      - labelFilter: my-operator
+      - func: Noop
+`
+
+func TestParseCatalog(t *testing.T) {
+
+	spec, catalog, err := ParseCatalog([]byte(testCatalog), testRegistry)
+	assert.NoError(t, err)
+	assert.Equal(t, "tests", spec.Category)
+	assert.Equal(t, "my-operator", spec.Catalog)
+	assert.Len(t, catalog, 1)
+	assert.Len(t, catalog[0].Actions, 2)
+
+	rctx := NewRuleCtx()
+	rctx.DiffFiles = Files{{Name: "controllers/foo/bar.go", Status: "M"}}
+	ok, err := catalog[0].Eval(rctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, catalog[0].Apply(rctx))
+	assert.Equal(t, "my-operator", rctx.LabelFilter)
+	assert.NoError(t, catalog[0].Apply(rctx))
+	assert.Equal(t, "my-operator", rctx.LabelFilter)
+
+	rctx.LabelFilter = "my-operator-upgrade || build"
+	assert.NoError(t, catalog[0].Apply(rctx))
+	assert.Equal(t, "my-operator-upgrade || build,my-operator", rctx.LabelFilter)
+
+	rctx.JobType = "periodic"
+	ok, err = catalog[0].Eval(rctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	rctx = NewRuleCtx()
+	rctx.DiffFiles = Files{{Name: "controllers/foo/bar.go", Status: "D"}}
+	ok, err = catalog[0].Eval(rctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestParseCatalogJSON(t *testing.T) {
+
+	_, catalog, err := ParseCatalog([]byte(`{"category": "ci", "catalog": "json", "rules": [{"name": "r", "condition": {"files": {"contains": "docs/"}}}]}`), testRegistry)
+	assert.NoError(t, err)
+	assert.Len(t, catalog, 1)
+}
+
+func TestParseCatalogErrors(t *testing.T) {
+
+	for name, data := range map[string]string{
+		"unknown condition": `{"category": "c", "catalog": "n", "rules": [{"name": "r", "condition": {"func": "Missing"}}]}`,
+		"unknown action":    `{"category": "c", "catalog": "n", "rules": [{"name": "r", "condition": {"func": "IsPeriodicJob"}, "actions": [{"func": "Missing"}]}]}`,
+		"multiple keys":     `{"category": "c", "catalog": "n", "rules": [{"name": "r", "condition": {"func": "IsPeriodicJob", "files": {"glob": "*"}}}]}`,
+		"empty all":         `{"category": "c", "catalog": "n", "rules": [{"name": "r", "condition": {"all": []}}]}`,
+		"empty files":       `{"category": "c", "catalog": "n", "rules": [{"name": "r", "condition": {"files": {}}}]}`,
+		"missing condition": `{"category": "c", "catalog": "n", "rules": [{"name": "r"}]}`,
+		"missing category":  `{"catalog": "n", "rules": [{"name": "r", "condition": {"func": "IsPeriodicJob"}}]}`,
+		"unknown field":     `{"category": "c", "catalog": "n", "rules": [{"name": "r", "conditions": {"func": "IsPeriodicJob"}}]}`,
+	} {
+		_, _, err := ParseCatalog([]byte(data), testRegistry)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestAddCatalog(t *testing.T) {
+
+	e := RuleEngine{"tests": {"e2e-repo": RuleCatalog{}}}
+	assert.NoError(t, e.AddCatalog("tests", "my-operator", RuleCatalog{}))
+	assert.NoError(t, e.AddCatalog("ci", "my-operator", RuleCatalog{}))
+	assert.Error(t, e.AddCatalog("tests", "e2e-repo", RuleCatalog{}))
+}
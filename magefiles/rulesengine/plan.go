@@ -0,0 +1,161 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Plan is the ordered list of rules whose actions would be executed by the engine,
+// including the rules applied as part of a rule chain.
+type Plan struct {
+	Rules []*PlannedRule `json:"rules"`
+}
+
+type PlannedRule struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Actions     []*PlannedAction `json:"actions"`
+}
+
+// PlannedAction is an action of a rule together with the external
+// commands it would run, i.e. the ginkgo command generated by ExecuteTestAction.
+type PlannedAction struct {
+	Name     string           `json:"name"`
+	Commands []PlannedCommand `json:"commands,omitempty"`
+}
+
+type PlannedCommand struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+func (pc PlannedCommand) String() string {
+
+	return strings.Join(append([]string{pc.Command}, pc.Args...), " ")
+}
+
+func (p *Plan) addRule(r *Rule) {
+
+	p.Rules = append(p.Rules, &PlannedRule{Name: r.Name, Description: r.Description})
+}
+
+func (p *Plan) addAction(a Action) {
+
+	if len(p.Rules) == 0 {
+		return
+	}
+	rule := p.Rules[len(p.Rules)-1]
+	rule.Actions = append(rule.Actions, &PlannedAction{Name: actionName(a)})
+}
+
+// AddCommand records a command that the currently planned action would run.
+// Actions call it instead of running the command when RuleCtx.Plan is set.
+func (p *Plan) AddCommand(command string, args ...string) {
+
+	if len(p.Rules) == 0 || len(p.Rules[len(p.Rules)-1].Actions) == 0 {
+		return
+	}
+	rule := p.Rules[len(p.Rules)-1]
+	action := rule.Actions[len(rule.Actions)-1]
+	action.Commands = append(action.Commands, PlannedCommand{Command: command, Args: args})
+}
+
+// Commands returns every command of the plan in execution order.
+func (p *Plan) Commands() []PlannedCommand {
+
+	var commands []PlannedCommand
+	for _, r := range p.Rules {
+		for _, a := range r.Actions {
+			commands = append(commands, a.Commands...)
+		}
+	}
+
+	return commands
+}
+
+func (p *Plan) String() string {
+
+	if len(p.Rules) == 0 {
+		return "No rules matched, nothing would be executed.\n"
+	}
+
+	var sb strings.Builder
+	for _, r := range p.Rules {
+		sb.WriteString(fmt.Sprintf("Rule: %s\n", r.Name))
+		for _, a := range r.Actions {
+			sb.WriteString(fmt.Sprintf("  Action: %s\n", a.Name))
+			for _, c := range a.Commands {
+				sb.WriteString(fmt.Sprintf("    $ %s\n", c.String()))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func (p *Plan) JSON() (string, error) {
+
+	out, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal plan: %+v", err)
+	}
+
+	return string(out), nil
+}
+
+// BuildPlan walks the selected catalogs like RunRules but in dry run mode, and records
+// the actions every matched rule would execute. The passed in RuleCtx is not modified.
+func (e *RuleEngine) BuildPlan(rctx *RuleCtx, args ...string) (*Plan, error) {
+
+	loaded, err := e.selectCatalogs(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	pctx := *rctx
+	pctx.RuleData = make(map[string]any, len(rctx.RuleData))
+	for k, v := range rctx.RuleData {
+		pctx.RuleData[k] = v
+	}
+	pctx.FocusFiles = append([]string{}, rctx.FocusFiles...)
+	pctx.Tracer = nil
+	pctx.DryRun = true
+	pctx.Plan = &Plan{}
+
+	var matched RuleCatalog
+	for _, rule := range loaded {
+		ok, err := rule.Eval(&pctx)
+		if err != nil {
+			return nil, err
+		}
+		// Same as runLoadedCatalog, a matching rule chain without actions stops the evaluation
+		// and the previously matched rules are not applied
+		if len(rule.Actions) == 0 {
+			if ok {
+				return pctx.Plan, nil
+			}
+			continue
+		}
+		if ok {
+			matched = append(matched, rule)
+		}
+	}
+
+	for _, rule := range matched {
+		if err := rule.Apply(&pctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return pctx.Plan, nil
+}
+
+func actionName(a Action) string {
+
+	if af, ok := a.(ActionFunc); ok {
+		return funcName(af)
+	}
+
+	return fmt.Sprintf("%T", a)
+}
@@ -0,0 +1,134 @@
+package rulesengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func alwaysTrue(rctx *RuleCtx) (bool, error) {
+
+	return true, nil
+}
+
+func runCommand(rctx *RuleCtx) error {
+
+	if rctx.Plan != nil {
+		rctx.Plan.AddCommand("ginkgo", "--label-filter="+rctx.LabelFilter, "./cmd")
+		return nil
+	}
+	rctx.AddRuleData("executed", true)
+
+	return nil
+}
+
+func TestBuildPlan(t *testing.T) {
+
+	setLabel := Rule{Name: "Set Label",
+		Condition: ConditionFunc(alwaysTrue),
+		Actions: []Action{ActionFunc(func(rctx *RuleCtx) error {
+			rctx.LabelFilter = "build"
+			return nil
+		})}}
+
+	engine := RuleEngine{"tests": {"repo": RuleCatalog{
+		{Name: "First", Condition: All{&setLabel}, Actions: []Action{ActionFunc(runCommand)}},
+		{Name: "Second", Condition: ConditionFunc(alwaysTrue), Actions: []Action{ActionFunc(runCommand)}},
+		{Name: "Skipped", Condition: None{ConditionFunc(alwaysTrue)}, Actions: []Action{ActionFunc(runCommand)}},
+	}}}
+
+	rctx := NewRuleCtx()
+	plan, err := engine.BuildPlan(rctx, "tests", "repo")
+	assert.NoError(t, err)
+
+	// the passed in context is left untouched
+	assert.False(t, rctx.DryRun)
+	assert.Nil(t, rctx.Plan)
+	assert.Empty(t, rctx.LabelFilter)
+	assert.Nil(t, rctx.GetRuleData("executed"))
+
+	assert.Len(t, plan.Rules, 3)
+	assert.Equal(t, "Set Label", plan.Rules[0].Name)
+	assert.Equal(t, "First", plan.Rules[1].Name)
+	assert.Equal(t, "rulesengine.runCommand", plan.Rules[1].Actions[0].Name)
+	assert.Equal(t, "Second", plan.Rules[2].Name)
+	assert.Equal(t, []PlannedCommand{
+		{Command: "ginkgo", Args: []string{"--label-filter=build", "./cmd"}},
+		{Command: "ginkgo", Args: []string{"--label-filter=build", "./cmd"}},
+	}, plan.Commands())
+
+	assert.Equal(t, `Rule: Set Label
+  Action: rulesengine.TestBuildPlan.func1
+Rule: First
+  Action: rulesengine.runCommand
+    $ ginkgo --label-filter=build ./cmd
+Rule: Second
+  Action: rulesengine.runCommand
+    $ ginkgo --label-filter=build ./cmd
+`, plan.String())
+}
+
+func TestBuildPlanStopsAtMatchedRuleChain(t *testing.T) {
+
+	setLabel := Rule{Name: "Set Label",
+		Condition: ConditionFunc(alwaysTrue),
+		Actions: []Action{ActionFunc(func(rctx *RuleCtx) error {
+			rctx.LabelFilter = "build"
+			return nil
+		})}}
+
+	var executed []string
+	record := func(name string) Action {
+		return ActionFunc(func(rctx *RuleCtx) error {
+			executed = append(executed, name)
+			return nil
+		})
+	}
+	engine := RuleEngine{"tests": {"repo": RuleCatalog{
+		{Name: "Before", Condition: ConditionFunc(alwaysTrue), Actions: []Action{record("before")}},
+		{Name: "Chain", Condition: All{&setLabel}},
+		{Name: "After", Condition: ConditionFunc(alwaysTrue), Actions: []Action{record("after")}},
+	}}}
+
+	plan, err := engine.BuildPlan(NewRuleCtx(), "tests", "repo")
+	assert.NoError(t, err)
+	assert.Len(t, plan.Rules, 1)
+	assert.Equal(t, "Set Label", plan.Rules[0].Name)
+
+	// the plan lists the same rules which are executed
+	rctx := NewRuleCtx()
+	assert.NoError(t, engine.RunRules(rctx, "tests", "repo"))
+	assert.Empty(t, executed)
+	assert.Equal(t, "build", rctx.LabelFilter)
+}
+
+func TestDryRunAppliesAllMatchedRules(t *testing.T) {
+
+	var applied []string
+	record := func(name string) Action {
+		return ActionFunc(func(rctx *RuleCtx) error {
+			assert.True(t, rctx.DryRun)
+			applied = append(applied, name)
+			return nil
+		})
+	}
+
+	engine := RuleEngine{"tests": {"repo": RuleCatalog{
+		{Name: "First", Condition: ConditionFunc(alwaysTrue), Actions: []Action{record("first")}},
+		{Name: "Second", Condition: ConditionFunc(alwaysTrue), Actions: []Action{record("second")}},
+	}}}
+
+	rctx := NewRuleCtx()
+	rctx.DryRun = true
+	assert.NoError(t, engine.RunRules(rctx, "tests", "repo"))
+	assert.Equal(t, []string{"first", "second"}, applied)
+}
+
+func TestRuleDryRunRestoresContext(t *testing.T) {
+
+	rule := Rule{Name: "Rule", Condition: ConditionFunc(alwaysTrue), Actions: []Action{ActionFunc(runCommand)}}
+
+	rctx := NewRuleCtx()
+	assert.NoError(t, rule.DryRun(rctx))
+	assert.False(t, rctx.DryRun)
+}
@@ -0,0 +1,353 @@
+package repos
+
+import (
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEngine = NewRuleEngine()
+
+const defaultE2ELabelFilter = "!upgrade-create && !upgrade-verify && !upgrade-cleanup && !release-pipelines"
+
+var preflightEnv = map[string]string{
+	"GITHUB_TOKEN":           "token",
+	"QUAY_TOKEN":             "token",
+	"DEFAULT_QUAY_ORG":       "org",
+	"DEFAULT_QUAY_ORG_TOKEN": "token",
+	"KONFLUX_CI":             "true",
+	"COMPONENT_IMAGE":        "quay.io/org/component@sha256:1234",
+	"SKIP_BOOTSTRAP":         "false",
+}
+
+func TestE2ERepoTestsCatalog(t *testing.T) {
+
+	runCatalogTestCases(t, testEngine, []catalogTestCase{
+		{
+			name:     "pkg files changed runs the default suites",
+			category: "tests", catalog: "e2e-repo",
+			diffFiles:            rulesengine.Files{{Name: "pkg/utils/util.go", Status: "M"}},
+			expectedRules:        []string{NonTestFilesRule.Name},
+			expectedLabelFilters: []string{defaultE2ELabelFilter},
+		},
+		{
+			name:     "no files changed runs the default suites",
+			category: "tests", catalog: "e2e-repo",
+			expectedRules:        []string{NonTestFilesRule.Name},
+			expectedLabelFilters: []string{defaultE2ELabelFilter},
+		},
+		{
+			name:     "release pipelines tests changed together with pkg files include the release-pipelines suite",
+			category: "tests", catalog: "e2e-repo",
+			diffFiles: rulesengine.Files{
+				{Name: "magefiles/magefile.go", Status: "M"},
+				{Name: "tests/release/pipelines/fbc_release.go", Status: "M"},
+			},
+			expectedRules:        []string{NonTestFilesRuleWithReleasePipelines.Name},
+			expectedLabelFilters: []string{"!upgrade-create && !upgrade-verify && !upgrade-cleanup"},
+		},
+		{
+			name:     "build test file only change focuses the changed file",
+			category: "tests", catalog: "e2e-repo",
+			diffFiles: rulesengine.Files{{Name: "tests/build/build.go", Status: "M"}},
+			expectedRules: []string{
+				TestFilesOnlyRule.Name,
+				BuildORBuildTemplatesTestFileChangeOnlyRule.Name,
+			},
+			expectedLabelFilters: []string{""},
+			expectedFocusFiles:   []string{"tests/build/build.go"},
+		},
+		{
+			name:     "build templates scenarios change focuses the build templates suite",
+			category: "tests", catalog: "e2e-repo",
+			diffFiles: rulesengine.Files{{Name: "tests/build/build_templates_scenarios.go", Status: "M"}},
+			expectedRules: []string{
+				TestFilesOnlyRule.Name,
+				BuildTemplateDependentFileChangeRule.Name,
+			},
+			expectedLabelFilters: []string{""},
+			expectedFocusFiles:   []string{"tests/build/build_templates.go"},
+		},
+		{
+			name:     "integration const change focuses all the integration suites",
+			category: "tests", catalog: "e2e-repo",
+			diffFiles: rulesengine.Files{{Name: "tests/integration-service/const.go", Status: "M"}},
+			expectedRules: []string{
+				TestFilesOnlyRule.Name,
+				BuildORBuildTemplatesTestFileChangeOnlyRule.Name,
+				// IntegrationTestsConstFileChangeRule and IntegrationTestsFileChangeRule share the same name
+				IntegrationTestsConstFileChangeRule.Name,
+			},
+			expectedLabelFilters: []string{""},
+			expectedFocusFiles: []string{
+				"tests/integration-service/gitlab-integration-reporting.go",
+				"tests/integration-service/group-snapshots-tests.go",
+				"tests/integration-service/integration-with-env.go",
+				"tests/integration-service/integration.go",
+				"tests/integration-service/status-reporting-to-pullrequest.go",
+			},
+		},
+		{
+			name:     "ec test file change focuses the changed file",
+			category: "tests", catalog: "e2e-repo",
+			diffFiles: rulesengine.Files{{Name: "tests/enterprise-contract/contract.go", Status: "M"}},
+			expectedRules: []string{
+				TestFilesOnlyRule.Name,
+				BuildORBuildTemplatesTestFileChangeOnlyRule.Name,
+				EcTestFileChangeRule.Name,
+			},
+			expectedLabelFilters: []string{""},
+			expectedFocusFiles:   []string{"tests/enterprise-contract/contract.go"},
+		},
+		{
+			// BuildORBuildTemplatesTestFileChangeOnlyRule matches whenever none of the build
+			// helper files changed, so any other change falls through to the test files rule
+			// and runs ginkgo without a label filter nor focused files.
+			name:     "documentation only change runs ginkgo without filters",
+			category: "tests", catalog: "e2e-repo",
+			diffFiles: rulesengine.Files{{Name: "docs/Installation.md", Status: "M"}},
+			expectedRules: []string{
+				TestFilesOnlyRule.Name,
+				BuildORBuildTemplatesTestFileChangeOnlyRule.Name,
+			},
+			expectedLabelFilters: []string{""},
+		},
+	})
+}
+
+func TestInfraDeploymentsTestsCatalog(t *testing.T) {
+
+	runCatalogTestCases(t, testEngine, []catalogTestCase{
+		{
+			name:     "changes outside of the components run the konflux suites",
+			category: "tests", catalog: "infra-deployments",
+			diffFiles:            rulesengine.Files{{Name: "argo-cd-apps/base/all-clusters.yaml", Status: "M"}},
+			expectedRules:        []string{InfraDeploymentsDefaultRule.Name},
+			expectedLabelFilters: []string{"konflux"},
+		},
+		{
+			name:     "integration component change runs the integration suites",
+			category: "tests", catalog: "infra-deployments",
+			diffFiles: rulesengine.Files{{Name: "components/integration/development/kustomization.yaml", Status: "M"}},
+			expectedRules: []string{
+				InfraDeploymentsIntegrationComponentChangeRule.Name,
+				InfraDeploymentsComponentsRule.Name,
+			},
+			expectedLabelFilters: []string{"integration-service,konflux"},
+		},
+		{
+			name:     "build pipeline config change runs the build templates suites only",
+			category: "tests", catalog: "infra-deployments",
+			diffFiles: rulesengine.Files{{Name: "components/build-service/base/build-pipeline-config/build-pipeline-config.yaml", Status: "M"}},
+			expectedRules: []string{
+				InfraDeploymentsBuildTemplatesComponentChangeRule.Name,
+				InfraDeploymentsComponentsRule.Name,
+			},
+			expectedLabelFilters: []string{"build-templates,konflux"},
+		},
+	})
+}
+
+func TestCICatalogs(t *testing.T) {
+
+	runCatalogTestCases(t, testEngine, []catalogTestCase{
+		{
+			name:      "e2e-tests PR bootstraps the cluster and runs the suites based on the diff",
+			category:  "ci",
+			repoName:  "e2e-tests",
+			env:       preflightEnv,
+			diffFiles: rulesengine.Files{{Name: "pkg/clients/has/components.go", Status: "M"}},
+			expectedRules: []string{
+				E2ERepoCIRuleChain.Name,
+				E2ERepoSetDefaultSettingsRule.Name,
+				PreflightInstallGinkgoRule.Name,
+				BootstrapClusterRuleChain.Name,
+				InstallKonfluxRule.Name,
+				RegisterKonfluxToSprayProxyRule.Name,
+				SetupMultiPlatformTestsRule.Name,
+				NonTestFilesRule.Name,
+			},
+			expectedLabelFilters: []string{defaultE2ELabelFilter},
+			expectedEnv:          map[string]string{"CUSTOM_BUILDAH_REMOTE_PIPELINE_BUILD_BUNDLE_ARM64": "quay.io/org/pipeline:pipeline-bundle-test"},
+			expectedInstalls:     1,
+			expectedPaCRegisters: 1,
+			expectedMPSetups:     1,
+		},
+		{
+			name:        "e2e-tests PR paired with infra-deployments sets the infra-deployments branch",
+			category:    "ci",
+			repoName:    "e2e-tests",
+			env:         preflightEnv,
+			diffFiles:   rulesengine.Files{{Name: "tests/build/build.go", Status: "M"}},
+			pairedRepos: []string{"infra-deployments"},
+			expectedRules: []string{
+				E2ERepoCIRuleChain.Name,
+				E2ERepoSetDefaultSettingsRule.Name,
+				InfraDeploymentsPRPairingRule.Name,
+				PreflightInstallGinkgoRule.Name,
+				BootstrapClusterRuleChain.Name,
+				InstallKonfluxRule.Name,
+				RegisterKonfluxToSprayProxyRule.Name,
+				SetupMultiPlatformTestsRule.Name,
+				TestFilesOnlyRule.Name,
+				BuildORBuildTemplatesTestFileChangeOnlyRule.Name,
+			},
+			expectedLabelFilters: []string{""},
+			expectedFocusFiles:   []string{"tests/build/build.go"},
+			expectedEnv: map[string]string{
+				"INFRA_DEPLOYMENTS_ORG":    "author",
+				"INFRA_DEPLOYMENTS_BRANCH": "feature",
+			},
+			expectedInstalls:     1,
+			expectedPaCRegisters: 1,
+			expectedMPSetups:     1,
+		},
+		{
+			name:     "build-service PR deploys the PR image and runs the build-service suites",
+			category: "ci",
+			repoName: "build-service",
+			env:      preflightEnv,
+			expectedRules: []string{
+				BuildServiceCIRule.Name,
+				BuildServiceRepoSetDefaultSettingsRule.Name,
+				PreflightInstallGinkgoRule.Name,
+				BootstrapClusterWithSprayProxyRuleChain.Name,
+				InstallKonfluxRule.Name,
+				RegisterKonfluxToSprayProxyRule.Name,
+			},
+			expectedLabelFilters: []string{"build-service"},
+			expectedEnv: map[string]string{
+				"BUILD_SERVICE_IMAGE_REPO": "quay.io/org/component",
+				"BUILD_SERVICE_IMAGE_TAG":  "on-pr-abcdef",
+				"BUILD_SERVICE_PR_OWNER":   "author",
+				"BUILD_SERVICE_PR_SHA":     "abcdef",
+			},
+			expectedInstalls:     1,
+			expectedPaCRegisters: 1,
+		},
+		{
+			name:     "image-controller PR runs the image-controller suites",
+			category: "ci",
+			repoName: "image-controller",
+			env:      preflightEnv,
+			expectedRules: []string{
+				ImageControllerCIRule.Name,
+				ImageControllerRepoSetDefaultSettingsRule.Name,
+				PreflightInstallGinkgoRule.Name,
+				BootstrapClusterWithSprayProxyRuleChain.Name,
+				InstallKonfluxRule.Name,
+				RegisterKonfluxToSprayProxyRule.Name,
+			},
+			expectedLabelFilters: []string{"image-controller"},
+			expectedEnv:          map[string]string{"IMAGE_CONTROLLER_IMAGE_TAG": "on-pr-abcdef"},
+			expectedInstalls:     1,
+			expectedPaCRegisters: 1,
+		},
+		{
+			name:     "integration-service PR runs the integration-service suites",
+			category: "ci",
+			repoName: "integration-service",
+			env:      preflightEnv,
+			expectedRules: []string{
+				IntegrationServiceCIRule.Name,
+				IntegrationServiceRepoSetDefaultSettingsRule.Name,
+				PreflightInstallGinkgoRule.Name,
+				BootstrapClusterWithSprayProxyRuleChain.Name,
+				InstallKonfluxRule.Name,
+				RegisterKonfluxToSprayProxyRule.Name,
+			},
+			expectedLabelFilters: []string{"integration-service"},
+			expectedEnv:          map[string]string{"INTEGRATION_SERVICE_IMAGE_REPO": "quay.io/org/component"},
+			expectedInstalls:     1,
+			expectedPaCRegisters: 1,
+		},
+		{
+			name:     "release-service PR installs Konflux without SprayProxy",
+			category: "ci",
+			repoName: "release-service",
+			env:      preflightEnv,
+			expectedRules: []string{
+				ReleaseServiceCIRule.Name,
+				ReleaseServiceRepoSetDefaultSettingsRule.Name,
+				PreflightInstallGinkgoRule.Name,
+				InstallKonfluxRule.Name,
+			},
+			expectedLabelFilters: []string{"release-service"},
+			expectedEnv:          map[string]string{"RELEASE_SERVICE_CATALOG_REVISION": "development"},
+			expectedInstalls:     1,
+		},
+		{
+			name:     "release-service-catalog PR runs all the release pipelines",
+			category: "ci",
+			repoName: "release-service-catalog",
+			env:      preflightEnv,
+			expectedRules: []string{
+				ReleaseServiceCatalogCIRule.Name,
+				ReleaseServiceCatalogRepoSetDefaultSettingsRule.Name,
+				PreflightInstallGinkgoRule.Name,
+				InstallKonfluxRule.Name,
+			},
+			expectedLabelFilters: []string{"release-pipelines"},
+			expectedEnv: map[string]string{
+				"RELEASE_SERVICE_CATALOG_URL":      "https://github.com/author/release-service-catalog",
+				"RELEASE_SERVICE_CATALOG_REVISION": "abcdef",
+			},
+			expectedInstalls: 1,
+		},
+		{
+			name:        "release-service-catalog PR paired with release-service uses the paired image",
+			category:    "ci",
+			repoName:    "release-service-catalog",
+			env:         preflightEnv,
+			pairedRepos: []string{"release-service"},
+			pairedSha:   "fedcba",
+			expectedRules: []string{
+				ReleaseServiceCatalogCIPairedRule.Name,
+				ReleaseServiceCatalogRepoSetDefaultSettingsRule.Name,
+				PreflightInstallGinkgoRule.Name,
+				InstallKonfluxRule.Name,
+			},
+			expectedLabelFilters: []string{"release-pipelines && !fbc-tests && !multiarch-advisories && !rh-advisories && !release-to-github && !rh-push-to-redhat-io && !rhtap-service-push"},
+			expectedEnv: map[string]string{
+				"RELEASE_SERVICE_IMAGE_TAG": "on-pr-fedcba",
+				"RELEASE_SERVICE_PR_SHA":    "fedcba",
+			},
+			expectedInstalls: 1,
+		},
+		{
+			name:     "unknown repository doesn't run anything",
+			category: "ci",
+			repoName: "some-repo",
+			env:      preflightEnv,
+		},
+	})
+}
+
+func TestRulesRunCommandsThroughSideEffects(t *testing.T) {
+
+	for k, v := range preflightEnv {
+		t.Setenv(k, v)
+	}
+	r := newRecorder()
+	rctx := rulesengine.NewRuleCtx()
+	assert.NoError(t, WithSideEffects(rctx, r.sideEffects(catalogTestCase{pairedRepos: []string{"e2e-tests"}})))
+	rctx.PrRemoteName = "author"
+	rctx.PrBranchName = "feature"
+	rctx.RequiredBinaries = []string{"jq"}
+
+	ok, err := PrepareBranchRule.Check(rctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = preflight_check_rule.Check(rctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Equal(t, [][]string{
+		{"git", "remote", "add", "author", "https://github.com/author/e2e-tests.git"},
+		{"git", "fetch", "author"},
+		{"git", "checkout", "feature"},
+		{"git", "pull", "--rebase", "upstream", "main"},
+		{"which", "jq"},
+	}, r.commands)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -22,7 +23,6 @@ import (
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
-	"github.com/magefile/mage/sh"
 	gtypes "github.com/onsi/ginkgo/v2/types"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog"
@@ -33,12 +33,30 @@ import (
 
 func ExecuteTestAction(rctx *rulesengine.RuleCtx) error {
 
+	argsToRun, err := GinkgoArgs(rctx)
+	if err != nil {
+		return err
+	}
+
+	if rctx.Plan != nil {
+		rctx.Plan.AddCommand("ginkgo", argsToRun...)
+		return nil
+	}
+
+	return sideEffects(rctx).RunV("ginkgo", argsToRun...)
+
+}
+
+// GinkgoArgs generates the ginkgo CLI arguments for running the e2e suites based on the RuleCtx.
+// When a plan is being built the arguments of the actual (non dry run) execution are generated.
+func GinkgoArgs(rctx *rulesengine.RuleCtx) ([]string, error) {
+
 	/* This is so that we don't have ginkgo add the prefixes to
 	the command args i.e. '--ginkgo.xx' || '--test.xx' || '--go.xx'
 	we let ginkgo handle that when we actually run the ginkgo cmd.
 	We just want the user ginkgo CLI flags we can pass to ginkgo command */
 
-	if rctx.DryRun {
+	if rctx.DryRun && rctx.Plan == nil {
 		rctx.Parallel = false
 	} else {
 		// Set the number of parallel test processes
@@ -47,6 +65,9 @@ func ExecuteTestAction(rctx *rulesengine.RuleCtx) error {
 	}
 
 	var suiteConfig = rctx.SuiteConfig
+	if rctx.Plan != nil {
+		suiteConfig.DryRun = false
+	}
 	var reporterConfig = rctx.ReporterConfig
 	var cliConfig = rctx.CLIConfig
 	var goFlagsConfig = rctx.GoFlagsConfig
@@ -54,7 +75,7 @@ func ExecuteTestAction(rctx *rulesengine.RuleCtx) error {
 	var flagSet, err = gtypes.BuildRunCommandFlagSet(&suiteConfig, &reporterConfig, &cliConfig, &goFlagsConfig)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	errs := gtypes.VetConfig(flagSet, suiteConfig, reporterConfig)
@@ -86,7 +107,7 @@ func ExecuteTestAction(rctx *rulesengine.RuleCtx) error {
 	}
 	argsToRun = append(argsToRun, "-vv")
 	argsToRun = append(argsToRun, "./cmd", "--")
-	return sh.RunV("ginkgo", argsToRun...)
+	return argsToRun, nil
 
 }
 
@@ -166,15 +187,15 @@ func IsSprayProxyTokenSet(rctx *rulesengine.RuleCtx) (bool, error) {
 	return true, nil
 }
 
-func GitCheckoutRemoteBranch(remoteName, branchName string) error {
-	var git = sh.RunCmd("git")
+// GitCheckoutRemoteBranch checks out the branch of the e2e-tests fork, running git through the given side effects
+func GitCheckoutRemoteBranch(se *SideEffects, remoteName, branchName string) error {
 	for _, arg := range [][]string{
 		{"remote", "add", remoteName, fmt.Sprintf("https://github.com/%s/e2e-tests.git", remoteName)},
 		{"fetch", remoteName},
 		{"checkout", branchName},
 		{"pull", "--rebase", "upstream", "main"},
 	} {
-		if err := git(arg...); err != nil {
+		if err := se.Run("git", arg...); err != nil {
 			return fmt.Errorf("error when checkout out remote branch %s from remote %s: %v", branchName, remoteName, err)
 		}
 	}
@@ -223,7 +244,7 @@ func IsPrelightChecked(rctx *rulesengine.RuleCtx) (bool, error) {
 		}
 
 		for _, binaryName := range rctx.RequiredBinaries {
-			if err := sh.Run("which", binaryName); err != nil {
+			if err := sideEffects(rctx).Run("which", binaryName); err != nil {
 				return false, fmt.Errorf("binary %s not found in PATH - please install it first", binaryName)
 			}
 		}
@@ -302,7 +323,7 @@ var PrepareBranchRule = rulesengine.Rule{Name: "Prepare E2E branch for CI",
 			}
 		}
 
-		return IsPRPairingRequired("e2e-tests", rctx.PrRemoteName, rctx.PrBranchName), nil
+		return sideEffects(rctx).IsPRPairingRequired("e2e-tests", rctx.PrRemoteName, rctx.PrBranchName), nil
 	}), rulesengine.None{rulesengine.ConditionFunc(IsPeriodicJob),
 		rulesengine.ConditionFunc(IsRehearseJob)}},
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
@@ -321,7 +342,7 @@ var PrepareBranchRule = rulesengine.Rule{Name: "Prepare E2E branch for CI",
 			return nil
 		}
 
-		return GitCheckoutRemoteBranch(rctx.PrRemoteName, rctx.PrBranchName)
+		return GitCheckoutRemoteBranch(sideEffects(rctx), rctx.PrRemoteName, rctx.PrBranchName)
 	})}}
 
 var PreflightInstallGinkgoRule = rulesengine.Rule{Name: "Preflight Check",
@@ -335,7 +356,7 @@ var PreflightInstallGinkgoRule = rulesengine.Rule{Name: "Preflight Check",
 			klog.Info("Ginkgo Installation Complete.")
 			return nil
 		}
-		return sh.RunV("go", "install", "-mod=mod", "github.com/onsi/ginkgo/v2/ginkgo")
+		return sideEffects(rctx).RunV("go", "install", "-mod=mod", "github.com/onsi/ginkgo/v2/ginkgo")
 	}),
 	},
 }
@@ -352,7 +373,7 @@ var InstallKonfluxRule = rulesengine.Rule{Name: "Install Konflux",
 			klog.Info("Konflux Installation Complete.")
 			return nil
 		}
-		return retry(InstallKonflux, 2, 10*time.Second)
+		return retry(sideEffects(rctx).InstallKonflux, 2, 10*time.Second)
 	}),
 	},
 }
@@ -370,9 +391,9 @@ var RegisterKonfluxToSprayProxyRule = rulesengine.Rule{Name: "Register SprayProx
 			return nil
 		}
 
-		err := registerPacServer()
+		err := sideEffects(rctx).RegisterPaCServer()
 		if err != nil {
-			os.Setenv(constants.SKIP_PAC_TESTS_ENV, "true")
+			sideEffects(rctx).SetEnv(constants.SKIP_PAC_TESTS_ENV, "true")
 			if alertErr := HandleErrorWithAlert(fmt.Errorf("failed to register SprayProxy: %+v", err), slack.ErrorSeverityLevelError); alertErr != nil {
 				return alertErr
 			}
@@ -395,7 +416,23 @@ var SetupMultiPlatformTestsRule = rulesengine.Rule{Name: "Setup multi-platform t
 			return nil
 		}
 
-		return SetupMultiPlatformTests()
+		se := sideEffects(rctx)
+		bundles, err := se.BuildMultiPlatformPipelineBundles()
+		if err != nil {
+			return err
+		}
+		var envVars []string
+		for envVar := range bundles {
+			envVars = append(envVars, envVar)
+		}
+		sort.Strings(envVars)
+		for _, envVar := range envVars {
+			klog.Infof("SETTING ENV VAR %s to value %s\n", envVar, bundles[envVar])
+			if err := se.SetEnv(envVar, bundles[envVar]); err != nil {
+				return fmt.Errorf("error when setting %s env var: %v", envVar, err)
+			}
+		}
+		return nil
 	}),
 	},
 }
@@ -464,13 +501,16 @@ func HandleErrorWithAlert(err error, errLevel slack.ErrorSeverityLevel) error {
 	return nil
 }
 
-func SetupMultiPlatformTests() error {
+// BuildMultiPlatformPipelineBundles pushes the docker-build pipeline using buildah-remote task for every platform
+// and returns the CUSTOM_BUILDAH_REMOTE_PIPELINE_BUILD_BUNDLE_<PLATFORM> env vars pointing to the new bundles
+func BuildMultiPlatformPipelineBundles() (map[string]string, error) {
 	var platforms = []string{"linux/arm64", "linux/s390x", "linux/ppc64le"}
 
 	klog.Infof("going to create new Tekton bundle remote-build for the purpose of testing multi-platform-controller PR")
 	var err error
 	var defaultBundleRef string
 	var tektonObj runtime.Object
+	bundles := map[string]string{}
 
 	for _, platformType := range platforms {
 		tag := fmt.Sprintf("%d-%s", time.Now().Unix(), util.GenerateRandomString(4))
@@ -480,13 +520,13 @@ func SetupMultiPlatformTests() error {
 		var newPipelineYaml []byte
 
 		if err = utils.CreateDockerConfigFile(os.Getenv("QUAY_TOKEN")); err != nil {
-			return fmt.Errorf("failed to create docker config file: %+v", err)
+			return nil, fmt.Errorf("failed to create docker config file: %+v", err)
 		}
 		if defaultBundleRef, err = tekton.GetDefaultPipelineBundleRef(constants.BuildPipelineConfigConfigMapYamlURL, "docker-build"); err != nil {
-			return fmt.Errorf("failed to get the pipeline bundle ref: %+v", err)
+			return nil, fmt.Errorf("failed to get the pipeline bundle ref: %+v", err)
 		}
 		if tektonObj, err = tekton.ExtractTektonObjectFromBundle(defaultBundleRef, "pipeline", "docker-build"); err != nil {
-			return fmt.Errorf("failed to extract the Tekton Pipeline from bundle: %+v", err)
+			return nil, fmt.Errorf("failed to extract the Tekton Pipeline from bundle: %+v", err)
 		}
 		dockerPipelineObject := tektonObj.(*tektonapi.Pipeline)
 
@@ -518,24 +558,23 @@ func SetupMultiPlatformTests() error {
 			}
 		}
 		if currentBuildahTaskRef == "" {
-			return fmt.Errorf("failed to extract the Tekton Task from bundle: %+v", err)
+			return nil, fmt.Errorf("failed to extract the Tekton Task from bundle: %+v", err)
 		}
 		if newPipelineYaml, err = yaml.Marshal(dockerPipelineObject); err != nil {
-			return fmt.Errorf("error when marshalling a new pipeline to YAML: %v", err)
+			return nil, fmt.Errorf("error when marshalling a new pipeline to YAML: %v", err)
 		}
 
 		keychain := authn.NewMultiKeychain(authn.DefaultKeychain)
 		authOption := remoteimg.WithAuthFromKeychain(keychain)
 
 		if err = tekton.BuildAndPushTektonBundle(newPipelineYaml, newRemotePipeline, authOption); err != nil {
-			return fmt.Errorf("error when building/pushing a tekton pipeline bundle: %v", err)
+			return nil, fmt.Errorf("error when building/pushing a tekton pipeline bundle: %v", err)
 		}
 		platform := strings.ToUpper(strings.Split(platformType, "/")[1])
-		klog.Infof("SETTING ENV VAR %s to value %s\n", constants.CUSTOM_BUILDAH_REMOTE_PIPELINE_BUILD_BUNDLE_ENV+"_"+platform, newRemotePipeline.String())
-		os.Setenv(constants.CUSTOM_BUILDAH_REMOTE_PIPELINE_BUILD_BUNDLE_ENV+"_"+platform, newRemotePipeline.String())
+		bundles[constants.CUSTOM_BUILDAH_REMOTE_PIPELINE_BUILD_BUNDLE_ENV+"_"+platform] = newRemotePipeline.String()
 	}
 
-	return nil
+	return bundles, nil
 }
 
 func SetEnvVarsForComponentImageDeployment(rctx *rulesengine.RuleCtx) error {
@@ -561,10 +600,11 @@ func SetEnvVarsForComponentImageDeployment(rctx *rulesengine.RuleCtx) error {
 		}
 	}
 
-	os.Setenv(fmt.Sprintf("%s_IMAGE_REPO", rctx.ComponentEnvVarPrefix), repository)
-	os.Setenv(fmt.Sprintf("%s_IMAGE_TAG", rctx.ComponentEnvVarPrefix), tag)
-	os.Setenv(fmt.Sprintf("%s_PR_OWNER", rctx.ComponentEnvVarPrefix), rctx.PrRemoteName)
-	os.Setenv(fmt.Sprintf("%s_PR_SHA", rctx.ComponentEnvVarPrefix), rctx.PrCommitSha)
+	se := sideEffects(rctx)
+	se.SetEnv(fmt.Sprintf("%s_IMAGE_REPO", rctx.ComponentEnvVarPrefix), repository)
+	se.SetEnv(fmt.Sprintf("%s_IMAGE_TAG", rctx.ComponentEnvVarPrefix), tag)
+	se.SetEnv(fmt.Sprintf("%s_PR_OWNER", rctx.ComponentEnvVarPrefix), rctx.PrRemoteName)
+	se.SetEnv(fmt.Sprintf("%s_PR_SHA", rctx.ComponentEnvVarPrefix), rctx.PrCommitSha)
 
 	return nil
 }
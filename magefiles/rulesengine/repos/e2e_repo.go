@@ -2,12 +2,9 @@ package repos
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
-	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	"k8s.io/klog"
 )
 
@@ -157,7 +154,7 @@ var ReleaseTestHelperFilesChangeOnlyRule = rulesengine.Rule{Name: "E2E PR Releas
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		matched, err := filepath.Glob("tests/release/*/*.go")
+		matched, err := sideEffects(rctx).Glob("tests/release/*/*.go")
 		if err != nil {
 
 			return err
@@ -196,7 +193,7 @@ var KonfluxDemoConfigsFileOnlyChangeRule = rulesengine.Rule{Name: "E2E PR Konflu
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		matched, err := filepath.Glob("tests/*-demo/*-demo.go")
+		matched, err := sideEffects(rctx).Glob("tests/*-demo/*-demo.go")
 		if err != nil {
 
 			return err
@@ -242,7 +239,7 @@ var IntegrationTestsConstFileChangeRule = rulesengine.Rule{Name: "E2E PR Integra
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		matched, err := filepath.Glob("tests/integration-*/*.go")
+		matched, err := sideEffects(rctx).Glob("tests/integration-*/*.go")
 		if err != nil {
 
 			return err
@@ -293,7 +290,7 @@ var InfraDeploymentsPRPairingRule = rulesengine.Rule{Name: "Set Required Setting
 			}
 		}
 
-		return IsPRPairingRequired("infra-deployments", rctx.PrRemoteName, rctx.PrBranchName), nil
+		return sideEffects(rctx).IsPRPairingRequired("infra-deployments", rctx.PrRemoteName, rctx.PrBranchName), nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
@@ -305,8 +302,9 @@ var InfraDeploymentsPRPairingRule = rulesengine.Rule{Name: "Set Required Setting
 		}
 
 		klog.Infof("pairing with infra-deployments org %q and branch %q", rctx.PrRemoteName, rctx.PrBranchName)
-		os.Setenv("INFRA_DEPLOYMENTS_ORG", rctx.PrRemoteName)
-		os.Setenv("INFRA_DEPLOYMENTS_BRANCH", rctx.PrBranchName)
+		se := sideEffects(rctx)
+		se.SetEnv("INFRA_DEPLOYMENTS_ORG", rctx.PrRemoteName)
+		se.SetEnv("INFRA_DEPLOYMENTS_BRANCH", rctx.PrBranchName)
 		return nil
 	})},
 }
@@ -333,7 +331,7 @@ var E2ERepoSetDefaultSettingsRule = rulesengine.Rule{Name: "General Required Set
 		rctx.RequiresSprayProxyRegistering = true
 		klog.Info("multi-platform tests and require sprayproxy registering are set to TRUE")
 
-		rctx.DiffFiles, err = utils.GetChangedFiles(rctx.RepoName)
+		rctx.DiffFiles, err = sideEffects(rctx).GetChangedFiles(rctx.RepoName)
 		return err
 	})},
 }
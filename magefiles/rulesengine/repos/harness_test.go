@@ -0,0 +1,194 @@
+package repos
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/stretchr/testify/assert"
+)
+
+// repoRoot is the e2e-tests repository root, relative to this package. Rules that glob
+// test files on disk expect to be executed from there.
+const repoRoot = "../../.."
+
+// catalogTestCase describes the CI job the rules are evaluated for and what the rules are expected to do.
+type catalogTestCase struct {
+	name string
+	// category and optional catalog passed to RuleEngine.RunRules
+	category string
+	catalog  string
+
+	repoName  string
+	jobName   string
+	jobType   string
+	eventType string
+	diffFiles rulesengine.Files
+	env       map[string]string
+	// repositories for which a paired PR exists
+	pairedRepos []string
+	pairedSha   string
+
+	// names of all the rules, including the ones within rule chains, that evaluated to true
+	expectedRules []string
+	// label filter of every ginkgo execution, an empty string means no label filter
+	expectedLabelFilters []string
+	expectedFocusFiles   []string
+	expectedEnv          map[string]string
+	expectedInstalls     int
+	expectedPaCRegisters int
+	expectedMPSetups     int
+}
+
+// recorder records the calls of the fake side effects of the rules.
+type recorder struct {
+	commands     [][]string
+	env          map[string]string
+	installs     int
+	pacRegisters int
+	mpSetups     int
+}
+
+func newRecorder() *recorder {
+
+	return &recorder{env: map[string]string{}}
+}
+
+// sideEffects returns fakes of the rules side effects which record the calls and answer as described by the test case.
+func (r *recorder) sideEffects(tc catalogTestCase) *SideEffects {
+
+	runV := func(cmd string, args ...string) error {
+		r.commands = append(r.commands, append([]string{cmd}, args...))
+		return nil
+	}
+
+	return &SideEffects{
+		RunV: runV,
+		Run:  runV,
+		SetEnv: func(key, value string) error {
+			r.env[key] = value
+			return nil
+		},
+		GetChangedFiles: func(repoName string) (rulesengine.Files, error) {
+			return tc.diffFiles, nil
+		},
+		IsPRPairingRequired: func(repoForPairing string, remoteName string, branchName string) bool {
+			for _, repo := range tc.pairedRepos {
+				if repo == repoForPairing {
+					return true
+				}
+			}
+			return false
+		},
+		GetPairedCommitSha: func(repoForPairing string, rctx *rulesengine.RuleCtx) string {
+			return tc.pairedSha
+		},
+		InstallKonflux: func() error {
+			r.installs++
+			return nil
+		},
+		RegisterPaCServer: func() error {
+			r.pacRegisters++
+			return nil
+		},
+		BuildMultiPlatformPipelineBundles: func() (map[string]string, error) {
+			r.mpSetups++
+			return map[string]string{"CUSTOM_BUILDAH_REMOTE_PIPELINE_BUILD_BUNDLE_ARM64": "quay.io/org/pipeline:pipeline-bundle-test"}, nil
+		},
+		Glob: func(pattern string) ([]string, error) {
+			matches, err := filepath.Glob(filepath.Join(repoRoot, pattern))
+			for i := range matches {
+				matches[i], _ = filepath.Rel(repoRoot, matches[i])
+			}
+			return matches, err
+		},
+	}
+}
+
+// ginkgoLabelFilters returns the label filter of every recorded ginkgo execution.
+func (r *recorder) ginkgoLabelFilters() []string {
+
+	var filters []string
+	for _, cmd := range r.commands {
+		if cmd[0] != "ginkgo" {
+			continue
+		}
+		filter := ""
+		for _, arg := range cmd[1:] {
+			if strings.HasPrefix(arg, "--label-filter=") {
+				filter = strings.TrimPrefix(arg, "--label-filter=")
+			}
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters
+}
+
+// matchedRules returns the names of the rules that evaluated to true, in evaluation order.
+func matchedRules(node *rulesengine.TraceNode) []string {
+
+	var names []string
+	if node.Kind == "Rule" && node.Result {
+		names = append(names, node.Name)
+	}
+	for _, child := range node.Children {
+		names = appendNewRuleNames(names, matchedRules(child))
+	}
+
+	return names
+}
+
+// appendNewRuleNames appends the rule names which are not in names yet, rules within several chains are listed once.
+func appendNewRuleNames(names []string, newNames []string) []string {
+
+	for _, name := range newNames {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func runCatalogTestCases(t *testing.T, engine rulesengine.RuleEngine, cases []catalogTestCase) {
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			r := newRecorder()
+
+			rctx := rulesengine.NewRuleCtx()
+			assert.NoError(t, WithSideEffects(rctx, r.sideEffects(tc)))
+			rctx.RepoName = tc.repoName
+			rctx.JobName = tc.jobName
+			rctx.JobType = tc.jobType
+			rctx.TektonEventType = tc.eventType
+			rctx.DiffFiles = tc.diffFiles
+			rctx.PrRemoteName = "author"
+			rctx.PrBranchName = "feature"
+			rctx.PrCommitSha = "abcdef"
+			rctx.Tracer = rulesengine.NewTracer()
+
+			args := []string{tc.category}
+			if tc.catalog != "" {
+				args = append(args, tc.catalog)
+			}
+			assert.NoError(t, engine.RunRules(rctx, args...))
+
+			assert.Equal(t, tc.expectedRules, matchedRules(rctx.Tracer.Root()), "matched rules")
+			assert.Equal(t, tc.expectedLabelFilters, r.ginkgoLabelFilters(), "ginkgo label filters")
+			assert.ElementsMatch(t, tc.expectedFocusFiles, rctx.FocusFiles, "focus files")
+			for k, v := range tc.expectedEnv {
+				assert.Equal(t, v, r.env[k], "env var %s", k)
+			}
+			assert.Equal(t, tc.expectedInstalls, r.installs, "Konflux installations")
+			assert.Equal(t, tc.expectedPaCRegisters, r.pacRegisters, "PaC server registrations")
+			assert.Equal(t, tc.expectedMPSetups, r.mpSetups, "multi-platform setups")
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package repos
+
+import (
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+)
+
+// CatalogRegistry exposes the conditions, rule chains and actions of the Go
+// catalogs by name so they can be referenced from declarative catalog files.
+var CatalogRegistry = rulesengine.Registry{
+	Conditions: map[string]rulesengine.Conditional{
+		"IsPeriodicJob":                     rulesengine.ConditionFunc(IsPeriodicJob),
+		"IsRehearseJob":                     rulesengine.ConditionFunc(IsRehearseJob),
+		"IsLoadTestJob":                     rulesengine.ConditionFunc(IsLoadTestJob),
+		"IsTektonPushEventType":             rulesengine.ConditionFunc(IsTektonPushEventType),
+		"IsSprayProxyRequired":              rulesengine.ConditionFunc(IsSprayProxyRequired),
+		"IsSprayProxyHostSet":               rulesengine.ConditionFunc(IsSprayProxyHostSet),
+		"IsSprayProxyTokenSet":              rulesengine.ConditionFunc(IsSprayProxyTokenSet),
+		"IsMultiPlatformConfigRequired":     rulesengine.ConditionFunc(IsMultiPlatformConfigRequired),
+		"IsPrelightChecked":                 rulesengine.ConditionFunc(IsPrelightChecked),
+		"IsE2ETestsRepoPR":                  IsE2ETestsRepoPR,
+		"IsBuildServiceRepoPR":              IsBuildServiceRepoPR,
+		"IsImageControllerRepoPR":           IsImageControllerRepoPR,
+		"IsIntegrationServiceRepoPR":        IsIntegrationServiceRepoPR,
+		"IsReleaseServiceRepoPR":            IsReleaseServiceRepoPR,
+		"IsReleaseServiceCatalogRepoPR":     IsReleaseServiceCatalogRepoPR,
+		"CheckNoFilesChanged":               rulesengine.ConditionFunc(CheckNoFilesChanged),
+		"CheckPkgFilesChanged":              rulesengine.ConditionFunc(CheckPkgFilesChanged),
+		"CheckMageFilesChanged":             rulesengine.ConditionFunc(CheckMageFilesChanged),
+		"CheckCmdFilesChanged":              rulesengine.ConditionFunc(CheckCmdFilesChanged),
+		"CheckTektonFilesChanged":           rulesengine.ConditionFunc(CheckTektonFilesChanged),
+		"CheckReleasePipelinesTestsChanged": rulesengine.ConditionFunc(CheckReleasePipelinesTestsChanged),
+		// Rule chains
+		"PrepareBranchRule":                       &PrepareBranchRule,
+		"PreflightInstallGinkgoRule":              &PreflightInstallGinkgoRule,
+		"InstallKonfluxRule":                      &InstallKonfluxRule,
+		"RegisterKonfluxToSprayProxyRule":         &RegisterKonfluxToSprayProxyRule,
+		"SetupMultiPlatformTestsRule":             &SetupMultiPlatformTestsRule,
+		"BootstrapClusterRuleChain":               &BootstrapClusterRuleChain,
+		"BootstrapClusterWithSprayProxyRuleChain": &BootstrapClusterWithSprayProxyRuleChain,
+		"InfraDeploymentsPRPairingRule":           &InfraDeploymentsPRPairingRule,
+	},
+	Actions: map[string]rulesengine.Action{
+		"ExecuteTestAction":                        rulesengine.ActionFunc(ExecuteTestAction),
+		"ExecuteDefaultTestAction":                 rulesengine.ActionFunc(ExecuteDefaultTestAction),
+		"ExecuteAllTestsExceptUpgradeTestSuite":    rulesengine.ActionFunc(ExecuteAllTestsExceptUpgradeTestSuite),
+		"ExecuteInfraDeploymentsDefaultTestAction": rulesengine.ActionFunc(ExecuteInfraDeploymentsDefaultTestAction),
+		"SetEnvVarsForComponentImageDeployment":    rulesengine.ActionFunc(SetEnvVarsForComponentImageDeployment),
+	},
+}
+
+// NewRuleEngine returns an engine with all the Go catalogs, it backs engine.MageEngine
+func NewRuleEngine() rulesengine.RuleEngine {
+	return rulesengine.RuleEngine{
+		"tests": {
+			"e2e-repo":          E2ETestRulesCatalog,
+			"infra-deployments": InfraDeploymentsRulesCatalog,
+		},
+		"demo": {
+			"local-workflow": DemoCatalog,
+		},
+
+		"ci": {
+			"e2e-repo":                E2ECIChainCatalog,
+			"release-service":         ReleaseServiceCICatalog,
+			"release-service-catalog": ReleaseServiceCatalogCICatalog,
+			"integration-service":     IntegrationServiceCICatalog,
+			"image-controller":        ImageControllerCICatalog,
+			"build-service":           BuildServiceCICatalog,
+			// TODO: to be implemented in a follow-up PR
+			//"infra-deployments": InfraDeploymentsCIChainCatalog,
+		},
+	}
+}
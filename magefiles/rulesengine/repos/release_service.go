@@ -41,7 +41,7 @@ var ReleaseServiceRepoSetDefaultSettingsRule = rulesengine.Rule{Name: "General R
 			rctx.ComponentImageTag = "redhat-appstudio-release-service-image"
 		}
 		//This is env variable is specified for release service
-		os.Setenv(fmt.Sprintf("%s_CATALOG_REVISION", rctx.ComponentEnvVarPrefix), "development")
+		sideEffects(rctx).SetEnv(fmt.Sprintf("%s_CATALOG_REVISION", rctx.ComponentEnvVarPrefix), "development")
 		return SetEnvVarsForComponentImageDeployment(rctx)
 	})},
 }
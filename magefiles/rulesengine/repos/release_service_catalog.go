@@ -2,7 +2,6 @@ package repos
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
@@ -56,20 +55,21 @@ var ReleaseServiceCatalogRepoSetDefaultSettingsRule = rulesengine.Rule{Name: "Ge
 		}
 		rctx.ComponentEnvVarPrefix = "RELEASE_SERVICE"
 
+		se := sideEffects(rctx)
 		//This is env variable is specified for release service catalog
-		os.Setenv(fmt.Sprintf("%s_CATALOG_URL", rctx.ComponentEnvVarPrefix), fmt.Sprintf("https://github.com/%s/%s", rctx.PrRemoteName, rctx.RepoName))
-		os.Setenv(fmt.Sprintf("%s_CATALOG_REVISION", rctx.ComponentEnvVarPrefix), rctx.PrCommitSha)
-		os.Setenv("DEPLOY_ONLY", "application-api dev-sso enterprise-contract has pipeline-service integration internal-services release")
+		se.SetEnv(fmt.Sprintf("%s_CATALOG_URL", rctx.ComponentEnvVarPrefix), fmt.Sprintf("https://github.com/%s/%s", rctx.PrRemoteName, rctx.RepoName))
+		se.SetEnv(fmt.Sprintf("%s_CATALOG_REVISION", rctx.ComponentEnvVarPrefix), rctx.PrCommitSha)
+		se.SetEnv("DEPLOY_ONLY", "application-api dev-sso enterprise-contract has pipeline-service integration internal-services release")
 
 		if rctx.IsPaired && !strings.Contains(rctx.JobName, "rehearse") {
-			os.Setenv(fmt.Sprintf("%s_IMAGE_REPO", rctx.ComponentEnvVarPrefix),
+			se.SetEnv(fmt.Sprintf("%s_IMAGE_REPO", rctx.ComponentEnvVarPrefix),
 				"quay.io/redhat-user-workloads/rhtap-release-2-tenant/release-service/release-service")
-			pairedSha := GetPairedCommitSha("release-service", rctx)
+			pairedSha := se.GetPairedCommitSha("release-service", rctx)
 			if pairedSha != "" {
-				os.Setenv(fmt.Sprintf("%s_IMAGE_TAG", rctx.ComponentEnvVarPrefix), fmt.Sprintf("on-pr-%s", pairedSha))
+				se.SetEnv(fmt.Sprintf("%s_IMAGE_TAG", rctx.ComponentEnvVarPrefix), fmt.Sprintf("on-pr-%s", pairedSha))
 			}
-			os.Setenv(fmt.Sprintf("%s_PR_OWNER", rctx.ComponentEnvVarPrefix), rctx.PrRemoteName)
-			os.Setenv(fmt.Sprintf("%s_PR_SHA", rctx.ComponentEnvVarPrefix), pairedSha)
+			se.SetEnv(fmt.Sprintf("%s_PR_OWNER", rctx.ComponentEnvVarPrefix), rctx.PrRemoteName)
+			se.SetEnv(fmt.Sprintf("%s_PR_SHA", rctx.ComponentEnvVarPrefix), pairedSha)
 		}
 		return nil
 	})},
@@ -86,7 +86,7 @@ var isRehearse = func(rctx *rulesengine.RuleCtx) (bool, error) {
 }
 
 var isPaired = func(rctx *rulesengine.RuleCtx) (bool, error) {
-	rctx.IsPaired = IsPRPairingRequired("release-service", rctx.PrRemoteName, rctx.PrBranchName)
+	rctx.IsPaired = sideEffects(rctx).IsPRPairingRequired("release-service", rctx.PrRemoteName, rctx.PrBranchName)
 	return rctx.IsPaired, nil
 }
 
@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
-	"github.com/magefile/mage/sh"
 	"k8s.io/klog"
 )
 
@@ -36,7 +35,7 @@ var isPreflightCheck = func(rctx *rulesengine.RuleCtx) (bool, error) {
 		}
 
 		for _, binaryName := range rctx.RequiredBinaries {
-			if err := sh.Run("which", binaryName); err != nil {
+			if err := sideEffects(rctx).Run("which", binaryName); err != nil {
 				return false, fmt.Errorf("binary %s not found in PATH - please install it first", binaryName)
 			}
 		}
@@ -0,0 +1,56 @@
+package repos
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/magefile/mage/sh"
+)
+
+// sideEffectsKey is the RuleCtx.RuleData key of the SideEffects used by the rules
+const sideEffectsKey = "sideEffects"
+
+// SideEffects are the ways the rules reach out to the environment (commands, env vars, GitHub, the cluster).
+// The rules get them from the RuleCtx, so the catalogs can be tested offline with recording fakes.
+type SideEffects struct {
+	RunV                              func(cmd string, args ...string) error
+	Run                               func(cmd string, args ...string) error
+	SetEnv                            func(key, value string) error
+	GetChangedFiles                   func(repoName string) (rulesengine.Files, error)
+	IsPRPairingRequired               func(repoForPairing string, remoteName string, branchName string) bool
+	GetPairedCommitSha                func(repoForPairing string, rctx *rulesengine.RuleCtx) string
+	InstallKonflux                    func() error
+	RegisterPaCServer                 func() error
+	BuildMultiPlatformPipelineBundles func() (map[string]string, error)
+	Glob                              func(pattern string) ([]string, error)
+}
+
+// DefaultSideEffects returns the side effects executed in CI
+func DefaultSideEffects() *SideEffects {
+	return &SideEffects{
+		RunV:                              sh.RunV,
+		Run:                               sh.Run,
+		SetEnv:                            os.Setenv,
+		GetChangedFiles:                   utils.GetChangedFiles,
+		IsPRPairingRequired:               IsPRPairingRequired,
+		GetPairedCommitSha:                GetPairedCommitSha,
+		InstallKonflux:                    InstallKonflux,
+		RegisterPaCServer:                 registerPacServer,
+		BuildMultiPlatformPipelineBundles: BuildMultiPlatformPipelineBundles,
+		Glob:                              filepath.Glob,
+	}
+}
+
+// WithSideEffects makes the rules evaluated with the RuleCtx use the given side effects instead of the default ones
+func WithSideEffects(rctx *rulesengine.RuleCtx, se *SideEffects) error {
+	return rctx.AddRuleData(sideEffectsKey, se)
+}
+
+func sideEffects(rctx *rulesengine.RuleCtx) *SideEffects {
+	if se, ok := rctx.GetRuleData(sideEffectsKey).(*SideEffects); ok {
+		return se
+	}
+	return DefaultSideEffects()
+}
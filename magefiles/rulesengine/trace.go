@@ -0,0 +1,195 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// TraceNode records the evaluation of a single Rule or Conditional.
+// Nested conditionals of a Rule or an Any/All/None filter are stored as children.
+type TraceNode struct {
+	Kind     string       `json:"kind"`
+	Name     string       `json:"name,omitempty"`
+	Result   bool         `json:"result"`
+	Error    string       `json:"error,omitempty"`
+	Files    []string     `json:"files,omitempty"`
+	Children []*TraceNode `json:"children,omitempty"`
+}
+
+// Tracer builds the evaluation tree while the engine evaluates rules. It is enabled
+// by setting RuleCtx.Tracer, otherwise evaluation isn't recorded at all.
+type Tracer struct {
+	root  *TraceNode
+	stack []*TraceNode
+}
+
+func NewTracer() *Tracer {
+
+	root := &TraceNode{Kind: "Catalog"}
+	return &Tracer{root: root, stack: []*TraceNode{root}}
+}
+
+// Root returns the top level node whose children are the evaluated rules.
+func (t *Tracer) Root() *TraceNode {
+
+	return t.root
+}
+
+func (t *Tracer) enter(kind, name string) *TraceNode {
+
+	node := &TraceNode{Kind: kind, Name: name}
+	parent := t.stack[len(t.stack)-1]
+	parent.Children = append(parent.Children, node)
+	t.stack = append(t.stack, node)
+
+	return node
+}
+
+func (t *Tracer) exit(node *TraceNode, ok bool, err error) {
+
+	node.Result = ok
+	if err != nil {
+		node.Error = err.Error()
+	}
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *Tracer) recordFile(file File) {
+
+	node := t.stack[len(t.stack)-1]
+	node.Files = dedupeAppend(node.Files, file.Name)
+}
+
+// traceCheck wraps the evaluation of a conditional with a trace node when tracing is enabled.
+func traceCheck(rctx *RuleCtx, kind, name string, check func() (bool, error)) (bool, error) {
+
+	if rctx.Tracer == nil {
+		return check()
+	}
+
+	node := rctx.Tracer.enter(kind, name)
+	ok, err := check()
+	rctx.Tracer.exit(node, ok, err)
+
+	return ok, err
+}
+
+// traceFilteredFiles records files matched by a Files filter. Condition functions filter the files without
+// access to the RuleCtx, so the files of the explained RuleCtx carry its Tracer, also into filtered copies.
+func traceFilteredFiles(files Files) {
+
+	for _, f := range files {
+		if f.tracer != nil {
+			f.tracer.recordFile(f)
+		}
+	}
+}
+
+// Tree renders the trace as an indented tree, one evaluated node per line.
+func (n *TraceNode) Tree() string {
+
+	var sb strings.Builder
+	for _, child := range n.Children {
+		child.writeTree(&sb, 0)
+	}
+
+	return sb.String()
+}
+
+func (n *TraceNode) writeTree(sb *strings.Builder, depth int) {
+
+	result := "false"
+	if n.Result {
+		result = "true"
+	}
+
+	line := fmt.Sprintf("%s[%s] %s", strings.Repeat("  ", depth), result, n.Kind)
+	if n.Name != "" {
+		line = fmt.Sprintf("%s: %s", line, n.Name)
+	}
+	if n.Error != "" {
+		line = fmt.Sprintf("%s (error: %s)", line, n.Error)
+	}
+	if len(n.Files) != 0 {
+		line = fmt.Sprintf("%s (files: %s)", line, strings.Join(n.Files, ", "))
+	}
+	sb.WriteString(line + "\n")
+
+	for _, child := range n.Children {
+		child.writeTree(sb, depth+1)
+	}
+}
+
+// JSON renders the trace of the evaluated rules as indented JSON.
+func (n *TraceNode) JSON() (string, error) {
+
+	out, err := json.MarshalIndent(n.Children, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rule trace: %+v", err)
+	}
+
+	return string(out), nil
+}
+
+// Explain evaluates conditions of every rule of the selected catalogs and returns the recorded evaluation tree.
+// The catalogs are selected in the same way as RunRules. No actions are applied, not even those of rules
+// nested in conditions, so conditions depending on data set by such actions may evaluate differently than in RunRules.
+// The rules are evaluated on a copy of the passed in RuleCtx, which is not modified.
+func (e *RuleEngine) Explain(rctx *RuleCtx, args ...string) (*TraceNode, error) {
+
+	loaded, err := e.selectCatalogs(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer := NewTracer()
+	ectx := *rctx
+	ectx.RuleData = make(map[string]any, len(rctx.RuleData))
+	for k, v := range rctx.RuleData {
+		ectx.RuleData[k] = v
+	}
+	ectx.DiffFiles = make(Files, len(rctx.DiffFiles))
+	for i, f := range rctx.DiffFiles {
+		f.tracer = tracer
+		ectx.DiffFiles[i] = f
+	}
+	ectx.DryRun = true
+	ectx.Tracer = tracer
+	ectx.conditionsOnly = true
+
+	for _, rule := range loaded {
+		// Errors are recorded in the trace so that the remaining rules can still be explained
+		_, _ = rule.Eval(&ectx)
+	}
+
+	return tracer.Root(), nil
+}
+
+// funcName returns the package qualified name of a function, i.e. repos.CheckPkgFilesChanged
+func funcName(f any) string {
+
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
+
+func dedupeAppend(values []string, value string) []string {
+
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
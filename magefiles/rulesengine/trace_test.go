@@ -0,0 +1,135 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkPkgChanged(rctx *RuleCtx) (bool, error) {
+
+	return len(rctx.DiffFiles.FilterByDirString("pkg/")) != 0, nil
+}
+
+func TestExplain(t *testing.T) {
+
+	applied := false
+	engine := RuleEngine{"tests": {"repo": RuleCatalog{
+		{Name: "Pkg Rule",
+			Condition: All{ConditionFunc(checkPkgChanged), None{&FilesSpec{Glob: "docs/**"}}},
+			Actions: []Action{ActionFunc(func(rctx *RuleCtx) error {
+				applied = true
+				return nil
+			})}},
+		{Name: "Failing Rule",
+			Condition: Any{ConditionFunc(func(rctx *RuleCtx) (bool, error) {
+				return false, fmt.Errorf("boom")
+			})}},
+	}}}
+
+	rctx := NewRuleCtx()
+	rctx.DiffFiles = Files{{Name: "pkg/utils/util.go", Status: "M"}, {Name: "docs/readme.md", Status: "M"}}
+
+	trace, err := engine.Explain(rctx, "tests", "repo")
+	assert.NoError(t, err)
+	assert.False(t, applied)
+	assert.Nil(t, rctx.Tracer)
+	assert.False(t, rctx.DryRun)
+	assert.Len(t, trace.Children, 2)
+
+	pkgRule := trace.Children[0]
+	assert.Equal(t, "Rule", pkgRule.Kind)
+	assert.False(t, pkgRule.Result)
+	all := pkgRule.Children[0]
+	assert.Equal(t, "All", all.Kind)
+	assert.Equal(t, "rulesengine.checkPkgChanged", all.Children[0].Name)
+	assert.True(t, all.Children[0].Result)
+	assert.Equal(t, []string{"pkg/utils/util.go"}, all.Children[0].Files)
+	none := all.Children[1]
+	assert.False(t, none.Result)
+	assert.Equal(t, "files(glob=docs/**)", none.Children[0].Name)
+	assert.Equal(t, []string{"docs/readme.md"}, none.Children[0].Files)
+
+	failingRule := trace.Children[1]
+	assert.Equal(t, "boom", failingRule.Error)
+
+	assert.Equal(t, `[false] Rule: Pkg Rule
+  [false] All
+    [true] ConditionFunc: rulesengine.checkPkgChanged (files: pkg/utils/util.go)
+    [false] None
+      [true] Files: files(glob=docs/**) (files: docs/readme.md)
+[false] Rule: Failing Rule (error: boom)
+  [false] Any (error: boom)
+    [false] ConditionFunc: rulesengine.TestExplain.func2 (error: boom)
+`, trace.Tree())
+
+	out, err := trace.JSON()
+	assert.NoError(t, err)
+	var decoded []TraceNode
+	assert.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	assert.Len(t, decoded, 2)
+}
+
+func TestExplainConcurrently(t *testing.T) {
+
+	engine := RuleEngine{"tests": {"repo": RuleCatalog{
+		{Name: "Pkg Rule", Condition: ConditionFunc(checkPkgChanged)},
+	}}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rctx := NewRuleCtx()
+			file := fmt.Sprintf("pkg/file%d.go", i)
+			rctx.DiffFiles = Files{{Name: file, Status: "M"}}
+
+			trace, err := engine.Explain(rctx)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{file}, trace.Children[0].Children[0].Files)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestExplainChainedFilters(t *testing.T) {
+
+	engine := RuleEngine{"tests": {"repo": RuleCatalog{
+		{Name: "Modified Go Rule", Condition: ConditionFunc(func(rctx *RuleCtx) (bool, error) {
+			files := rctx.DiffFiles
+			goFiles := files.FilterByDirGlob("**/*.go")
+			return len(goFiles.FilterByStatus("M")) != 0, nil
+		})},
+	}}}
+
+	rctx := NewRuleCtx()
+	rctx.DiffFiles = Files{{Name: "pkg/a.go", Status: "M"}, {Name: "pkg/b.go", Status: "A"}, {Name: "docs/readme.md", Status: "M"}}
+
+	trace, err := engine.Explain(rctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pkg/a.go", "pkg/b.go"}, trace.Children[0].Children[0].Files)
+}
+
+func TestExplainNestedRuleActions(t *testing.T) {
+
+	applied := false
+	nested := &Rule{Name: "Nested Rule", Condition: ConditionFunc(checkPkgChanged), Actions: []Action{ActionFunc(func(rctx *RuleCtx) error {
+		applied = true
+		return nil
+	})}}
+	engine := RuleEngine{"tests": {"repo": RuleCatalog{
+		{Name: "Outer Rule", Condition: All{nested}},
+	}}}
+
+	rctx := NewRuleCtx()
+	rctx.DiffFiles = Files{{Name: "pkg/utils/util.go", Status: "M"}}
+
+	trace, err := engine.Explain(rctx)
+	assert.NoError(t, err)
+	assert.True(t, trace.Children[0].Result)
+	assert.False(t, applied, "actions of nested rules are not applied when explaining")
+}
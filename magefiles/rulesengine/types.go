@@ -50,6 +50,19 @@ func (e *RuleEngine) ListCatalogsByCategory(cat string) (string, error) {
 
 func (e *RuleEngine) RunRules(rctx *RuleCtx, args ...string) error {
 
+	fullCatalogs, err := e.selectCatalogs(args...)
+	if err != nil {
+		return err
+	}
+
+	return e.runLoadedCatalog(fullCatalogs, rctx)
+
+}
+
+// selectCatalogs loads the rules of the catalogs matching the optional
+// category (args[0]) and catalog (args[1]) filters.
+func (e *RuleEngine) selectCatalogs(args ...string) (RuleCatalog, error) {
+
 	var fullCatalogs RuleCatalog
 	foundCat := false
 	foundCtl := false
@@ -88,14 +101,14 @@ func (e *RuleEngine) RunRules(rctx *RuleCtx, args ...string) error {
 	}
 
 	if !foundCat && len(args) == 1 {
-		return fmt.Errorf("%s is not a category registered in the engine", args[0])
+		return nil, fmt.Errorf("%s is not a category registered in the engine", args[0])
 	}
 
 	if !foundCtl && len(args) == 2 {
-		return fmt.Errorf("%s is not a catalog registered in the engine", args[1])
+		return nil, fmt.Errorf("%s is not a catalog registered in the engine", args[1])
 	}
 
-	return e.runLoadedCatalog(fullCatalogs, rctx)
+	return fullCatalogs, nil
 
 }
 
@@ -168,7 +181,10 @@ func (e *RuleEngine) dryRun(matched RuleCatalog, rctx *RuleCtx) error {
 	klog.Info("DryRun has been enabled will apply them in dry run mode")
 	for _, rule := range matched {
 
-		return rule.DryRun(rctx)
+		if err := rule.DryRun(rctx); err != nil {
+			klog.Errorf("Failed to execute rule in dry run mode: %s", rule.String())
+			return err
+		}
 
 	}
 
@@ -217,6 +233,11 @@ type Any []Conditional
 
 func (a Any) Check(rctx *RuleCtx) (bool, error) {
 
+	return traceCheck(rctx, "Any", "", func() (bool, error) { return a.check(rctx) })
+}
+
+func (a Any) check(rctx *RuleCtx) (bool, error) {
+
 	// Initial logic was to pass on the first
 	// eval to true but that might not be the
 	// case. So not eval all and as long as any
@@ -240,6 +261,11 @@ type All []Conditional
 
 func (a All) Check(rctx *RuleCtx) (bool, error) {
 
+	return traceCheck(rctx, "All", "", func() (bool, error) { return a.check(rctx) })
+}
+
+func (a All) check(rctx *RuleCtx) (bool, error) {
+
 	for _, c := range a {
 
 		ok, err := c.Check(rctx)
@@ -258,6 +284,11 @@ type None []Conditional
 
 func (a None) Check(rctx *RuleCtx) (bool, error) {
 
+	return traceCheck(rctx, "None", "", func() (bool, error) { return a.check(rctx) })
+}
+
+func (a None) check(rctx *RuleCtx) (bool, error) {
+
 	for _, c := range a {
 
 		ok, err := c.Check(rctx)
@@ -282,7 +313,12 @@ func (af ActionFunc) Execute(rctx *RuleCtx) error {
 type ConditionFunc func(rctx *RuleCtx) (bool, error)
 
 func (cf ConditionFunc) Check(rctx *RuleCtx) (bool, error) {
-	return cf(rctx)
+
+	if rctx.Tracer == nil {
+		return cf(rctx)
+	}
+
+	return traceCheck(rctx, "ConditionFunc", funcName(cf), func() (bool, error) { return cf(rctx) })
 }
 
 type Rule struct {
@@ -305,13 +341,20 @@ type IRule interface {
 
 func (r *Rule) Eval(rctx *RuleCtx) (bool, error) {
 
-	return r.Condition.Check(rctx)
+	return traceCheck(rctx, "Rule", r.Name, func() (bool, error) { return r.Condition.Check(rctx) })
 }
 
 func (r *Rule) Apply(rctx *RuleCtx) error {
 
+	if rctx.Plan != nil && len(r.Actions) != 0 {
+		rctx.Plan.addRule(r)
+	}
+
 	for _, action := range r.Actions {
 
+		if rctx.Plan != nil {
+			rctx.Plan.addAction(action)
+		}
 		err := action.Execute(rctx)
 		if err != nil {
 			return err
@@ -321,18 +364,15 @@ func (r *Rule) Apply(rctx *RuleCtx) error {
 	return nil
 }
 
+// DryRun executes the actions in dry run mode. The DryRun setting
+// of the RuleCtx is restored once the actions have been executed.
 func (r *Rule) DryRun(rctx *RuleCtx) error {
 
+	dryRun := rctx.DryRun
 	rctx.DryRun = true
-	for _, action := range r.Actions {
+	defer func() { rctx.DryRun = dryRun }()
 
-		err := action.Execute(rctx)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return r.Apply(rctx)
 }
 
 func (r *Rule) Check(rctx *RuleCtx) (bool, error) {
@@ -342,6 +382,9 @@ func (r *Rule) Check(rctx *RuleCtx) (bool, error) {
 		return false, err
 	}
 	if ok {
+		if rctx.conditionsOnly {
+			return true, nil
+		}
 		if rctx.DryRun {
 			return true, r.DryRun(rctx)
 		}
@@ -357,6 +400,8 @@ func (r *Rule) Check(rctx *RuleCtx) (bool, error) {
 type File struct {
 	Status string
 	Name   string
+	// tracer records files matched by filters, set only for files of the RuleCtx being explained
+	tracer *Tracer
 }
 
 type Files []File
@@ -374,6 +419,8 @@ func (cfs *Files) FilterByDirString(filter string) Files {
 		subfiles = append(subfiles, file)
 	}
 
+	traceFilteredFiles(subfiles)
+
 	return subfiles
 
 }
@@ -392,6 +439,8 @@ func (cfs *Files) FilterByDirGlob(filter string) Files {
 		subfiles = append(subfiles, file)
 	}
 
+	traceFilteredFiles(subfiles)
+
 	return subfiles
 
 }
@@ -410,6 +459,8 @@ func (cfs *Files) FilterByStatus(filter string) Files {
 
 	}
 
+	traceFilteredFiles(subfiles)
+
 	return subfiles
 
 }
@@ -450,6 +501,12 @@ type RuleCtx struct {
 	TektonEventType               string
 	RequiresMultiPlatformTests    bool
 	RequiresSprayProxyRegistering bool
+	// Tracer records the evaluation tree of the rules when set, see RuleEngine.Explain
+	Tracer *Tracer
+	// Plan records the actions that would be executed instead of running external commands, see RuleEngine.BuildPlan
+	Plan *Plan
+	// conditionsOnly skips actions of matched rules nested in conditions, see RuleEngine.Explain
+	conditionsOnly bool
 }
 
 func NewRuleCtx() *RuleCtx {
@@ -478,6 +535,9 @@ func NewRuleCtx() *RuleCtx {
 		"",
 		"",
 		false,
+		false,
+		nil,
+		nil,
 		false}
 
 	//init defaults we've used so far
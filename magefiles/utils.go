@@ -8,9 +8,10 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
-	"sync"
 	"text/template"
 	"time"
 
@@ -21,8 +22,10 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	plumbingHttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	sprig "github.com/go-task/slim-sprig"
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/slack"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/gc"
 	"github.com/konflux-ci/image-controller/pkg/quay"
 	"github.com/magefile/mage/sh"
 )
@@ -111,6 +114,23 @@ func goFmt(path string) error {
 	return nil
 }
 
+// exportTestPlan prints the plan and stores it as JSON in the artifact directory
+func exportTestPlan(plan *rulesengine.Plan) error {
+	fmt.Print(plan.String())
+
+	out, err := plan.JSON()
+	if err != nil {
+		return err
+	}
+	planPath := filepath.Join(artifactDir, "e2e-test-plan.json")
+	if err := os.WriteFile(planPath, []byte(out), 0644); err != nil {
+		return fmt.Errorf("failed to write the test plan to %s: %+v", planPath, err)
+	}
+	klog.Infof("test plan stored in %s", planPath)
+
+	return nil
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -169,175 +189,66 @@ func renderTemplate(destination, templatePath string, templateData interface{},
 }
 
 func cleanupQuayReposAndRobots(quayService quay.QuayService, quayOrg string) error {
-	r, err := regexp.Compile(fmt.Sprintf(`^(%s)`, quayPrefixesToDeleteRegexp))
+	policy, err := gc.NewPolicy(fmt.Sprintf(`^(%s)`, quayPrefixesToDeleteRegexp), 24*time.Hour)
 	if err != nil {
 		return err
 	}
+	return runGarbageCollector(gcConfig{DryRun: false}, &gc.QuayRobots{Service: quayService, Organization: quayOrg, Policy: policy})
+}
 
-	repos, err := quayService.GetAllRepositories(quayOrg)
-	if err != nil {
-		return err
-	}
-
-	// Key is the repo name without slashes which is the same as robot name
-	// Value is the repo name with slashes
-	reposMap := make(map[string]string)
-
-	for _, repo := range repos {
-		if r.MatchString(repo.Name) {
-			sanitizedRepoName := strings.ReplaceAll(repo.Name, "/", "") // repo name without slashes
-			reposMap[sanitizedRepoName] = repo.Name
-		}
-	}
+func cleanupQuayTags(quayService quay.QuayService, organization, repository string) error {
+	policy := gc.Policy{MaxAge: 7 * 24 * time.Hour}
+	return runGarbageCollector(gcConfig{DryRun: false}, &gc.QuayTags{Service: quayService, Organization: organization, Repository: repository, Policy: policy})
+}
 
-	robots, err := quayService.GetAllRobotAccounts(quayOrg)
-	if err != nil {
-		return err
+// Deletes the private repos older than 7 days
+func cleanupPrivateRepos(quayService quay.QuayService, quayOrg string, repoNamePrefixes []string) error {
+	var quotedPrefixes []string
+	for _, prefix := range repoNamePrefixes {
+		quotedPrefixes = append(quotedPrefixes, regexp.QuoteMeta(prefix))
 	}
-
-	r, err = regexp.Compile(fmt.Sprintf(`^%s\+(%s)`, quayOrg, quayPrefixesToDeleteRegexp))
+	policy, err := gc.NewPolicy(fmt.Sprintf(`^(%s)`, strings.Join(quotedPrefixes, "|")), 7*24*time.Hour)
 	if err != nil {
 		return err
 	}
-
-	const timeFormat = "Mon, 02 Jan 2006 15:04:05 -0700"
-
-	// Deletes robots and their repos with correct prefix if created more than 24 hours ago
-	for _, robot := range robots {
-		parsed, err := time.Parse(timeFormat, robot.Created)
-		if err != nil {
-			return err
-		}
-
-		// If robot.Name has correct prefix and was created more than 24 hours ago
-		if r.MatchString(robot.Name) && time.Since(parsed) > 24*time.Hour {
-			// Robot name without the name of org which is the same as previous sanitizedRepoName
-			// redhat-appstudio-qe+e2e-demos turns to e2e-demos
-			splitRobotName := strings.Split(robot.Name, "+")
-			if len(splitRobotName) != 2 {
-				return fmt.Errorf("failed to split robot name into 2 parts, got %d parts", len(splitRobotName))
-			}
-			sanitizedRepoName := splitRobotName[1] // Same as robot shortname
-			if repo, exists := reposMap[sanitizedRepoName]; exists {
-				deleted, err := quayService.DeleteRepository(quayOrg, repo)
-				if err != nil {
-					return fmt.Errorf("failed to delete repository %s, error: %s", repo, err)
-				}
-				if !deleted {
-					fmt.Printf("repository %s has already been deleted, skipping\n", repo)
-				}
-			}
-			// DeleteRobotAccount uses robot shortname, so e2e-demos instead of redhat-appstudio-qe+e2e-demos
-			deleted, err := quayService.DeleteRobotAccount(quayOrg, splitRobotName[1])
-			if err != nil {
-				return fmt.Errorf("failed to delete robot account %s, error: %s", robot.Name, err)
-			}
-			if !deleted {
-				fmt.Printf("robot account %s has already been deleted, skipping\n", robot.Name)
-			}
-		}
-	}
-	return nil
+	return runGarbageCollector(gcConfig{DryRun: false}, &gc.QuayRepositories{Service: quayService, Organization: quayOrg, Policy: policy, PrivateOnly: true})
 }
 
-func cleanupQuayTags(quayService quay.QuayService, organization, repository string) error {
-	workerCount := 10
-	var wg sync.WaitGroup
-
-	var allTags []quay.Tag
-	var errors []error
-
-	page := 1
-	for {
-		tags, hasAdditional, err := quayService.GetTagsFromPage(organization, repository, page)
-		page++
-		if err != nil {
-			errors = append(errors, fmt.Errorf("error getting tags of `%s` repository of `%s` organization on page `%d`, error: %s", repository, organization, page, err))
-			continue
-		}
-		allTags = append(allTags, tags...)
-		if !hasAdditional {
-			break
-		}
-	}
-
-	wg.Add(workerCount)
-
-	var errorsMutex sync.Mutex
-	for i := 0; i < workerCount; i++ {
-		go func(startIdx int, allTags []quay.Tag, errors []error, errorsMutex *sync.Mutex, wg *sync.WaitGroup) {
-			defer wg.Done()
-			for idx := startIdx; idx < len(allTags); idx += workerCount {
-				tag := allTags[idx]
-				if time.Unix(tag.StartTS, 0).Before(time.Now().AddDate(0, 0, -7)) {
-					deleted, err := quayService.DeleteTag(organization, repository, tag.Name)
-					if err != nil {
-						errorsMutex.Lock()
-						errors = append(errors, fmt.Errorf("error during deletion of tag `%s` in repository `%s` of organization `%s`, error: `%s`", tag.Name, repository, organization, err))
-						errorsMutex.Unlock()
-					} else if !deleted {
-						fmt.Printf("tag `%s` in repository `%s` of organization `%s` was not deleted\n", tag.Name, repository, organization)
-					}
-				}
-			}
-		}(i, allTags, errors, &errorsMutex, &wg)
-	}
-
-	wg.Wait()
-
-	if len(errors) == 0 {
-		return nil
-	}
-
-	var errBuilder strings.Builder
-	for _, err := range errors {
-		errBuilder.WriteString(fmt.Sprintf("%s\n", err))
-	}
-	return fmt.Errorf("encountered errors during CleanupQuayTags: %s", errBuilder.String())
+// gcConfig configures runGarbageCollector, values are overridden by GC_WORKERS, GC_RATE_LIMIT and GC_REPORT_FILE env vars when set
+type gcConfig struct {
+	DryRun bool
+	// Number of concurrent deletions, gc.DefaultWorkers when zero
+	Workers int
+	// Deletions per second, unlimited when zero
+	RateLimit float64
+	// The report is saved to the file when set
+	ReportFile string
 }
 
-func repoNameStartsWithPrefix(prefixes []string, repoName string) bool {
-	for _, prefix := range prefixes {
-		hasPrefix := strings.HasPrefix(repoName, prefix)
-		if hasPrefix {
-			return true
-		}
+// runGarbageCollector deletes resources found by the collectors (only lists them in dry run) and prints the report
+func runGarbageCollector(config gcConfig, collectors ...gc.Collector) error {
+	if config.Workers == 0 {
+		config.Workers = gc.DefaultWorkers
 	}
-	return false
-}
-
-// Deletes the private repos older than 7 days
-func cleanupPrivateRepos(quayService quay.QuayService, quayOrg string, repoNamePrefixes []string) error {
-	repos, err := quayService.GetAllRepositories(quayOrg)
+	workers, err := strconv.Atoi(utils.GetEnv("GC_WORKERS", strconv.Itoa(config.Workers)))
 	if err != nil {
-		return err
+		return fmt.Errorf("unable to parse GC_WORKERS env var: %+v", err)
 	}
-	foundError := false
-	var errors []error
-	for _, repo := range repos {
-		if !repo.IsPublic && repoNameStartsWithPrefix(repoNamePrefixes, repo.Name) {
-			lastModifiedTime := int64(repo.LastModified)
-			currentTime := time.Now().Unix()
-			timeDiff := int64(7 * 24 * 3600)
-			if currentTime-lastModifiedTime > timeDiff {
-				_, err := quayService.DeleteRepository(quayOrg, repo.Name)
-				if err != nil {
-					foundError = true
-					errors = append(errors, fmt.Errorf("failed to delete repository %s with error: %v", repo.Name, err))
-					continue
-				}
-				fmt.Printf("Deleted repo %s successfully\n", repo.Name)
-			}
-		}
+	rateLimit, err := strconv.ParseFloat(utils.GetEnv("GC_RATE_LIMIT", strconv.FormatFloat(config.RateLimit, 'f', -1, 64)), 64)
+	if err != nil {
+		return fmt.Errorf("unable to parse GC_RATE_LIMIT env var: %+v", err)
 	}
-	if foundError {
-		var errBuilder strings.Builder
-		for _, err := range errors {
-			errBuilder.WriteString(fmt.Sprintf("%s\n", err))
+
+	report := gc.NewGarbageCollector(gc.Options{DryRun: config.DryRun, Workers: workers, RateLimit: rateLimit}, collectors...).Run(context.Background())
+	if err := report.WriteText(os.Stdout); err != nil {
+		klog.Errorf("failed to print garbage collector report: %+v", err)
+	}
+	if reportFile := utils.GetEnv("GC_REPORT_FILE", config.ReportFile); reportFile != "" {
+		if err := report.Save(reportFile); err != nil {
+			klog.Errorf("failed to save garbage collector report: %+v", err)
 		}
-		return fmt.Errorf("encountered errors during cleanup of private repos: %s", errBuilder.String())
 	}
-	return nil
+	return report.Err()
 }
 
 func MergePRInRemote(branch string, forkOrganization string, repoPath string) error {
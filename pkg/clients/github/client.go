@@ -2,10 +2,12 @@ package github
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/gofri/go-github-ratelimit/github_ratelimit"
 	"github.com/google/go-github/v44/github"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/ledger"
 	"golang.org/x/oauth2"
 )
 
@@ -16,6 +18,7 @@ const (
 type Github struct {
 	client       *github.Client
 	organization string
+	ledger       *ledger.Ledger
 }
 
 func NewGithubClient(token, organization string) (*Github, error) {
@@ -34,3 +37,17 @@ func NewGithubClient(token, organization string) (*Github, error) {
 
 	return githubClient, nil
 }
+
+// SetLedger enables tracking of branches, pull requests and webhooks created by the client for cleanup
+func (g *Github) SetLedger(l *ledger.Ledger) {
+	g.ledger = l
+}
+
+// isNotFound returns true for errors of requests to resources which don't exist (anymore)
+func isNotFound(err error) bool {
+	if errResponse, ok := err.(*github.ErrorResponse); ok && errResponse.Response != nil {
+		// deleting missing ref returns 422 "Reference does not exist"
+		return errResponse.Response.StatusCode == http.StatusNotFound || errResponse.Response.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
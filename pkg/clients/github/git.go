@@ -38,6 +38,14 @@ func (g *Github) CreateRef(repository, baseBranchName, sha, newBranchName string
 	if err != nil {
 		return fmt.Errorf("error when creating a new branch '%s' for the repo '%s': %+v", newBranchName, repository, err)
 	}
+	organization := g.organization
+	g.ledger.Track(fmt.Sprintf("GitHub branch %s/%s:%s", organization, repository, newBranchName), func() error {
+		_, err := g.client.Git.DeleteRef(context.Background(), organization, repository, fmt.Sprintf(HEADS, newBranchName))
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		return nil
+	})
 	err = utils.WaitUntilWithInterval(func() (done bool, err error) {
 		exist, err := g.ExistsRef(repository, newBranchName)
 		if err != nil {
@@ -66,6 +74,37 @@ func (g *Github) ExistsRef(repository, branchName string) (bool, error) {
 	return true, nil
 }
 
+// ListBranches returns all branches of the repository, the branches contain only SHA of the head commit
+func (g *Github) ListBranches(repository string) ([]*github.Branch, error) {
+	opt := &github.BranchListOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	var allBranches []*github.Branch
+	for {
+		branches, resp, err := g.client.Repositories.ListBranches(context.Background(), g.organization, repository, opt)
+		if err != nil {
+			return nil, fmt.Errorf("error when listing branches of the repo '%s': %+v", repository, err)
+		}
+		allBranches = append(allBranches, branches...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return allBranches, nil
+}
+
+// GetBranch returns the branch including details of its head commit
+func (g *Github) GetBranch(repository, branchName string) (*github.Branch, error) {
+	branch, _, err := g.client.Repositories.GetBranch(context.Background(), g.organization, repository, branchName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting the branch '%s' of the repo '%s': %+v", branchName, repository, err)
+	}
+	return branch, nil
+}
+
 func (g *Github) UpdateGithubOrg(githubOrg string) {
 	g.organization = githubOrg
 }
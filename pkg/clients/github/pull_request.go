@@ -31,16 +31,47 @@ func (g *Github) CreatePullRequest(repository, title, body, head, base string) (
 	if err != nil {
 		return nil, err
 	}
+	organization := g.organization
+	g.ledger.Track(fmt.Sprintf("GitHub pull request %s/%s#%d", organization, repository, pr.GetNumber()), func() error {
+		// merged and closed pull requests are left as they are
+		_, _, err := g.client.PullRequests.Edit(context.Background(), organization, repository, pr.GetNumber(), &github.PullRequest{State: github.String("closed")})
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		return nil
+	})
 	return pr, nil
 }
 
 func (g *Github) ListPullRequests(repository string) ([]*github.PullRequest, error) {
-	prs, _, err := g.client.PullRequests.List(context.Background(), g.organization, repository, &github.PullRequestListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("error when listing pull requests for the repo %s: %v", repository, err)
+	opt := &github.PullRequestListOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
 	}
+	var allPrs []*github.PullRequest
+	for {
+		prs, resp, err := g.client.PullRequests.List(context.Background(), g.organization, repository, opt)
+		if err != nil {
+			return nil, fmt.Errorf("error when listing pull requests for the repo %s: %v", repository, err)
+		}
+		allPrs = append(allPrs, prs...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return allPrs, nil
+}
 
-	return prs, nil
+// ClosePullRequest closes the pull request without merging it
+func (g *Github) ClosePullRequest(repository string, prNumber int) error {
+	_, _, err := g.client.PullRequests.Edit(context.Background(), g.organization, repository, prNumber, &github.PullRequest{State: github.String("closed")})
+	if err != nil {
+		return fmt.Errorf("error when closing pull request %d in the repo %s: %v", prNumber, repository, err)
+	}
+	return nil
 }
 
 func (g *Github) ListPullRequestCommentsSince(repository string, prNumber int, since time.Time) ([]*github.IssueComment, error) {
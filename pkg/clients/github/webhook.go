@@ -33,6 +33,14 @@ func (g *Github) CreateWebhook(repository, url string) (int64, error) {
 	if err != nil {
 		return 0, fmt.Errorf("error when creating a webhook: %v", err)
 	}
+	organization := g.organization
+	g.ledger.Track(fmt.Sprintf("GitHub webhook %s/%s %d", organization, repository, hook.GetID()), func() error {
+		_, err := g.client.Repositories.DeleteHook(context.Background(), organization, repository, hook.GetID())
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		return nil
+	})
 	return hook.GetID(), err
 }
 
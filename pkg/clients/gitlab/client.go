@@ -1,6 +1,10 @@
 package gitlab
 
 import (
+	"fmt"
+	"net/http"
+
+	"github.com/konflux-ci/e2e-tests/pkg/utils/ledger"
 	gitlabClient "github.com/xanzy/go-gitlab"
 )
 
@@ -10,6 +14,7 @@ const (
 
 type GitlabClient struct {
 	client *gitlabClient.Client
+	ledger *ledger.Ledger
 }
 
 func NewGitlabClient(accessToken, baseUrl string) (*GitlabClient, error) {
@@ -26,3 +31,19 @@ func NewGitlabClient(accessToken, baseUrl string) (*GitlabClient, error) {
 func (gc *GitlabClient) GetClient() *gitlabClient.Client {
 	return gc.client
 }
+
+// SetLedger enables tracking of branches created by the client for cleanup
+func (gc *GitlabClient) SetLedger(l *ledger.Ledger) {
+	gc.ledger = l
+}
+
+// trackBranch registers deletion of created branch, branches which are already gone are ignored
+func (gc *GitlabClient) trackBranch(projectID, branchName string) {
+	gc.ledger.Track(fmt.Sprintf("GitLab branch %s:%s", projectID, branchName), func() error {
+		_, err := gc.client.Branches.DeleteBranch(projectID, branchName)
+		if errResponse, ok := err.(*gitlabClient.ErrorResponse); ok && errResponse.Response.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return err
+	})
+}
@@ -23,6 +23,7 @@ func (gc *GitlabClient) CreateBranch(projectID, newBranchName, defaultBranch str
 	if err != nil {
 		return fmt.Errorf("failed to create branch %s in project %s: %w", newBranchName, projectID, err)
 	}
+	gc.trackBranch(projectID, newBranchName)
 
 	// Wait for the branch to actually exist
 	Eventually(func(gomega Gomega) {
@@ -85,6 +86,7 @@ func (gc *GitlabClient) CreateGitlabNewBranch(projectID, branchName, sha, baseBr
 		}
 		return fmt.Errorf("failed to create branch '%s': %v", branchName, err)
 	}
+	gc.trackBranch(projectID, branchName)
 
 	return nil
 }
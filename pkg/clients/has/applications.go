@@ -46,7 +46,7 @@ func (h *HasController) CreateApplicationWithTimeout(name string, namespace stri
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
 	defer cancel()
-	if err := h.KubeRest().Create(ctx, application); err != nil {
+	if err := h.CreateAndTrack(ctx, application); err != nil {
 		return nil, err
 	}
 
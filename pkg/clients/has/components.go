@@ -9,6 +9,7 @@ import (
 
 	"github.com/devfile/library/v2/pkg/util"
 	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/tekton"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/logs"
@@ -164,16 +165,22 @@ type RetryOptions struct {
 func (h *HasController) WaitForComponentPipelineToBeFinished(component *appservice.Component, sha string, t *tekton.TektonController, r *RetryOptions, prToUpdate *pipeline.PipelineRun) error {
 	attempts := 1
 	app := component.Spec.Application
-	pr := &pipeline.PipelineRun{}
+	var pr *pipeline.PipelineRun
 
 	for {
-		err := wait.PollUntilContextTimeout(context.Background(), constants.PipelineRunPollingInterval, 30*time.Minute, true, func(ctx context.Context) (done bool, err error) {
-			pr, err = h.GetComponentPipelineRun(component.GetName(), app, component.GetNamespace(), sha)
-
-			if err != nil {
-				GinkgoWriter.Printf("PipelineRun has not been created yet for the Component %s/%s\n", component.GetNamespace(), component.GetName())
+		pr = nil
+		pipelineRunLabels := map[string]string{"appstudio.openshift.io/component": component.GetName(), "appstudio.openshift.io/application": app}
+		if sha != "" {
+			pipelineRunLabels["pipelinesascode.tekton.dev/sha"] = sha
+		}
+		GinkgoWriter.Printf("Waiting for PipelineRun of the Component %s/%s to finish\n", component.GetNamespace(), component.GetName())
+		opts := kubeCl.WatchOptions{Namespace: component.GetNamespace(), Labels: pipelineRunLabels, Timeout: 30 * time.Minute}
+		_, err := kubeCl.WaitForCondition(h.DynamicClient(), kubeCl.PipelineRunGVR, opts, func(pipelineRun *pipeline.PipelineRun) (bool, error) {
+			// the failed PipelineRun deleted before retriggering can still be seen while its finalizers are removed
+			if pipelineRun.GetDeletionTimestamp() != nil {
 				return false, nil
 			}
+			pr = pipelineRun
 
 			GinkgoWriter.Printf("PipelineRun %s reason: %s\n", pr.Name, pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).GetReason())
 
@@ -185,11 +192,11 @@ func (h *HasController) WaitForComponentPipelineToBeFinished(component *appservi
 				return true, nil
 			}
 
-			var prLogs string
-			if err = t.StorePipelineRun(component.GetName(), pr); err != nil {
+			if err := t.StorePipelineRun(component.GetName(), pr); err != nil {
 				GinkgoWriter.Printf("failed to store PipelineRun %s:%s: %s\n", pr.GetNamespace(), pr.GetName(), err.Error())
 			}
-			if prLogs, err = t.GetPipelineRunLogs(component.GetName(), pr.Name, pr.Namespace); err != nil {
+			prLogs, err := t.GetPipelineRunLogs(component.GetName(), pr.Name, pr.Namespace)
+			if err != nil {
 				GinkgoWriter.Printf("failed to get logs for PipelineRun %s:%s: %s\n", pr.GetNamespace(), pr.GetName(), err.Error())
 			}
 			return false, fmt.Errorf("%s", prLogs)
@@ -260,7 +267,7 @@ func (h *HasController) CreateComponent(componentSpec appservice.ComponentSpec,
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
 	defer cancel()
-	if err := h.KubeRest().Create(ctx, componentObject); err != nil {
+	if err := h.CreateAndTrack(ctx, componentObject); err != nil {
 		return nil, err
 	}
 
@@ -295,7 +302,7 @@ func (h *HasController) CreateComponentWithDockerSource(applicationName, compone
 			Route:          "",
 		},
 	}
-	err := h.KubeRest().Create(context.Background(), component)
+	err := h.CreateAndTrack(context.Background(), component)
 	if err != nil {
 		return nil, err
 	}
@@ -332,7 +339,7 @@ func (h *HasController) DeleteComponent(name string, namespace string, reportErr
 	}
 
 	// RHTAPBUGS-978: temporary timeout to 15min
-	err := utils.WaitUntil(h.ComponentDeleted(&component), 15*time.Minute)
+	err := kubeCl.WaitForDeletion(h.DynamicClient(), kubeCl.ComponentGVR, kubeCl.WatchOptions{Namespace: namespace, Name: name, Timeout: 15 * time.Minute})
 
 	// temporary logs
 	deletionTime := time.Since(start).Minutes()
@@ -2,14 +2,24 @@ package imagecontroller
 
 import (
 	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
+	"github.com/konflux-ci/image-controller/pkg/quay"
 )
 
 type ImageController struct {
 	*kubeCl.CustomClient
+	quayClient       quay.QuayService
+	quayOrganization string
 }
 
 func NewSuiteController(kube *kubeCl.CustomClient) (*ImageController, error) {
 	return &ImageController{
-		kube,
+		CustomClient: kube,
 	}, nil
 }
+
+// SetQuay enables deletion of Quay repositories and robot accounts of tracked ImageRepositories, which are left behind
+// when image-controller fails to delete them in its finalizer (the failure is only logged and the finalizer removed)
+func (i *ImageController) SetQuay(client quay.QuayService, organization string) {
+	i.quayClient = client
+	i.quayOrganization = organization
+}
@@ -2,15 +2,24 @@ package imagecontroller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	"github.com/konflux-ci/image-controller/api/v1alpha1"
+	"github.com/konflux-ci/image-controller/pkg/quay"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	rclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// CreateImageRepositoryCR creates new ImageRepository
+// Time for image-controller to delete Quay repository and robot accounts of deleted ImageRepository
+const imageRepositoryDeletionTimeout = 2 * time.Minute
+
+// CreateImageRepositoryCR creates new ImageRepository, tracked in the ledger together with its Quay repository and robot accounts
 func (i *ImageController) CreateImageRepositoryCR(name, namespace, applicationName, componentName string) (*v1alpha1.ImageRepository, error) {
 	imageRepository := &v1alpha1.ImageRepository{
 		ObjectMeta: metav1.ObjectMeta{
@@ -27,9 +36,54 @@ func (i *ImageController) CreateImageRepositoryCR(name, namespace, applicationNa
 	if err != nil {
 		return nil, err
 	}
+	i.Ledger().Track(fmt.Sprintf("ImageRepository %s/%s", namespace, name), func() error {
+		return deleteImageRepository(i.KubeRest(), i.quayClient, i.quayOrganization, types.NamespacedName{Name: name, Namespace: namespace}, imageRepositoryDeletionTimeout)
+	})
 	return imageRepository, nil
 }
 
+// deleteImageRepository deletes the ImageRepository, waits for image-controller to finalize it and deletes its Quay repository
+// and robot accounts which are left behind, quayClient can be nil when Quay is not accessible
+func deleteImageRepository(kubeRest rclient.Client, quayClient quay.QuayService, quayOrganization string, key types.NamespacedName, timeout time.Duration) error {
+	imageRepository := &v1alpha1.ImageRepository{}
+	if err := kubeRest.Get(context.Background(), key, imageRepository); err != nil {
+		return rclient.IgnoreNotFound(err)
+	}
+	if err := rclient.IgnoreNotFound(kubeRest.Delete(context.Background(), imageRepository)); err != nil {
+		return err
+	}
+	err := utils.WaitUntilWithInterval(func() (bool, error) {
+		err := kubeRest.Get(context.Background(), key, &v1alpha1.ImageRepository{})
+		if k8sErrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}, time.Second, timeout)
+	if err != nil {
+		return fmt.Errorf("ImageRepository %s was not finalized: %+v", key, err)
+	}
+	if quayClient == nil {
+		return nil
+	}
+
+	var errs []error
+	credentials := imageRepository.Status.Credentials
+	for _, robotAccount := range []string{credentials.PushRobotAccountName, credentials.PullRobotAccountName} {
+		if robotAccount == "" {
+			continue
+		}
+		if _, err := quayClient.DeleteRobotAccount(quayOrganization, robotAccount); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete robot account %s: %+v", robotAccount, err))
+		}
+	}
+	if repository := imageRepository.Spec.Image.Name; repository != "" {
+		if _, err := quayClient.DeleteRepository(quayOrganization, repository); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete repository %s: %+v", repository, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // GetImageRepositoryCR returns the requested ImageRepository object
 func (i *ImageController) GetImageRepositoryCR(name, namespace string) (*v1alpha1.ImageRepository, error) {
 	namespacedName := types.NamespacedName{
@@ -0,0 +1,77 @@
+package imagecontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konflux-ci/image-controller/api/v1alpha1"
+	"github.com/konflux-ci/image-controller/pkg/quay"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeQuay struct {
+	quay.QuayService
+	deleted []string
+}
+
+func (q *fakeQuay) DeleteRepository(organization, imageRepository string) (bool, error) {
+	q.deleted = append(q.deleted, "repository "+organization+"/"+imageRepository)
+	return true, nil
+}
+
+func (q *fakeQuay) DeleteRobotAccount(organization, robotName string) (bool, error) {
+	q.deleted = append(q.deleted, "robot "+organization+"/"+robotName)
+	return true, nil
+}
+
+func newImageRepository(finalizers ...string) *v1alpha1.ImageRepository {
+	imageRepository := &v1alpha1.ImageRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "comp", Namespace: "user-tenant", Finalizers: finalizers},
+	}
+	imageRepository.Spec.Image.Name = "user-tenant/comp"
+	imageRepository.Status.Credentials.PushRobotAccountName = "user_tenant_comp"
+	imageRepository.Status.Credentials.PullRobotAccountName = "user_tenant_comp_pull"
+	return imageRepository
+}
+
+func newKubeRest(objects ...*v1alpha1.ImageRepository) *fake.ClientBuilder {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		builder = builder.WithObjects(obj)
+	}
+	return builder
+}
+
+func TestDeleteImageRepository(t *testing.T) {
+	key := types.NamespacedName{Name: "comp", Namespace: "user-tenant"}
+	kubeRest := newKubeRest(newImageRepository()).Build()
+	quayClient := &fakeQuay{}
+
+	assert.NoError(t, deleteImageRepository(kubeRest, quayClient, "org", key, time.Second))
+	assert.Equal(t, []string{"robot org/user_tenant_comp", "robot org/user_tenant_comp_pull", "repository org/user-tenant/comp"}, quayClient.deleted)
+	assert.Error(t, kubeRest.Get(context.Background(), key, &v1alpha1.ImageRepository{}))
+}
+
+func TestDeleteImageRepositoryNotFinalized(t *testing.T) {
+	key := types.NamespacedName{Name: "comp", Namespace: "user-tenant"}
+	kubeRest := newKubeRest(newImageRepository("appstudio.openshift.io/image-repository")).Build()
+	quayClient := &fakeQuay{}
+
+	assert.Error(t, deleteImageRepository(kubeRest, quayClient, "org", key, 100*time.Millisecond))
+	assert.Empty(t, quayClient.deleted, "Quay resources are not touched while image-controller still finalizes them")
+}
+
+func TestDeleteImageRepositoryAlreadyDeleted(t *testing.T) {
+	key := types.NamespacedName{Name: "comp", Namespace: "user-tenant"}
+	quayClient := &fakeQuay{}
+
+	assert.NoError(t, deleteImageRepository(newKubeRest().Build(), quayClient, "org", key, time.Second))
+	assert.Empty(t, quayClient.deleted)
+}
@@ -52,7 +52,7 @@ func (i *IntegrationController) CreateIntegrationTestScenario(itsName, applicati
 		}
 	}
 
-	err := i.KubeRest().Create(context.Background(), integrationTestScenario)
+	err := i.CreateAndTrack(context.Background(), integrationTestScenario)
 	if err != nil {
 		return nil, err
 	}
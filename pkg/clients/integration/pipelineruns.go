@@ -7,6 +7,7 @@ import (
 	"time"
 
 	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
@@ -52,7 +53,7 @@ func (i *IntegrationController) CreateIntegrationPipelineRun(snapshotName, names
 			},
 		},
 	}
-	err := i.KubeRest().Create(context.Background(), testpipelineRun)
+	err := i.CreateAndTrack(context.Background(), testpipelineRun)
 	if err != nil {
 		return nil, err
 	}
@@ -147,12 +148,17 @@ func (i *IntegrationController) WaitForIntegrationPipelineToGetStarted(testScena
 // WaitForIntegrationPipelineToBeFinished wait for given integration pipeline to finish.
 // In case of failure, this function retries till it gets timed out.
 func (i *IntegrationController) WaitForIntegrationPipelineToBeFinished(testScenario *integrationv1beta2.IntegrationTestScenario, snapshot *appstudioApi.Snapshot, appNamespace string) error {
-	return wait.PollUntilContextTimeout(context.Background(), constants.PipelineRunPollingInterval, 20*time.Minute, true, func(ctx context.Context) (done bool, err error) {
-		pipelineRun, err := i.GetIntegrationPipelineRun(testScenario.Name, snapshot.Name, appNamespace)
-		if err != nil {
-			GinkgoWriter.Println("PipelineRun has not been created yet for test scenario %s and snapshot %s/%s", testScenario.GetName(), snapshot.GetNamespace(), snapshot.GetName())
-			return false, nil
-		}
+	opts := kubeCl.WatchOptions{
+		Namespace: appNamespace,
+		Labels: map[string]string{
+			"pipelines.appstudio.openshift.io/type": "test",
+			"test.appstudio.openshift.io/scenario":  testScenario.Name,
+			"appstudio.openshift.io/snapshot":       snapshot.Name,
+		},
+		Timeout: 20 * time.Minute,
+	}
+	GinkgoWriter.Printf("Waiting for PipelineRun of test scenario %s and snapshot %s/%s to finish\n", testScenario.GetName(), snapshot.GetNamespace(), snapshot.GetName())
+	_, err := kubeCl.WaitForCondition(i.DynamicClient(), kubeCl.PipelineRunGVR, opts, func(pipelineRun *tektonv1.PipelineRun) (bool, error) {
 		GinkgoWriter.Printf("PipelineRun %s reason: %s\n", pipelineRun.Name, pipelineRun.GetStatusCondition().GetCondition(apis.ConditionSucceeded).GetReason())
 
 		if !pipelineRun.IsDone() {
@@ -162,12 +168,13 @@ func (i *IntegrationController) WaitForIntegrationPipelineToBeFinished(testScena
 		if pipelineRun.GetStatusCondition().GetCondition(apis.ConditionSucceeded).IsTrue() {
 			return true, nil
 		}
-		var prLogs string
-		if prLogs, err = tekton.GetFailedPipelineRunLogs(i.KubeRest(), i.KubeInterface(), pipelineRun); err != nil {
+		prLogs, err := tekton.GetFailedPipelineRunLogs(i.KubeRest(), i.KubeInterface(), pipelineRun)
+		if err != nil {
 			return false, fmt.Errorf("failed to get PLR logs: %+v", err)
 		}
 		return false, fmt.Errorf("%s", prLogs)
 	})
+	return err
 }
 
 func (i *IntegrationController) isScenarioInExpectedScenarios(testScenario *integrationv1beta2.IntegrationTestScenario, expectedTestScenarios []string) bool {
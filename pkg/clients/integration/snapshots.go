@@ -7,14 +7,13 @@ import (
 
 	"github.com/devfile/library/v2/pkg/util"
 	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
-	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
 	"github.com/konflux-ci/e2e-tests/pkg/logs"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
 	. "github.com/onsi/ginkgo/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -38,7 +37,7 @@ func (i *IntegrationController) CreateSnapshotWithComponents(snapshotName, compo
 			Components:  snapshotComponents,
 		},
 	}
-	return snapshot, i.KubeRest().Create(context.Background(), snapshot)
+	return snapshot, i.CreateAndTrack(context.Background(), snapshot)
 }
 
 // CreateSnapshotWithImage creates a snapshot using an image.
@@ -141,20 +140,16 @@ func (i *IntegrationController) DeleteAllSnapshotsInASpecificNamespace(namespace
 }
 
 // WaitForSnapshotToGetCreated wait for the Snapshot to get created successfully.
+// The Snapshot is looked up in the same way as GetSnapshot does.
 func (i *IntegrationController) WaitForSnapshotToGetCreated(snapshotName, pipelinerunName, componentName, testNamespace string) (*appstudioApi.Snapshot, error) {
-	var snapshot *appstudioApi.Snapshot
+	GinkgoWriter.Printf("Waiting for Snapshot (name: '%s', pipelineRun: '%s', component: '%s') to get created in %s namespace\n", snapshotName, pipelinerunName, componentName, testNamespace)
 
-	err := wait.PollUntilContextTimeout(context.Background(), constants.PipelineRunPollingInterval, 10*time.Minute, true, func(ctx context.Context) (done bool, err error) {
-		snapshot, err = i.GetSnapshot(snapshotName, pipelinerunName, componentName, testNamespace)
-		if err != nil {
-			GinkgoWriter.Printf("unable to get the Snapshot within the namespace %s. Error: %v", testNamespace, err)
-			return false, nil
-		}
-
-		return true, nil
+	opts := kubeCl.WatchOptions{Namespace: testNamespace, Name: snapshotName, Timeout: 10 * time.Minute}
+	return kubeCl.WaitForCondition(i.DynamicClient(), kubeCl.SnapshotGVR, opts, func(snapshot *appstudioApi.Snapshot) (bool, error) {
+		return len(snapshotName) > 0 ||
+			(len(pipelinerunName) > 0 && snapshot.Labels["appstudio.openshift.io/build-pipelinerun"] == pipelinerunName) ||
+			(len(componentName) > 0 && snapshot.Labels["appstudio.openshift.io/component"] == componentName), nil
 	})
-
-	return snapshot, err
 }
 
 // ListAllSnapshots returns a list of all Snapshots in a given namespace.
@@ -12,6 +12,7 @@ import (
 	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/ledger"
 	imagecontroller "github.com/konflux-ci/image-controller/api/v1alpha1"
 	integrationservicev1beta2 "github.com/konflux-ci/integration-service/api/v1beta2"
 	pacv1alpha1 "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
@@ -25,6 +26,7 @@ import (
 	release "github.com/konflux-ci/release-service/api/v1alpha1"
 	tekton "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	pipelineclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"golang.org/x/oauth2"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -32,6 +34,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
@@ -48,6 +51,7 @@ type CustomClient struct {
 	dynamicClient         dynamic.Interface
 	jvmbuildserviceClient jvmbuildserviceclientset.Interface
 	routeClient           routeclientset.Interface
+	ledger                *ledger.Ledger
 }
 
 type K8SClient struct {
@@ -58,6 +62,7 @@ type K8SClient struct {
 	UserName          string
 	UserNamespace     string
 	UserToken         string
+	TokenSource       *sandbox.KeycloakTokenSource
 }
 
 var (
@@ -102,6 +107,25 @@ func (c *CustomClient) RouteClient() routeclientset.Interface {
 	return c.routeClient
 }
 
+// Ledger returns the ledger where controllers track created resources for cleanup, nil when tracking is disabled
+func (c *CustomClient) Ledger() *ledger.Ledger {
+	return c.ledger
+}
+
+// SetLedger enables tracking of resources created through the client
+func (c *CustomClient) SetLedger(l *ledger.Ledger) {
+	c.ledger = l
+}
+
+// CreateAndTrack creates the object and tracks it in the ledger for deletion once the spec or container ends
+func (c *CustomClient) CreateAndTrack(ctx context.Context, obj crclient.Object, opts ...crclient.CreateOption) error {
+	if err := c.crClient.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.ledger.TrackObject(c.crClient, obj)
+	return nil
+}
+
 // Returns a DynamicClient interface.
 // Note: other client interfaces are likely preferred, except in rare cases.
 func (c *CustomClient) DynamicClient() dynamic.Interface {
@@ -145,7 +169,11 @@ func NewDevSandboxProxyClient(userName string, isStage bool, options utils.Optio
 		}
 	}
 
-	sandboxProxyClient, err = CreateAPIProxyClient(proxyAuthInfo.UserToken, proxyAuthInfo.ProxyUrl)
+	if proxyAuthInfo.TokenSource != nil {
+		sandboxProxyClient, err = CreateAPIProxyClientWithTokenSource(proxyAuthInfo.TokenSource, proxyAuthInfo.ProxyUrl)
+	} else {
+		sandboxProxyClient, err = CreateAPIProxyClient(proxyAuthInfo.UserToken, proxyAuthInfo.ProxyUrl)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +186,47 @@ func NewDevSandboxProxyClient(userName string, isStage bool, options utils.Optio
 		UserName:          proxyAuthInfo.UserName,
 		UserNamespace:     proxyAuthInfo.UserNamespace,
 		UserToken:         proxyAuthInfo.UserToken,
+		TokenSource:       proxyAuthInfo.TokenSource,
+	}, nil
+}
+
+// NewTenantClient provisions a tenant namespace for the user directly on the cluster from default kubeconfig and returns
+// a client using a ServiceAccount token as developer identity. Intended for clusters without the sandbox stack.
+func NewTenantClient(userName string) (*K8SClient, error) {
+	adminKubeconfig, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	asAdminClient, err := NewAdminKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	sandboxController, err := sandbox.NewDevSandboxController(asAdminClient.KubeInterface(), asAdminClient.KubeRest())
+	if err != nil {
+		return nil, err
+	}
+	sandboxController.TenantMode = true
+
+	tenantAuthInfo, err := sandboxController.ReconcileTenantCreation(userName, adminKubeconfig.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantClient, err := CreateAPIProxyClient(tenantAuthInfo.UserToken, tenantAuthInfo.ProxyUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &K8SClient{
+		AsKubeAdmin:       asAdminClient,
+		AsKubeDeveloper:   tenantClient,
+		ProxyUrl:          tenantAuthInfo.ProxyUrl,
+		SandboxController: sandboxController,
+		UserName:          tenantAuthInfo.UserName,
+		UserNamespace:     tenantAuthInfo.UserNamespace,
+		UserToken:         tenantAuthInfo.UserToken,
 	}, nil
 }
 
@@ -193,14 +262,31 @@ func NewAdminKubernetesClient() (*CustomClient, error) {
 
 // CreateAPIProxyClient creates a client to the RHTAP api proxy using the given user token
 func CreateAPIProxyClient(usertoken, proxyURL string) (*CustomClient, error) {
-	var proxyCl crclient.Client
-	var initProxyClError error
-
-	proxyKubeConfig := &rest.Config{
+	return createAPIProxyClient(&rest.Config{
 		Host:        proxyURL,
 		BearerToken: usertoken,
 		Transport:   noTimeoutDefaultTransport(),
+	})
+}
+
+// CreateAPIProxyClientWithTokenSource creates a client to the RHTAP api proxy which takes the user token from the token source
+// on every request, so the token can be refreshed without recreating the client
+func CreateAPIProxyClientWithTokenSource(tokenSource oauth2.TokenSource, proxyURL string) (*CustomClient, error) {
+	wrapTransport := transport.TokenSourceWrapTransport(tokenSource)
+	if resettable, ok := tokenSource.(transport.ResettableTokenSource); ok {
+		// rejected token is fetched again on next request
+		wrapTransport = transport.ResettableTokenSourceWrapTransport(resettable)
 	}
+	return createAPIProxyClient(&rest.Config{
+		Host:          proxyURL,
+		Transport:     noTimeoutDefaultTransport(),
+		WrapTransport: wrapTransport,
+	})
+}
+
+func createAPIProxyClient(proxyKubeConfig *rest.Config) (*CustomClient, error) {
+	var proxyCl crclient.Client
+	var initProxyClError error
 
 	// Getting the proxy client can fail from time to time if the proxy's informer cache has not been
 	// updated yet and we try to create the client to quickly so retry to reduce flakiness.
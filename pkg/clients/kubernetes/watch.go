@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	PipelineRunGVR = schema.GroupVersionResource{Group: "tekton.dev", Version: "v1", Resource: "pipelineruns"}
+	TaskRunGVR     = schema.GroupVersionResource{Group: "tekton.dev", Version: "v1", Resource: "taskruns"}
+	ComponentGVR   = schema.GroupVersionResource{Group: "appstudio.redhat.com", Version: "v1alpha1", Resource: "components"}
+	SnapshotGVR    = schema.GroupVersionResource{Group: "appstudio.redhat.com", Version: "v1alpha1", Resource: "snapshots"}
+	ReleaseGVR     = schema.GroupVersionResource{Group: "appstudio.redhat.com", Version: "v1alpha1", Resource: "releases"}
+)
+
+// WatchOptions selects the objects of a resource to wait for.
+type WatchOptions struct {
+	Namespace string
+	// Name of the object, empty means all the objects matching the labels
+	Name   string
+	Labels map[string]string
+	// Timeout of the wait, a zero value means no timeout
+	Timeout time.Duration
+}
+
+func (o WatchOptions) String() string {
+	selector := o.Namespace + "/" + o.Name
+	if len(o.Labels) > 0 {
+		selector = fmt.Sprintf("%s (labels: %s)", selector, labels.SelectorFromSet(o.Labels))
+	}
+	return selector
+}
+
+// matches filters the objects on the client side as well, in case the API server (proxy) ignores the selectors.
+func (o WatchOptions) matches(obj *unstructured.Unstructured) bool {
+	return (o.Name == "" || obj.GetName() == o.Name) && labels.SelectorFromSet(o.Labels).Matches(labels.Set(obj.GetLabels()))
+}
+
+func (o WatchOptions) context() (context.Context, context.CancelFunc) {
+	if o.Timeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), o.Timeout)
+}
+
+// informerKey identifies an informer shared by the waits for objects of a resource in a namespace
+type informerKey struct {
+	client    dynamic.Interface
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+type sharedInformer struct {
+	informer cache.SharedIndexInformer
+	stop     chan struct{}
+	waits    int
+}
+
+// Waits running at the same time (e.g. for PipelineRuns of several components in one namespace) share a single
+// list and watch of the resource instead of opening one per wait, the informer is stopped once the last wait ends.
+var (
+	informersLock sync.Mutex
+	informers     = map[informerKey]*sharedInformer{}
+)
+
+// acquireInformer returns a running informer of the resource in the namespace, release has to be called once the wait ends
+func acquireInformer(client dynamic.Interface, gvr schema.GroupVersionResource, namespace string) (informer cache.SharedIndexInformer, release func()) {
+	informersLock.Lock()
+	defer informersLock.Unlock()
+
+	key := informerKey{client: client, gvr: gvr, namespace: namespace}
+	shared, ok := informers[key]
+	if !ok {
+		shared = &sharedInformer{
+			informer: dynamicinformer.NewFilteredDynamicInformer(client, gvr, namespace, 0, cache.Indexers{}, nil).Informer(),
+			stop:     make(chan struct{}),
+		}
+		informers[key] = shared
+		go shared.informer.Run(shared.stop)
+	}
+	shared.waits++
+
+	return shared.informer, func() {
+		informersLock.Lock()
+		defer informersLock.Unlock()
+		shared.waits--
+		if shared.waits == 0 {
+			close(shared.stop)
+			delete(informers, key)
+		}
+	}
+}
+
+// notify returns a channel receiving the objects selected by the options whenever they are added (including the objects
+// existing before the wait) or updated, and a channel signalled whenever an object is deleted
+func notify(ctx context.Context, informer cache.SharedIndexInformer, opts WatchOptions) (changed chan *unstructured.Unstructured, deleted chan struct{}, remove func(), err error) {
+	changed = make(chan *unstructured.Unstructured)
+	deleted = make(chan struct{}, 1)
+	send := func(obj interface{}) {
+		if u, ok := obj.(*unstructured.Unstructured); ok && opts.matches(u) {
+			select {
+			case changed <- u:
+			case <-ctx.Done():
+			}
+		}
+	}
+	registration, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    send,
+		UpdateFunc: func(_, obj interface{}) { send(obj) },
+		DeleteFunc: func(interface{}) {
+			select {
+			case deleted <- struct{}{}:
+			default:
+			}
+		},
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return changed, deleted, func() { _ = informer.RemoveEventHandler(registration) }, nil
+}
+
+// WaitForCondition waits until the condition is met for one of the objects selected by the options and returns that object.
+// Instead of polling the API server the objects are watched by an informer shared with the other waits for the same
+// resource in the namespace, the objects existing before the wait are evaluated as well. Whenever the watch gets
+// disconnected the objects are listed again, so no change is missed. The wait stops as soon as the condition returns an error.
+func WaitForCondition[T any](client dynamic.Interface, gvr schema.GroupVersionResource, opts WatchOptions, condition func(obj *T) (bool, error)) (*T, error) {
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	informer, release := acquireInformer(client, gvr, opts.Namespace)
+	defer release()
+	changed, _, remove, err := notify(ctx, informer, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch %s %s: %+v", gvr.Resource, opts, err)
+	}
+	defer remove()
+	// unblocks the handler waiting to deliver an object before it is removed
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for %s %s: %+v", gvr.Resource, opts, ctx.Err())
+		case u := <-changed:
+			obj := new(T)
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+				return obj, fmt.Errorf("failed to convert %s %s/%s: %+v", gvr.Resource, u.GetNamespace(), u.GetName(), err)
+			}
+			done, err := condition(obj)
+			if done || err != nil {
+				return obj, err
+			}
+		}
+	}
+}
+
+// WaitForDeletion waits until none of the objects selected by the options exists.
+func WaitForDeletion(client dynamic.Interface, gvr schema.GroupVersionResource, opts WatchOptions) error {
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	informer, release := acquireInformer(client, gvr, opts.Namespace)
+	defer release()
+	changed, deleted, remove, err := notify(ctx, informer, opts)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s %s: %+v", gvr.Resource, opts, err)
+	}
+	defer remove()
+	// unblocks the handler waiting to deliver an object before it is removed
+	defer cancel()
+
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("timed out waiting for the deletion of %s %s: %+v", gvr.Resource, opts, ctx.Err())
+	}
+	for remaining(informer.GetStore(), opts) != 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the deletion of %s %s: %+v", gvr.Resource, opts, ctx.Err())
+		case <-changed:
+		case <-deleted:
+		}
+	}
+	return nil
+}
+
+// remaining returns the number of objects in the store selected by the options.
+func remaining(store cache.Store, opts WatchOptions) int {
+	count := 0
+	for _, obj := range store.List() {
+		if u, ok := obj.(*unstructured.Unstructured); ok && opts.matches(u) {
+			count++
+		}
+	}
+	return count
+}
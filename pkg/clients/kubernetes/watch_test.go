@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tekton "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newUnstructuredPipelineRun(name string, labels map[string]string) *unstructured.Unstructured {
+	pr := &unstructured.Unstructured{}
+	pr.SetAPIVersion("tekton.dev/v1")
+	pr.SetKind("PipelineRun")
+	pr.SetNamespace("user-tenant")
+	pr.SetName(name)
+	pr.SetLabels(labels)
+	return pr
+}
+
+func newFakeDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{PipelineRunGVR: "PipelineRunList"}, objects...)
+}
+
+func TestWaitForCondition(t *testing.T) {
+	client := newFakeDynamicClient(
+		newUnstructuredPipelineRun("other", map[string]string{"app": "other"}),
+		newUnstructuredPipelineRun("build", map[string]string{"app": "test"}),
+	)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		pr := newUnstructuredPipelineRun("build", map[string]string{"app": "test"})
+		assert.NoError(t, unstructured.SetNestedField(pr.Object, "2024-05-01T10:00:00Z", "status", "completionTime"))
+		_, err := client.Resource(PipelineRunGVR).Namespace("user-tenant").Update(context.Background(), pr, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+	}()
+
+	var evaluated []string
+	pr, err := WaitForCondition(client, PipelineRunGVR, WatchOptions{Namespace: "user-tenant", Labels: map[string]string{"app": "test"}, Timeout: time.Minute},
+		func(pr *tekton.PipelineRun) (bool, error) {
+			evaluated = append(evaluated, pr.Name)
+			return pr.Status.CompletionTime != nil, nil
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "build", pr.Name)
+	assert.Equal(t, []string{"build", "build"}, evaluated)
+}
+
+func TestWaitForConditionTimeout(t *testing.T) {
+	client := newFakeDynamicClient(newUnstructuredPipelineRun("build", nil))
+
+	_, err := WaitForCondition(client, PipelineRunGVR, WatchOptions{Namespace: "user-tenant", Name: "build", Timeout: 200 * time.Millisecond},
+		func(pr *tekton.PipelineRun) (bool, error) {
+			return false, nil
+		})
+
+	assert.ErrorContains(t, err, "timed out waiting for pipelineruns user-tenant/build")
+}
+
+func TestWaitForDeletion(t *testing.T) {
+	client := newFakeDynamicClient(newUnstructuredPipelineRun("build", nil), newUnstructuredPipelineRun("other", nil))
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, client.Resource(PipelineRunGVR).Namespace("user-tenant").Delete(context.Background(), "build", metav1.DeleteOptions{}))
+	}()
+
+	assert.NoError(t, WaitForDeletion(client, PipelineRunGVR, WatchOptions{Namespace: "user-tenant", Name: "build", Timeout: time.Minute}))
+	assert.NoError(t, WaitForDeletion(client, PipelineRunGVR, WatchOptions{Namespace: "user-tenant", Name: "missing", Timeout: time.Minute}))
+}
+
+func TestWaitsShareInformer(t *testing.T) {
+	client := newFakeDynamicClient(newUnstructuredPipelineRun("first", nil), newUnstructuredPipelineRun("second", nil))
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"first", "second"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			_, err := WaitForCondition(client, PipelineRunGVR, WatchOptions{Namespace: "user-tenant", Name: name, Timeout: time.Minute},
+				func(pr *tekton.PipelineRun) (bool, error) {
+					return pr.Status.CompletionTime != nil, nil
+				})
+			assert.NoError(t, err)
+		}(name)
+	}
+
+	assert.Eventually(t, func() bool {
+		informersLock.Lock()
+		defer informersLock.Unlock()
+		return len(informers) == 1 && informers[informerKey{client: client, gvr: PipelineRunGVR, namespace: "user-tenant"}].waits == 2
+	}, 5*time.Second, 10*time.Millisecond, "both waits use the same informer")
+
+	for _, name := range []string{"first", "second"} {
+		pr := newUnstructuredPipelineRun(name, nil)
+		assert.NoError(t, unstructured.SetNestedField(pr.Object, "2024-05-01T10:00:00Z", "status", "completionTime"))
+		_, err := client.Resource(PipelineRunGVR).Namespace("user-tenant").Update(context.Background(), pr, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+	}
+	wg.Wait()
+
+	informersLock.Lock()
+	defer informersLock.Unlock()
+	assert.Empty(t, informers, "informer is stopped once the last wait ends")
+}
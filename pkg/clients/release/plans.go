@@ -40,7 +40,7 @@ func (r *ReleaseController) CreateReleasePlan(name, namespace, application, targ
 		releasePlan.ObjectMeta.Labels[releaseMetadata.AutoReleaseLabel] = "false"
 	}
 
-	return releasePlan, r.KubeRest().Create(context.Background(), releasePlan)
+	return releasePlan, r.CreateAndTrack(context.Background(), releasePlan)
 }
 
 // CreateReleasePlanAdmission creates a new ReleasePlanAdmission using the given parameters.
@@ -66,7 +66,7 @@ func (r *ReleaseController) CreateReleasePlanAdmission(name, namespace, environm
 		},
 	}
 
-	return releasePlanAdmission, r.KubeRest().Create(context.Background(), releasePlanAdmission)
+	return releasePlanAdmission, r.CreateAndTrack(context.Background(), releasePlanAdmission)
 }
 
 // GetReleasePlan returns the ReleasePlan with the given name in the given namespace.
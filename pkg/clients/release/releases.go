@@ -6,7 +6,7 @@ import (
 	"strings"
 	"time"
 
-	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
 	"github.com/konflux-ci/e2e-tests/pkg/logs"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
 	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
@@ -35,7 +35,7 @@ func (r *ReleaseController) CreateRelease(name, namespace, snapshot, releasePlan
 		},
 	}
 
-	return release, r.KubeRest().Create(context.Background(), release)
+	return release, r.CreateAndTrack(context.Background(), release)
 }
 
 // CreateReleasePipelineRoleBindingForServiceAccount creates a RoleBinding for the passed serviceAccount to enable
@@ -59,7 +59,7 @@ func (r *ReleaseController) CreateReleasePipelineRoleBindingForServiceAccount(na
 			},
 		},
 	}
-	err := r.KubeRest().Create(context.Background(), roleBinding)
+	err := r.CreateAndTrack(context.Background(), roleBinding)
 	if err != nil {
 		return nil, err
 	}
@@ -194,26 +194,29 @@ func (r *ReleaseController) WaitForReleasePipelineToGetStarted(release *releaseA
 // WaitForReleasePipelineToBeFinished wait for given release pipeline to finish.
 // It exposes the error message from the failed task to the end user when the pipelineRun failed.
 func (r *ReleaseController) WaitForReleasePipelineToBeFinished(release *releaseApi.Release, managedNamespace string) error {
-	return wait.PollUntilContextTimeout(context.Background(), constants.PipelineRunPollingInterval, 30*time.Minute, true, func(ctx context.Context) (done bool, err error) {
-		pipelineRun, err := r.GetPipelineRunInNamespace(managedNamespace, release.GetName(), release.GetNamespace())
-		if err != nil {
-			GinkgoWriter.Println("PipelineRun has not been created yet for release %s/%s", release.GetNamespace(), release.GetName())
-			return false, nil
-		}
+	opts := kubeCl.WatchOptions{
+		Namespace: managedNamespace,
+		Labels: map[string]string{
+			"release.appstudio.openshift.io/name":      release.GetName(),
+			"release.appstudio.openshift.io/namespace": release.GetNamespace(),
+		},
+		Timeout: 30 * time.Minute,
+	}
+	GinkgoWriter.Printf("Waiting for PipelineRun of release %s/%s to finish in %s namespace\n", release.GetNamespace(), release.GetName(), managedNamespace)
+	_, err := kubeCl.WaitForCondition(r.DynamicClient(), kubeCl.PipelineRunGVR, opts, func(pipelineRun *pipeline.PipelineRun) (bool, error) {
 		for _, condition := range pipelineRun.Status.Conditions {
 			GinkgoWriter.Printf("PipelineRun %s reason: %s\n", pipelineRun.Name, condition.Reason)
+		}
 
-			if !pipelineRun.IsDone() {
-				return false, nil
-			}
+		if !pipelineRun.IsDone() {
+			return false, nil
+		}
 
-			if pipelineRun.GetStatusCondition().GetCondition(apis.ConditionSucceeded).IsTrue() {
-				return true, nil
-			} else {
-				logs, _ := tekton.GetFailedPipelineRunLogs(r.KubeRest(), r.KubeInterface(), pipelineRun)
-				return false, fmt.Errorf("%s", logs)
-			}
+		if pipelineRun.GetStatusCondition().GetCondition(apis.ConditionSucceeded).IsTrue() {
+			return true, nil
 		}
-		return false, nil
+		logs, _ := tekton.GetFailedPipelineRunLogs(r.KubeRest(), r.KubeInterface(), pipelineRun)
+		return false, fmt.Errorf("%s", logs)
 	})
+	return err
 }
@@ -2,16 +2,20 @@ package tekton
 
 import (
 	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
+	results "github.com/konflux-ci/e2e-tests/pkg/utils/pipeline"
 )
 
 // Create the struct for kubernetes clients
 type TektonController struct {
 	*kubeCl.CustomClient
+
+	// ResultClient is used to get the logs of PipelineRuns and TaskRuns whose pods were pruned, it is optional
+	ResultClient *results.ResultClient
 }
 
 // Create controller for Tekton Task/Pipeline CRUD operations
 func NewSuiteController(kube *kubeCl.CustomClient) *TektonController {
 	return &TektonController{
-		kube,
+		CustomClient: kube,
 	}
 }
@@ -18,7 +18,7 @@ func (t *TektonController) CreateEnterpriseContractPolicy(name, namespace string
 		},
 		Spec: ecpolicy,
 	}
-	return ec, t.KubeRest().Create(context.Background(), ec)
+	return ec, t.CreateAndTrack(context.Background(), ec)
 }
 
 // CreateOrUpdatePolicyConfiguration creates new policy if it doesn't exist, otherwise updates the existing one, in a specified namespace.
@@ -31,6 +31,7 @@ func (t *TektonController) CreatePVCInAccessMode(name, namespace string, accessM
 	if err != nil {
 		return nil, err
 	}
+	t.Ledger().TrackObject(t.KubeRest(), createdPVC)
 	return createdPVC, err
 }
 
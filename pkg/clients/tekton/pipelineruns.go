@@ -3,11 +3,12 @@ package tekton
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
 	"github.com/konflux-ci/e2e-tests/pkg/logs"
-	"github.com/konflux-ci/e2e-tests/pkg/utils"
 
 	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
@@ -16,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
+	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -26,7 +28,12 @@ import (
 
 // CreatePipelineRun creates a tekton pipelineRun and returns the pipelineRun or error
 func (t *TektonController) CreatePipelineRun(pipelineRun *pipeline.PipelineRun, ns string) (*pipeline.PipelineRun, error) {
-	return t.PipelineClient().TektonV1().PipelineRuns(ns).Create(context.Background(), pipelineRun, metav1.CreateOptions{})
+	created, err := t.PipelineClient().TektonV1().PipelineRuns(ns).Create(context.Background(), pipelineRun, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	t.Ledger().TrackObject(t.KubeRest(), created)
+	return created, nil
 }
 
 // createAndWait creates a pipelineRun and waits until it starts.
@@ -36,7 +43,10 @@ func (t *TektonController) createAndWait(pr *pipeline.PipelineRun, namespace str
 		return nil, err
 	}
 	g.GinkgoWriter.Printf("Creating Pipeline %q\n", pipelineRun.Name)
-	return pipelineRun, utils.WaitUntil(t.CheckPipelineRunStarted(pipelineRun.Name, namespace), time.Duration(taskTimeout)*time.Second)
+	_, err = t.WaitForPipelineRun(pipelineRun.Name, namespace, time.Duration(taskTimeout)*time.Second, func(pr *pipeline.PipelineRun) (bool, error) {
+		return pr.Status.StartTime != nil, nil
+	})
+	return pipelineRun, err
 }
 
 // RunPipeline creates a pipelineRun and waits for it to start.
@@ -71,6 +81,8 @@ func (t *TektonController) GetPipelineRun(pipelineRunName, namespace string) (*p
 }
 
 // GetPipelineRunLogs returns logs of a given pipelineRun.
+// When the pods of the pipelineRun are gone, the logs are taken from Tekton Results if the ResultClient is configured
+// and available, otherwise no logs are returned.
 func (t *TektonController) GetPipelineRunLogs(prefix, pipelineRunName, namespace string) (string, error) {
 	podClient := t.KubeInterface().CoreV1().Pods(namespace)
 	podList, err := podClient.List(context.Background(), metav1.ListOptions{})
@@ -78,10 +90,12 @@ func (t *TektonController) GetPipelineRunLogs(prefix, pipelineRunName, namespace
 		return "", err
 	}
 	podLog := ""
+	podFound := false
 	for _, pod := range podList.Items {
 		if !strings.HasPrefix(pod.Name, prefix) {
 			continue
 		}
+		podFound = true
 		for _, c := range pod.Spec.InitContainers {
 			var err error
 			var cLog string
@@ -101,24 +115,73 @@ func (t *TektonController) GetPipelineRunLogs(prefix, pipelineRunName, namespace
 			}
 		}
 	}
+
+	if !podFound && t.ResultClient != nil {
+		// best effort, callers only logging the output shouldn't fail when Tekton Results is unavailable
+		resultsLog, err := t.getPipelineRunLogsFromResults(pipelineRunName, namespace)
+		if err == nil {
+			return resultsLog, nil
+		}
+		g.GinkgoWriter.Printf("failed to get logs of PipelineRun %s/%s from tekton results: %+v\n", namespace, pipelineRunName, err)
+		if resultsLog != "" {
+			// partial logs are still useful, the error is attached for the missing ones
+			return resultsLog + fmt.Sprintf("\nfailed to get the remaining logs from tekton results: %+v\n", err), nil
+		}
+	}
 	return podLog, nil
 }
 
+// getPipelineRunLogsFromResults returns logs of all the taskRuns of a given pipelineRun stored by Tekton Results.
+func (t *TektonController) getPipelineRunLogsFromResults(pipelineRunName, namespace string) (string, error) {
+	pr, err := t.GetPipelineRun(pipelineRunName, namespace)
+	if errors.IsNotFound(err) {
+		// the pipelineRun was pruned from the cluster as well
+		pr, err = t.ResultClient.FindPipelineRun(context.Background(), namespace, pipelineRunName)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get PipelineRun %s/%s in order to get its logs from tekton results: %+v", namespace, pipelineRunName, err)
+	}
+
+	taskRunLogs, err := t.getTaskRunLogsFromResults(pr.GetUID(), namespace)
+	taskRunNames := make([]string, 0, len(taskRunLogs))
+	for name := range taskRunLogs {
+		taskRunNames = append(taskRunNames, name)
+	}
+	sort.Strings(taskRunNames)
+
+	podLog := ""
+	for _, name := range taskRunNames {
+		podLog = podLog + fmt.Sprintf("\ntaskRun: %s | tekton results: \n", name) + taskRunLogs[name]
+	}
+	return podLog, err
+}
+
 // GetPipelineRunWatch returns pipelineRun watch interface.
 func (t *TektonController) GetPipelineRunWatch(ctx context.Context, namespace string) (watch.Interface, error) {
 	return t.PipelineClient().TektonV1().PipelineRuns(namespace).Watch(ctx, metav1.ListOptions{})
 }
 
+// WaitForPipelineRun watches the pipelineRun until the condition is met for it or the timeout expires.
+func (t *TektonController) WaitForPipelineRun(pipelineRunName, namespace string, timeout time.Duration, condition func(pr *pipeline.PipelineRun) (bool, error)) (*pipeline.PipelineRun, error) {
+	return kubeCl.WaitForCondition(t.DynamicClient(), kubeCl.PipelineRunGVR, kubeCl.WatchOptions{Namespace: namespace, Name: pipelineRunName, Timeout: timeout}, condition)
+}
+
 // WatchPipelineRun waits until pipelineRun finishes.
 func (t *TektonController) WatchPipelineRun(pipelineRunName, namespace string, taskTimeout int) error {
 	g.GinkgoWriter.Printf("Waiting for pipeline %q to finish\n", pipelineRunName)
-	return utils.WaitUntil(t.CheckPipelineRunFinished(pipelineRunName, namespace), time.Duration(taskTimeout)*time.Second)
+	_, err := t.WaitForPipelineRun(pipelineRunName, namespace, time.Duration(taskTimeout)*time.Second, func(pr *pipeline.PipelineRun) (bool, error) {
+		return pr.Status.CompletionTime != nil, nil
+	})
+	return err
 }
 
 // WatchPipelineRunSucceeded waits until the pipelineRun succeeds.
 func (t *TektonController) WatchPipelineRunSucceeded(pipelineRunName, namespace string, taskTimeout int) error {
 	g.GinkgoWriter.Printf("Waiting for pipeline %q to finish\n", pipelineRunName)
-	return utils.WaitUntil(t.CheckPipelineRunSucceeded(pipelineRunName, namespace), time.Duration(taskTimeout)*time.Second)
+	_, err := t.WaitForPipelineRun(pipelineRunName, namespace, time.Duration(taskTimeout)*time.Second, func(pr *pipeline.PipelineRun) (bool, error) {
+		return pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).IsTrue(), nil
+	})
+	return err
 }
 
 // CheckPipelineRunStarted checks if pipelineRUn started.
@@ -9,7 +9,12 @@ import (
 
 // CreatePipeline creates a tekton pipeline and returns the pipeline or an error
 func (t *TektonController) CreatePipeline(pipeline *pipeline.Pipeline, ns string) (*pipeline.Pipeline, error) {
-	return t.PipelineClient().TektonV1().Pipelines(ns).Create(context.Background(), pipeline, metav1.CreateOptions{})
+	created, err := t.PipelineClient().TektonV1().Pipelines(ns).Create(context.Background(), pipeline, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	t.Ledger().TrackObject(t.KubeRest(), created)
+	return created, nil
 }
 
 // DeletePipeline removes the pipeline from given namespace.
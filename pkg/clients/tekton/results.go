@@ -0,0 +1,94 @@
+package tekton
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// stepLogPrefix matches the prefix of the lines of TaskRun logs stored by Tekton Results, i.e. "[build] " or "[buildah : build] "
+var stepLogPrefix = regexp.MustCompile(`^\[(?:[^\]]+ : )?([^\]]+)\] ?`)
+
+// getTaskRunLogsFromResults returns the logs of the TaskRuns of a PipelineRun stored by Tekton Results, mapped by the TaskRun name.
+// Only the TaskRuns with the given names are returned, all of them when no name is given.
+func (t *TektonController) getTaskRunLogsFromResults(pipelineRunUID types.UID, namespace string, taskRunNames ...string) (map[string]string, error) {
+	if t.ResultClient == nil {
+		return nil, fmt.Errorf("tekton results client is not configured")
+	}
+
+	records, err := t.ResultClient.GetAllLogs(namespace, string(pipelineRunUID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log records of PipelineRun with UID %s in %s namespace: %+v", pipelineRunUID, namespace, err)
+	}
+
+	// logs of the other records are still returned when some of them fail
+	logs := make(map[string]string)
+	var errs []error
+	for _, record := range records {
+		resource, err := record.LogResource()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(taskRunNames) > 0 && !contains(taskRunNames, resource.Name) {
+			continue
+		}
+		content, err := t.ResultClient.GetLogContent(record.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get log %s from tekton results: %+v", record.Name, err))
+			continue
+		}
+		logs[resource.Name] = content
+	}
+
+	return logs, errors.Join(errs...)
+}
+
+// splitStepLogs splits the log of a TaskRun stored by Tekton Results by steps, mapped by the container name of the step.
+// Logs without step prefixes are returned as the log of the whole TaskRun.
+func splitStepLogs(taskRunName, log string) map[string]string {
+	steps := make(map[string]*strings.Builder)
+	for _, line := range strings.Split(strings.TrimSuffix(log, "\n"), "\n") {
+		match := stepLogPrefix.FindStringSubmatch(line)
+		if match == nil {
+			return map[string]string{taskRunName: log}
+		}
+		container := "step-" + strings.TrimPrefix(match[1], "step-")
+		if _, ok := steps[container]; !ok {
+			steps[container] = &strings.Builder{}
+		}
+		steps[container].WriteString(line[len(match[0]):] + "\n")
+	}
+
+	logs := make(map[string]string)
+	for container, sb := range steps {
+		logs[container] = sb.String()
+	}
+	return logs
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// getTaskRunStepLogsFromResults returns the logs of a taskRun stored by Tekton Results, mapped by the container name of the step.
+func (t *TektonController) getTaskRunStepLogsFromResults(pipelineRunUID types.UID, taskRunName, namespace string) (map[string]string, error) {
+	logs, err := t.getTaskRunLogsFromResults(pipelineRunUID, namespace, taskRunName)
+	if err != nil {
+		return nil, err
+	}
+	log, ok := logs[taskRunName]
+	if !ok {
+		return nil, fmt.Errorf("no log of taskRun %s/%s found in tekton results", namespace, taskRunName)
+	}
+
+	return splitStepLogs(taskRunName, log), nil
+}
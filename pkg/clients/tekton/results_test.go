@@ -0,0 +1,71 @@
+package tekton
+
+import (
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/pkg/utils/pipeline"
+	"github.com/stretchr/testify/assert"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSplitStepLogs(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"step-init":  "first\nsecond\n",
+		"step-build": "building\n",
+	}, splitStepLogs("build-pr-init", "[init] first\n[build : step-build] building\n[init] second\n"))
+
+	assert.Equal(t, map[string]string{"build-pr-init": "no step prefixes\n"}, splitStepLogs("build-pr-init", "no step prefixes\n"))
+}
+
+func TestGetTaskRunStepLogsFromResults(t *testing.T) {
+	server := pipeline.NewFakeResultsServer()
+	defer server.Close()
+	_, err := server.AddLog("pr-uid", pipeline.LogResource{Kind: "TaskRun", Name: "build-pr-init", Namespace: "user-tenant", UID: "init-uid"}, "[init] first\n[init] second\n")
+	assert.NoError(t, err)
+	_, err = server.AddLog("pr-uid", pipeline.LogResource{Kind: "TaskRun", Name: "build-pr-build", Namespace: "user-tenant", UID: "build-uid"}, "[build] building\n")
+	assert.NoError(t, err)
+
+	tc := &TektonController{ResultClient: server.ResultClient()}
+	logs, err := tc.getTaskRunStepLogsFromResults("pr-uid", "build-pr-init", "user-tenant")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"step-init": "first\nsecond\n"}, logs)
+
+	_, err = tc.getTaskRunStepLogsFromResults("pr-uid", "build-pr-missing", "user-tenant")
+	assert.Error(t, err)
+}
+
+func TestGetPrunedTaskRunLogs(t *testing.T) {
+	server := pipeline.NewFakeResultsServer()
+	defer server.Close()
+	pr := &pipelinev1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "build-pr", Namespace: "user-tenant", UID: "pr-uid"}}
+	pr.Status.ChildReferences = []pipelinev1.ChildStatusReference{{Name: "build-pr-init", PipelineTaskName: "init"}}
+	_, err := server.AddPipelineRun(pr)
+	assert.NoError(t, err)
+	_, err = server.AddLog("pr-uid", pipeline.LogResource{Kind: "TaskRun", Name: "build-pr-init", Namespace: "user-tenant", UID: "init-uid"}, "[init] first\n")
+	assert.NoError(t, err)
+
+	tc := &TektonController{ResultClient: server.ResultClient()}
+	logs, err := tc.getPrunedTaskRunLogs("build-pr", "init", "user-tenant")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"step-init": "first\n"}, logs)
+
+	_, err = tc.getPrunedTaskRunLogs("build-pr", "missing", "user-tenant")
+	assert.ErrorContains(t, err, "task with missing name doesn't exist")
+	_, err = tc.getPrunedTaskRunLogs("other-pr", "init", "user-tenant")
+	assert.Error(t, err)
+}
+
+func TestGetTaskRunLogsFromResultsPartially(t *testing.T) {
+	server := pipeline.NewFakeResultsServer()
+	defer server.Close()
+	_, err := server.AddLog("pr-uid", pipeline.LogResource{Kind: "TaskRun", Name: "build-pr-init", Namespace: "user-tenant", UID: "init-uid"}, "[init] first\n")
+	assert.NoError(t, err)
+	_, err = server.AddRecord("user-tenant", "pr-uid", "broken-log", pipeline.LogRecordType, "not a log record")
+	assert.NoError(t, err)
+
+	tc := &TektonController{ResultClient: server.ResultClient()}
+	logs, err := tc.getTaskRunLogsFromResults("pr-uid", "user-tenant")
+	assert.ErrorContains(t, err, "failed to decode log record")
+	assert.Equal(t, map[string]string{"build-pr-init": "[init] first\n"}, logs, "logs of the other records are kept")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	g "github.com/onsi/ginkgo/v2"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/yaml"
@@ -66,7 +67,7 @@ func (t *TektonController) CreateTaskRunCopy(name, namespace, serviceAccountName
 		},
 	}
 
-	err := t.KubeRest().Create(context.Background(), &taskRun)
+	err := t.CreateAndTrack(context.Background(), &taskRun)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +94,11 @@ func (t *TektonController) GetTaskRun(name, namespace string) (*pipeline.TaskRun
 	return &taskRun, nil
 }
 
+// ListAllTaskRuns returns a list of all TaskRuns in a namespace.
+func (t *TektonController) ListAllTaskRuns(ns string) (*pipeline.TaskRunList, error) {
+	return t.PipelineClient().TektonV1().TaskRuns(ns).List(context.Background(), metav1.ListOptions{})
+}
+
 // StoreTaskRun stores a given TaskRun as an artifact.
 func (t *TektonController) StoreTaskRun(prefix string, taskRun *pipeline.TaskRun) error {
 	artifacts := make(map[string][]byte)
@@ -125,19 +131,28 @@ func (t *TektonController) StoreTaskRunsForPipelineRun(c crclient.Client, pr *pi
 }
 
 // GetTaskRunLogs returns logs of a specified taskRun.
+// When the pipelineRun, the taskRun or its pod is gone, the logs are taken from Tekton Results if the ResultClient is configured.
 func (t *TektonController) GetTaskRunLogs(pipelineRunName, pipelineTaskName, namespace string) (map[string]string, error) {
 	tektonClient := t.PipelineClient().TektonV1beta1().PipelineRuns(namespace)
 	pipelineRun, err := tektonClient.Get(context.Background(), pipelineRunName, metav1.GetOptions{})
 	if err != nil {
+		if errors.IsNotFound(err) && t.ResultClient != nil {
+			return t.getPrunedTaskRunLogs(pipelineRunName, pipelineTaskName, namespace)
+		}
 		return nil, err
 	}
 
+	taskRunName := ""
 	podName := ""
 	for _, childStatusReference := range pipelineRun.Status.ChildReferences {
 		if childStatusReference.PipelineTaskName == pipelineTaskName {
+			taskRunName = childStatusReference.Name
 			taskRun := &pipeline.TaskRun{}
 			taskRunKey := types.NamespacedName{Namespace: pipelineRun.Namespace, Name: childStatusReference.Name}
 			if err := t.KubeRest().Get(context.Background(), taskRunKey, taskRun); err != nil {
+				if errors.IsNotFound(err) && t.ResultClient != nil {
+					return t.getTaskRunStepLogsFromResults(pipelineRun.GetUID(), taskRunName, namespace)
+				}
 				return nil, err
 			}
 			podName = taskRun.Status.PodName
@@ -151,6 +166,9 @@ func (t *TektonController) GetTaskRunLogs(pipelineRunName, pipelineTaskName, nam
 	podClient := t.KubeInterface().CoreV1().Pods(namespace)
 	pod, err := podClient.Get(context.Background(), podName, metav1.GetOptions{})
 	if err != nil {
+		if errors.IsNotFound(err) && t.ResultClient != nil {
+			return t.getTaskRunStepLogsFromResults(pipelineRun.GetUID(), taskRunName, namespace)
+		}
 		return nil, err
 	}
 
@@ -166,6 +184,20 @@ func (t *TektonController) GetTaskRunLogs(pipelineRunName, pipelineTaskName, nam
 	return logs, nil
 }
 
+// getPrunedTaskRunLogs returns logs of a taskRun of a pipelineRun pruned from the cluster, both are looked up in Tekton Results.
+func (t *TektonController) getPrunedTaskRunLogs(pipelineRunName, pipelineTaskName, namespace string) (map[string]string, error) {
+	pipelineRun, err := t.ResultClient.FindPipelineRun(context.Background(), namespace, pipelineRunName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PipelineRun %s/%s from tekton results: %+v", namespace, pipelineRunName, err)
+	}
+	for _, childStatusReference := range pipelineRun.Status.ChildReferences {
+		if childStatusReference.PipelineTaskName == pipelineTaskName {
+			return t.getTaskRunStepLogsFromResults(pipelineRun.GetUID(), childStatusReference.Name, namespace)
+		}
+	}
+	return nil, fmt.Errorf("task with %s name doesn't exist in %s pipelinerun", pipelineTaskName, pipelineRunName)
+}
+
 func (t *TektonController) GetTaskRunFromPipelineRun(c crclient.Client, pr *pipeline.PipelineRun, pipelineTaskName string) (*pipeline.TaskRun, error) {
 	for _, chr := range pr.Status.ChildReferences {
 		if chr.PipelineTaskName != pipelineTaskName {
@@ -308,5 +340,10 @@ func (t *TektonController) RunTaskAndWait(trSpec *pipeline.TaskRun, namespace st
 }
 
 func (t *TektonController) CreateTaskRun(taskRun *pipeline.TaskRun, ns string) (*pipeline.TaskRun, error) {
-	return t.PipelineClient().TektonV1().TaskRuns(ns).Create(context.Background(), taskRun, metav1.CreateOptions{})
+	created, err := t.PipelineClient().TektonV1().TaskRuns(ns).Create(context.Background(), taskRun, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	t.Ledger().TrackObject(t.KubeRest(), created)
+	return created, nil
 }
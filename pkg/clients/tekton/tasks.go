@@ -12,7 +12,12 @@ import (
 
 // Create a tekton task and return the task or error.
 func (t *TektonController) CreateTask(task *pipeline.Task, ns string) (*pipeline.Task, error) {
-	return t.PipelineClient().TektonV1().Tasks(ns).Create(context.Background(), task, metav1.CreateOptions{})
+	created, err := t.PipelineClient().TektonV1().Tasks(ns).Create(context.Background(), task, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	t.Ledger().TrackObject(t.KubeRest(), created)
+	return created, nil
 }
 
 // CreateSkopeoCopyTask creates a skopeo copy task in the given namespace.
@@ -19,6 +19,12 @@ const (
 	// A quay organization where repositories for component images will be created.
 	DEFAULT_QUAY_ORG_ENV string = "DEFAULT_QUAY_ORG" // #nosec
 
+	// Token of DEFAULT_QUAY_ORG, used to check and clean up repositories and robot accounts created by image-controller
+	DEFAULT_QUAY_ORG_TOKEN_ENV string = "DEFAULT_QUAY_ORG_TOKEN" // #nosec
+
+	// Base64 encoded docker config used to push images to quay.io and added to the pipeline service account of the test users
+	QUAY_TOKEN_ENV string = "QUAY_TOKEN" // #nosec
+
 	// The quay.io token to perform container builds and push. The token must be correlated with the QUAY_OAUTH_USER environment
 	QUAY_OAUTH_TOKEN_ENV string = "QUAY_OAUTH_TOKEN" // #nosec
 
@@ -46,6 +52,9 @@ const (
 	// Name of the Secret Tekton Chains uses to read signing key
 	TEKTON_CHAINS_SIGNING_SECRETS_NAME = "signing-secrets"
 
+	// URL of the Tekton Results API used to get logs of pruned PipelineRuns, defaults to the tekton-results proxy plugin
+	TEKTON_RESULTS_URL_ENV string = "TEKTON_RESULTS_URL"
+
 	//Cluster Registration namespace
 	CLUSTER_REG_NS string = "cluster-reg-config" // #nosec
 
@@ -55,6 +64,24 @@ const (
 	// Skip checking "ApplicationServiceGHTokenSecrName" secret
 	SKIP_HAS_SECRET_CHECK_ENV string = "SKIP_HAS_SECRET_CHECK"
 
+	// Framework mode: "sandbox" (default) provisions users through the toolchain operators, "tenant" creates tenant namespaces directly
+	FRAMEWORK_MODE_ENV string = "FRAMEWORK_MODE"
+
+	// Application domain of the cluster, taken from the OpenShift console route when not defined
+	CLUSTER_APP_DOMAIN_ENV string = "CLUSTER_APP_DOMAIN"
+
+	// ClusterRole bound to the developer ServiceAccount in tenant framework mode
+	TENANT_CLUSTER_ROLE_ENV string = "TENANT_CLUSTER_ROLE"
+
+	// Path to YAML environment profile used by framework instead of the individual environment variables, see framework.EnvironmentProfile
+	ENVIRONMENT_PROFILE_ENV string = "E2E_ENVIRONMENT_PROFILE"
+
+	// Setting this env var to "true" makes tests skip cleanup of their resources
+	E2E_SKIP_CLEANUP_ENV string = "E2E_SKIP_CLEANUP"
+
+	// Setting this env var to "true" keeps resources tracked by the framework for cleanup when the spec fails, for debugging
+	KEEP_RESOURCES_ON_FAILURE_ENV string = "KEEP_RESOURCES_ON_FAILURE"
+
 	// Sandbox kubeconfig user path
 	USER_KUBE_CONFIG_PATH_ENV string = "USER_KUBE_CONFIG_PATH"
 	// Release e2e auth for build and release quay keys
@@ -192,6 +219,10 @@ const (
 	DefaultPipelineServiceAccountRoleBinding = "appstudio-pipelines-runner-rolebinding"
 	DefaultPipelineServiceAccountClusterRole = "appstudio-pipelines-runner"
 
+	FrameworkModeSandbox     = "sandbox"
+	FrameworkModeTenant      = "tenant"
+	DefaultTenantClusterRole = "konflux-admin-user-actions"
+
 	PaCPullRequestBranchPrefix = "appstudio-"
 
 	// Expiration for image tags
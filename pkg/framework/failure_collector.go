@@ -0,0 +1,320 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// IndexFileName is the name of the manifest describing the content of a failure bundle.
+const IndexFileName = "index.json"
+
+// ResourceKind is a namespaced resource whose objects are stored as YAML in the failure bundle.
+type ResourceKind struct {
+	// Kind is used to name the stored artifacts, i.e. PipelineRun
+	Kind string
+	GVR  schema.GroupVersionResource
+}
+
+var (
+	PipelineRunKind = ResourceKind{Kind: "PipelineRun", GVR: kubeCl.PipelineRunGVR}
+	TaskRunKind     = ResourceKind{Kind: "TaskRun", GVR: kubeCl.TaskRunGVR}
+	SnapshotKind    = ResourceKind{Kind: "Snapshot", GVR: kubeCl.SnapshotGVR}
+	ReleaseKind     = ResourceKind{Kind: "Release", GVR: kubeCl.ReleaseGVR}
+	ComponentKind   = ResourceKind{Kind: "Component", GVR: kubeCl.ComponentGVR}
+)
+
+// Artifact is a single file of the failure bundle.
+type Artifact struct {
+	Name      string
+	Kind      string
+	Namespace string
+	Data      []byte
+}
+
+// CollectorClients are the clients the artifact collectors use to query the cluster.
+type CollectorClients struct {
+	Kube    kubernetes.Interface
+	Dynamic dynamic.Interface
+}
+
+// ArtifactCollector gathers the artifacts of a single namespace. A zero filter means that
+// everything is collected, otherwise only what the filter matches should be returned.
+// A collector returns the artifacts it managed to gather even if it fails for some of them.
+type ArtifactCollector interface {
+	Name() string
+	Collect(clients CollectorClients, namespace string, filter LogFilter) ([]Artifact, error)
+}
+
+// FailureReport configures what ReportFailure collects for a failed spec.
+// Suites can pass their own FailureReport to register extra namespaces, resource kinds and collectors.
+type FailureReport struct {
+	// Namespaces maps a (human readable) component name to the namespace its controller runs in.
+	// Only the logs and events of the spec duration are collected from these namespaces,
+	// and only the log lines related to the user namespace.
+	Namespaces map[string]string
+	// ResourceKinds are stored as YAML, both from the user namespace and the controller namespaces
+	ResourceKinds []ResourceKind
+	Collectors    []ArtifactCollector
+}
+
+// DefaultFailureReport returns what is collected for every failed spec.
+func DefaultFailureReport() FailureReport {
+	return FailureReport{
+		Namespaces: map[string]string{
+			"Build Service":       "build-service",
+			"JVM Build Service":   "jvm-build-service",
+			"Application Service": "application-service",
+			"Image Controller":    "image-controller"},
+		ResourceKinds: []ResourceKind{PipelineRunKind, TaskRunKind, SnapshotKind, ReleaseKind, ComponentKind},
+		Collectors:    []ArtifactCollector{PodLogsCollector{}, EventsCollector{}},
+	}
+}
+
+// merge adds the namespaces, resource kinds and collectors of the other report that aren't present yet.
+func (r FailureReport) merge(other FailureReport) FailureReport {
+	merged := FailureReport{Namespaces: map[string]string{}}
+	for name, namespace := range r.Namespaces {
+		merged.Namespaces[name] = namespace
+	}
+	for name, namespace := range other.Namespaces {
+		merged.Namespaces[name] = namespace
+	}
+
+	kinds := map[schema.GroupVersionResource]bool{}
+	for _, kind := range append(append([]ResourceKind{}, r.ResourceKinds...), other.ResourceKinds...) {
+		if !kinds[kind.GVR] {
+			kinds[kind.GVR] = true
+			merged.ResourceKinds = append(merged.ResourceKinds, kind)
+		}
+	}
+
+	collectors := map[string]bool{}
+	for _, c := range append(append([]ArtifactCollector{}, r.Collectors...), other.Collectors...) {
+		if !collectors[c.Name()] {
+			collectors[c.Name()] = true
+			merged.Collectors = append(merged.Collectors, c)
+		}
+	}
+
+	return merged
+}
+
+// collectors returns the configured collectors followed by one ResourceCollector per resource kind.
+func (r FailureReport) collectors() []ArtifactCollector {
+	collectors := append([]ArtifactCollector{}, r.Collectors...)
+	for _, kind := range r.ResourceKinds {
+		collectors = append(collectors, ResourceCollector{ResourceKind: kind})
+	}
+	return collectors
+}
+
+// BundleIndex is the manifest of a failure bundle, stored as index.json next to the collected artifacts.
+type BundleIndex struct {
+	Spec       string        `json:"spec"`
+	StartTime  time.Time     `json:"startTime"`
+	EndTime    time.Time     `json:"endTime"`
+	Namespaces []string      `json:"namespaces"`
+	Artifacts  []IndexEntry  `json:"artifacts"`
+	Errors     []BundleError `json:"errors,omitempty"`
+}
+
+type IndexEntry struct {
+	File      string `json:"file"`
+	Collector string `json:"collector"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Size      int    `json:"size"`
+}
+
+// BundleError records a collector that failed (partially) for a namespace.
+type BundleError struct {
+	Collector string `json:"collector"`
+	Namespace string `json:"namespace"`
+	Error     string `json:"error"`
+}
+
+// CollectFailureBundle runs every collector of the report against the user namespace and the controller namespaces.
+// Failures of a collector are recorded in the returned index and don't stop the collection.
+// The returned artifacts include the index.json manifest.
+func CollectFailureBundle(clients CollectorClients, report FailureReport, userNamespace, spec string, start, end time.Time) (map[string][]byte, *BundleIndex) {
+	index := &BundleIndex{Spec: spec, StartTime: start, EndTime: end}
+	artifacts := map[string][]byte{}
+
+	// Everything from the user namespace is relevant, controller namespaces are shared across specs
+	targets := map[string]LogFilter{}
+	if userNamespace != "" {
+		targets[userNamespace] = LogFilter{}
+	}
+	for _, namespace := range report.Namespaces {
+		if _, ok := targets[namespace]; !ok {
+			targets[namespace] = LogFilter{Start: start, End: end, Namespace: userNamespace}
+		}
+	}
+	for namespace := range targets {
+		index.Namespaces = append(index.Namespaces, namespace)
+	}
+	sort.Strings(index.Namespaces)
+
+	for _, namespace := range index.Namespaces {
+		for _, collector := range report.collectors() {
+			collected, err := collector.Collect(clients, namespace, targets[namespace])
+			if err != nil {
+				index.Errors = append(index.Errors, BundleError{Collector: collector.Name(), Namespace: namespace, Error: err.Error()})
+			}
+			for _, a := range collected {
+				if _, exists := artifacts[a.Name]; exists {
+					index.Errors = append(index.Errors, BundleError{Collector: collector.Name(), Namespace: namespace, Error: fmt.Sprintf("duplicate artifact %s skipped", a.Name)})
+					continue
+				}
+				artifacts[a.Name] = a.Data
+				index.Artifacts = append(index.Artifacts, IndexEntry{File: a.Name, Collector: collector.Name(), Kind: a.Kind, Namespace: a.Namespace, Size: len(a.Data)})
+			}
+		}
+	}
+
+	indexJson, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		index.Errors = append(index.Errors, BundleError{Collector: "index", Error: fmt.Sprintf("failed to marshal %s: %+v", IndexFileName, err)})
+	} else {
+		artifacts[IndexFileName] = indexJson
+	}
+
+	return artifacts, index
+}
+
+// PodLogsCollector collects the logs of every container of every pod in the namespace.
+type PodLogsCollector struct{}
+
+func (PodLogsCollector) Name() string {
+	return "pod-logs"
+}
+
+func (PodLogsCollector) Collect(clients CollectorClients, namespace string, filter LogFilter) ([]Artifact, error) {
+	podList, err := clients.Kube.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %+v", namespace, err)
+	}
+
+	var artifacts []Artifact
+	var errs []string
+	for _, pod := range podList.Items {
+		var containers []corev1.Container
+		containers = append(containers, pod.Spec.InitContainers...)
+		containers = append(containers, pod.Spec.Containers...)
+		for _, c := range containers {
+			log, err := utils.GetContainerLogs(clients.Kube, pod.Name, c.Name, namespace)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("pod/container %s/%s: %v", pod.Name, c.Name, err))
+				continue
+			}
+			if !filter.IsZero() {
+				if log = filter.Filter(log); log == "" {
+					continue
+				}
+			}
+			name := fmt.Sprintf("pod-%s-%s-%s.log", namespace, pod.Name, c.Name)
+			artifacts = append(artifacts, Artifact{Name: name, Kind: "Pod", Namespace: namespace, Data: []byte(log)})
+		}
+	}
+
+	return artifacts, joinErrors("failed to get container logs", errs)
+}
+
+// EventsCollector collects the Kubernetes events of the namespace, sorted by time, into a single file.
+// Events are filtered by the time window of the filter only.
+type EventsCollector struct{}
+
+func (EventsCollector) Name() string {
+	return "events"
+}
+
+func (EventsCollector) Collect(clients CollectorClients, namespace string, filter LogFilter) ([]Artifact, error) {
+	eventList, err := clients.Kube.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events in namespace %s: %+v", namespace, err)
+	}
+
+	var events []corev1.Event
+	for _, e := range eventList.Items {
+		if t := eventTime(e); !t.Before(filter.Start) && (filter.End.IsZero() || !t.After(filter.End)) {
+			events = append(events, e)
+		}
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+
+	var sb strings.Builder
+	for _, e := range events {
+		sb.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s/%s\t%s\n", eventTime(e).UTC().Format(time.RFC3339), e.Type, e.Reason, e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Message))
+	}
+
+	return []Artifact{{Name: "events-" + namespace + ".log", Kind: "Event", Namespace: namespace, Data: []byte(sb.String())}}, nil
+}
+
+// eventTime returns the time the event was last seen.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// ResourceCollector stores every object of the resource kind in the namespace as YAML.
+// Objects are stored regardless of the filter, their status reflects the current state.
+type ResourceCollector struct {
+	ResourceKind
+}
+
+func (r ResourceCollector) Name() string {
+	return strings.ToLower(r.Kind) + "s"
+}
+
+func (r ResourceCollector) Collect(clients CollectorClients, namespace string, filter LogFilter) ([]Artifact, error) {
+	list, err := clients.Dynamic.Resource(r.GVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s in namespace %s: %+v", r.GVR.Resource, namespace, err)
+	}
+
+	var artifacts []Artifact
+	var errs []string
+	for _, item := range list.Items {
+		item.SetManagedFields(nil)
+		data, err := yaml.Marshal(item.Object)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", item.GetName(), err))
+			continue
+		}
+		name := fmt.Sprintf("%s-%s-%s.yaml", strings.ToLower(r.Kind), namespace, item.GetName())
+		artifacts = append(artifacts, Artifact{Name: name, Kind: r.Kind, Namespace: namespace, Data: data})
+	}
+
+	return artifacts, joinErrors(fmt.Sprintf("failed to marshal %s", r.GVR.Resource), errs)
+}
+
+func joinErrors(msg string, errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %s", msg, strings.Join(errs, "; "))
+}
@@ -0,0 +1,110 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newPipelineRun(namespace, name string) *unstructured.Unstructured {
+	pr := &unstructured.Unstructured{}
+	pr.SetAPIVersion("tekton.dev/v1")
+	pr.SetKind("PipelineRun")
+	pr.SetNamespace(namespace)
+	pr.SetName(name)
+	return pr
+}
+
+func TestCollectFailureBundle(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	kube := kubefake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "user-tenant"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "step-build"}}}},
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "build-service"},
+			LastTimestamp: metav1.NewTime(start.Add(-time.Hour)), Reason: "Old"},
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "build-service"},
+			LastTimestamp: metav1.NewTime(start.Add(time.Minute)), Type: "Warning", Reason: "Failed",
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "controller"}, Message: "back-off"},
+	)
+	// Listing pods of the controller namespace fails, the remaining collectors have to run anyway
+	kube.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "build-service" {
+			return true, nil, fmt.Errorf("forbidden")
+		}
+		return false, nil, nil
+	})
+
+	snapshots := schema.GroupVersionResource{Group: "appstudio.redhat.com", Version: "v1alpha1", Resource: "snapshots"}
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{PipelineRunKind.GVR: "PipelineRunList", snapshots: "SnapshotList"},
+		newPipelineRun("user-tenant", "on-push"))
+	dynamic.PrependReactor("list", "snapshots", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("the server could not find the requested resource")
+	})
+
+	report := FailureReport{Namespaces: map[string]string{"Build Service": "build-service"}, Collectors: []ArtifactCollector{PodLogsCollector{}, EventsCollector{}}}
+	report = report.merge(FailureReport{ResourceKinds: []ResourceKind{PipelineRunKind, {Kind: "Snapshot", GVR: snapshots}}})
+
+	artifacts, index := CollectFailureBundle(CollectorClients{Kube: kube, Dynamic: dynamic}, report, "user-tenant", "spec", start, start.Add(time.Hour))
+
+	assert.Equal(t, []string{"build-service", "user-tenant"}, index.Namespaces)
+	assert.Equal(t, "fake logs", string(artifacts["pod-user-tenant-build-step-build.log"]))
+	assert.Equal(t, "2024-05-01T10:01:00Z\tWarning\tFailed\tPod/controller\tback-off\n", string(artifacts["events-build-service.log"]))
+	assert.Contains(t, string(artifacts["pipelinerun-user-tenant-on-push.yaml"]), "name: on-push")
+
+	assert.Len(t, index.Errors, 3)
+	assert.Equal(t, BundleError{Collector: "pod-logs", Namespace: "build-service", Error: "failed to list pods in namespace build-service: forbidden"}, index.Errors[0])
+	assert.Equal(t, "snapshots", index.Errors[1].Collector)
+	assert.Equal(t, "snapshots", index.Errors[2].Collector)
+
+	var stored BundleIndex
+	assert.NoError(t, json.Unmarshal(artifacts[IndexFileName], &stored))
+	assert.Equal(t, *index, stored)
+	var files []string
+	for _, a := range stored.Artifacts {
+		files = append(files, a.File)
+	}
+	assert.ElementsMatch(t, []string{"pod-user-tenant-build-step-build.log", "events-build-service.log", "pipelinerun-user-tenant-on-push.yaml"}, files)
+}
+
+func TestFailureReportMerge(t *testing.T) {
+	report := DefaultFailureReport().merge(FailureReport{
+		Namespaces:    map[string]string{"Release Service": "release-service"},
+		ResourceKinds: []ResourceKind{ReleaseKind, {Kind: "ReleasePlan", GVR: schema.GroupVersionResource{Group: "appstudio.redhat.com", Version: "v1alpha1", Resource: "releaseplans"}}},
+		Collectors:    []ArtifactCollector{EventsCollector{}},
+	})
+
+	assert.Equal(t, "release-service", report.Namespaces["Release Service"])
+	assert.Equal(t, "build-service", report.Namespaces["Build Service"])
+	assert.Len(t, report.ResourceKinds, 6)
+	assert.Len(t, report.Collectors, 2)
+	assert.Len(t, report.collectors(), 8)
+}
+
+func TestPodLogsOfSameNamedPods(t *testing.T) {
+	kube := kubefake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "controller", Namespace: "build-service"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "manager"}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "controller", Namespace: "release-service"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "manager"}}}},
+	)
+	report := FailureReport{Namespaces: map[string]string{"Build Service": "build-service", "Release Service": "release-service"}, Collectors: []ArtifactCollector{PodLogsCollector{}}}
+
+	artifacts, index := CollectFailureBundle(CollectorClients{Kube: kube}, report, "", "spec", time.Time{}, time.Time{})
+
+	assert.Empty(t, index.Errors)
+	assert.Contains(t, artifacts, "pod-build-service-controller-manager.log")
+	assert.Contains(t, artifacts, "pod-release-service-controller-manager.log")
+}
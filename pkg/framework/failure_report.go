@@ -1,8 +1,6 @@
 package framework
 
 import (
-	"regexp"
-	"strings"
 	"time"
 
 	"github.com/konflux-ci/e2e-tests/pkg/logs"
@@ -10,12 +8,14 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 )
 
-func ReportFailure(f **Framework) func() {
-	namespaces := map[string]string{
-		"Build Service":       "build-service",
-		"JVM Build Service":   "jvm-build-service",
-		"Application Service": "application-service",
-		"Image Controller":    "image-controller"}
+// ReportFailure returns a function storing a failure bundle of the current spec if it failed.
+// The bundle contains what DefaultFailureReport describes, extended by the passed in reports,
+// collected from the user namespace and the controller namespaces. See CollectFailureBundle.
+func ReportFailure(f **Framework, reports ...FailureReport) func() {
+	report := DefaultFailureReport()
+	for _, r := range reports {
+		report = report.merge(r)
+	}
 
 	return func() {
 		if !CurrentSpecReport().Failed() {
@@ -31,55 +31,23 @@ func ReportFailure(f **Framework) func() {
 			GinkgoWriter.Printf("failed to store test timing: %v\n", err)
 		}
 
-		allPodLogs := make(map[string][]byte)
-		for _, namespace := range namespaces {
-			podList, err := fwk.AsKubeAdmin.CommonController.ListAllPods(namespace)
-			if err != nil {
-				GinkgoWriter.Printf("failed to list pods in namespace %s: %v\n", namespace, err)
-				return
-			}
-
-			for _, pod := range podList.Items {
-				podLogs := fwk.AsKubeAdmin.CommonController.GetPodLogs(&pod)
-
-				for podName, log := range podLogs {
-					if filteredLogs := FilterLogs(string(log), CurrentSpecReport().StartTime); filteredLogs != "" {
-						allPodLogs[podName] = []byte(filteredLogs)
-					}
-				}
-			}
+		clients := CollectorClients{
+			Kube:    fwk.AsKubeAdmin.CommonController.KubeInterface(),
+			Dynamic: fwk.AsKubeAdmin.CommonController.DynamicClient(),
+		}
+		artifacts, index := CollectFailureBundle(clients, report, fwk.UserNamespace, CurrentSpecReport().FullText(), CurrentSpecReport().StartTime, time.Now())
+		for _, e := range index.Errors {
+			GinkgoWriter.Printf("failure bundle: %s collector failed in namespace %s: %s\n", e.Collector, e.Namespace, e.Error)
 		}
 
-		if err := logs.StoreArtifacts(allPodLogs); err != nil {
-			GinkgoWriter.Printf("failed to store pod logs: %v\n", err)
+		if err := logs.StoreArtifacts(artifacts); err != nil {
+			GinkgoWriter.Printf("failed to store failure bundle: %v\n", err)
 		}
 	}
 }
 
+// FilterLogs returns the log lines logged at or after the start time.
+// See LogFilter for filtering by a time window, namespace or resource names.
 func FilterLogs(logs string, start time.Time) string {
-
-	//bit of a hack, the logs are in different formats and are not always valid JSON
-	//just look for RFC 3339 dates line by line, once we find one after the start time dump the
-	//rest of the lines
-	lines := strings.Split(logs, "\n")
-	ret := []string{}
-	rfc3339Pattern := `(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2}))`
-
-	re := regexp.MustCompile(rfc3339Pattern)
-	for pos, i := range lines {
-		match := re.FindStringSubmatch(i)
-
-		if match != nil {
-			dateString := match[1]
-			ts, err := time.Parse(time.RFC3339, dateString)
-			if err != nil {
-				ret = append(ret, "Invalid Time, unable to parse date: "+i)
-			} else if ts.Equal(start) || ts.After(start) {
-				ret = append(ret, lines[pos:]...)
-				break
-			}
-		}
-	}
-
-	return strings.Join(ret, "\n")
+	return LogFilter{Start: start}.Filter(logs)
 }
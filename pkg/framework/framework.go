@@ -2,11 +2,16 @@ package framework
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/avast/retry-go/v4"
@@ -21,8 +26,13 @@ import (
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/ledger"
+	results "github.com/konflux-ci/e2e-tests/pkg/utils/pipeline"
+	"github.com/konflux-ci/image-controller/pkg/quay"
 )
 
+const quayApiUrl = "https://quay.io/api/v1"
+
 type ControllerHub struct {
 	HasController         *has.HasController
 	CommonController      *common.SuiteController
@@ -43,25 +53,19 @@ type Framework struct {
 	UserNamespace        string
 	UserName             string
 	UserToken            string
+	// Source of refreshed user token for Stage users, UserToken holds only the initial token
+	TokenSource *sandbox.KeycloakTokenSource
+	// Resources created through the controllers, deleted once the spec or container ends
+	Ledger *ledger.Ledger
+
+	stopTokenRefresh context.CancelFunc
+	refreshFailures  *refreshFailures
 }
 
 func NewFramework(userName string, stageConfig ...utils.Options) (*Framework, error) {
 	return NewFrameworkWithTimeout(userName, time.Second*60, stageConfig...)
 }
 
-// This periodically refreshes framework for Stage user because of Keycloak access token expires in 15 minutes
-func refreshFrameworkStage(currentFramework *Framework, userName string, timeout time.Duration, options ...utils.Options) {
-	for {
-		time.Sleep(time.Minute * 10)
-		fw, err := newFrameworkWithTimeout(userName, timeout, options...)
-		if err != nil {
-			fmt.Printf("ERROR: Failed refreshing framework for user %s: %+v\n", userName, err)
-			return
-		}
-		*currentFramework = *fw
-	}
-}
-
 func newFrameworkWithTimeout(userName string, timeout time.Duration, options ...utils.Options) (*Framework, error) {
 	var err error
 	var k *kubeCl.K8SClient
@@ -88,8 +92,21 @@ func newFrameworkWithTimeout(userName string, timeout time.Duration, options ...
 	// in some very rare cases fail to get the client for some timeout in member operator.
 	// Just try several times to get the user kubeconfig
 
+	// in tenant mode the user is provisioned directly as a tenant namespace, for clusters without the sandbox stack
+	tenantMode := !isStage && utils.GetEnv(constants.FRAMEWORK_MODE_ENV, constants.FrameworkModeSandbox) == constants.FrameworkModeTenant
+	// there is no OpenShift console route to take the cluster app domain from
+	if tenantMode && utils.GetEnv(constants.CLUSTER_APP_DOMAIN_ENV, "") == "" {
+		return nil, fmt.Errorf("%s env var is required in %q framework mode", constants.CLUSTER_APP_DOMAIN_ENV, constants.FrameworkModeTenant)
+	}
+
 	err = retry.Do(
 		func() error {
+			if tenantMode {
+				if k, err = kubeCl.NewTenantClient(userName); err != nil {
+					GinkgoWriter.Printf("error when creating tenant client: %+v\n", err)
+				}
+				return err
+			}
 			if k, err = kubeCl.NewDevSandboxProxyClient(userName, isStage, option); err != nil {
 				GinkgoWriter.Printf("error when creating dev sandbox proxy client: %+v\n", err)
 			}
@@ -108,7 +125,7 @@ func newFrameworkWithTimeout(userName string, timeout time.Duration, options ...
 		if err != nil {
 			return nil, fmt.Errorf("error when initializing appstudio hub controllers for admin user: %v", err)
 		}
-		if err = asAdmin.CommonController.AddRegistryAuthSecretToSA("QUAY_TOKEN", k.UserNamespace); err != nil {
+		if err = asAdmin.CommonController.AddRegistryAuthSecretToSA(constants.QUAY_TOKEN_ENV, k.UserNamespace); err != nil {
 			GinkgoWriter.Println(fmt.Sprintf("Failed to add registry auth secret to service account: %v\n", err))
 		}
 	}
@@ -122,16 +139,29 @@ func newFrameworkWithTimeout(userName string, timeout time.Duration, options ...
 		asAdmin = asUser
 	}
 
+	// Logs of pruned PipelineRuns are taken from Tekton Results, see TektonController.GetPipelineRunLogs
+	if resultsUrl := tektonResultsUrl(k.ProxyUrl, tenantMode); resultsUrl != "" {
+		resultClient := results.NewClient(resultsUrl, k.UserToken)
+		if k.TokenSource != nil {
+			resultClient.TokenSource = k.TokenSource
+		}
+		asUser.TektonController.ResultClient = resultClient
+		asAdmin.TektonController.ResultClient = resultClient
+	}
+
 	if !isStage {
 		if err = utils.WaitUntil(asAdmin.CommonController.ServiceAccountPresent(constants.DefaultPipelineServiceAccount, k.UserNamespace), timeout); err != nil {
 			return nil, fmt.Errorf("'%s' service account wasn't created in %s namespace: %+v", constants.DefaultPipelineServiceAccount, k.UserNamespace, err)
 		}
-		r, err := asAdmin.CommonController.CustomClient.RouteClient().RouteV1().Routes("openshift-console").Get(context.Background(), "console", v1.GetOptions{})
-		if err != nil {
-			return nil, fmt.Errorf("cannot get openshift console route in order to determine cluster app domain: %+v", err)
+		if !tenantMode {
+			r, err := asAdmin.CommonController.CustomClient.RouteClient().RouteV1().Routes("openshift-console").Get(context.Background(), "console", v1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("cannot get openshift console route in order to determine cluster app domain: %+v", err)
+			}
+			openshiftConsoleHost = r.Spec.Host
+			clusterAppDomain = strings.Join(strings.Split(openshiftConsoleHost, ".")[1:], ".")
 		}
-		openshiftConsoleHost = r.Spec.Host
-		clusterAppDomain = strings.Join(strings.Split(openshiftConsoleHost, ".")[1:], ".")
+		clusterAppDomain = utils.GetEnv(constants.CLUSTER_APP_DOMAIN_ENV, clusterAppDomain)
 	}
 	return &Framework{
 		AsKubeAdmin:          asAdmin,
@@ -143,10 +173,40 @@ func newFrameworkWithTimeout(userName string, timeout time.Duration, options ...
 		UserNamespace:        k.UserNamespace,
 		UserName:             k.UserName,
 		UserToken:            k.UserToken,
+		TokenSource:          k.TokenSource,
 	}, nil
 }
 
+// tektonResultsUrl returns TEKTON_RESULTS_URL or the Tekton Results plugin of the sandbox proxy,
+// empty when neither is available, i.e. in tenant mode the user talks directly to the API server.
+func tektonResultsUrl(proxyUrl string, tenantMode bool) string {
+	if url := utils.GetEnv(constants.TEKTON_RESULTS_URL_ENV, ""); url != "" {
+		return url
+	}
+	if tenantMode || proxyUrl == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/plugins/tekton-results", proxyUrl)
+}
+
+// NewFrameworkWithTimeout creates framework for the user, without options the environment profile from E2E_ENVIRONMENT_PROFILE is used when set
 func NewFrameworkWithTimeout(userName string, timeout time.Duration, options ...utils.Options) (*Framework, error) {
+	return NewFrameworkWithContext(context.Background(), userName, timeout, options...)
+}
+
+// NewFrameworkWithContext is NewFrameworkWithTimeout with the token refresh of Stage user running until the context is done or Stop is called
+func NewFrameworkWithContext(ctx context.Context, userName string, timeout time.Duration, options ...utils.Options) (*Framework, error) {
+	if len(options) == 0 {
+		profileOptions, err := environmentProfileOptions()
+		if err != nil {
+			return nil, err
+		}
+		options = profileOptions
+	}
+	return newFramework(ctx, userName, timeout, options...)
+}
+
+func newFramework(ctx context.Context, userName string, timeout time.Duration, options ...utils.Options) (*Framework, error) {
 	isStage, err := utils.CheckOptions(options)
 	if err != nil {
 		return nil, err
@@ -157,12 +217,110 @@ func NewFrameworkWithTimeout(userName string, timeout time.Duration, options ...
 	}
 
 	fw, err := newFrameworkWithTimeout(userName, timeout, options...)
+	if err != nil {
+		return nil, err
+	}
 
-	if isStage {
-		go refreshFrameworkStage(fw, userName, timeout, options...)
+	fw.Ledger = ledger.New()
+	fw.Ledger.Start()
+	for _, hub := range []*ControllerHub{fw.AsKubeAdmin, fw.AsKubeDeveloper} {
+		hub.CommonController.CustomClient.SetLedger(fw.Ledger)
+		hub.CommonController.Github.SetLedger(fw.Ledger)
+		hub.CommonController.Gitlab.SetLedger(fw.Ledger)
+	}
+	if quayToken := utils.GetEnv(constants.DEFAULT_QUAY_ORG_TOKEN_ENV, ""); quayToken != "" {
+		quayClient := quay.NewQuayClient(&http.Client{Transport: &http.Transport{}}, quayToken, quayApiUrl)
+		quayOrganization := utils.GetEnv(constants.DEFAULT_QUAY_ORG_ENV, "redhat-appstudio-qe")
+		for _, hub := range []*ControllerHub{fw.AsKubeAdmin, fw.AsKubeDeveloper} {
+			hub.ImageController.SetQuay(quayClient, quayOrganization)
+		}
+	}
+
+	// Keycloak access token of Stage user expires in 15 minutes, clients take the refreshed one from the token source
+	if fw.TokenSource != nil {
+		ctx, fw.stopTokenRefresh = context.WithCancel(ctx)
+		fw.refreshFailures = &refreshFailures{}
+		go fw.TokenSource.Run(ctx, func(err error) {
+			GinkgoWriter.Printf("ERROR: %+v\n", err)
+			fw.refreshFailures.add(err)
+		})
+		// Outside of Ginkgo nodes (e.g. in load tests) the caller stops the refresh and checks CurrentUserToken
+		if CurrentSpecReport().LeafNodeType != types.NodeTypeInvalid {
+			DeferCleanup(fw.reportRefreshFailures)
+			DeferCleanup(fw.Stop)
+		}
 	}
 
-	return fw, err
+	return fw, nil
+}
+
+// Stop stops the background token refresh of Stage user, framework clients can't be used afterwards once the token expires
+func (f *Framework) Stop() {
+	if f.stopTokenRefresh != nil {
+		f.stopTokenRefresh()
+	}
+}
+
+// refreshFailures records failed refreshes of Stage user token done in background
+type refreshFailures struct {
+	lock sync.Mutex
+	errs []error
+}
+
+func (r *refreshFailures) add(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *refreshFailures) get() []error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.errs
+}
+
+// reportRefreshFailures adds failed token refreshes to the report of the node which created the framework
+// and fails it when the token could not be refreshed at all since the last failure
+func (f *Framework) reportRefreshFailures() {
+	errs := f.refreshFailures.get()
+	if len(errs) == 0 {
+		return
+	}
+	AddReportEntry("Token refresh failures", errors.Join(errs...).Error())
+	if err := f.TokenSource.Err(); err != nil {
+		Fail(fmt.Sprintf("token of user %s could not be refreshed: %+v", f.UserName, err))
+	}
+}
+
+// DeleteUserOnCleanup tracks deletion of the framework user in the ledger. Call it right after creating the framework,
+// so the user is deleted after all the resources created later. Stage users are never deleted.
+func (f *Framework) DeleteUserOnCleanup() {
+	if f.SandboxController == nil || f.TokenSource != nil {
+		return
+	}
+	f.Ledger.Track(fmt.Sprintf("user %s", f.UserName), func() error {
+		_, err := f.SandboxController.DeleteUserSignup(f.UserName)
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// CurrentUserToken returns valid user token, for Stage users refreshed one. When the last refresh failed its error
+// is returned, even though the current token may still be valid for a while.
+func (f *Framework) CurrentUserToken() (string, error) {
+	if f.TokenSource == nil {
+		return f.UserToken, nil
+	}
+	token, err := f.TokenSource.Token()
+	if err == nil {
+		err = f.TokenSource.Err()
+	}
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
 }
 
 func InitControllerHub(cc *kubeCl.CustomClient) (*ControllerHub, error) {
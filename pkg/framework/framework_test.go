@@ -0,0 +1,58 @@
+package framework
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrentUserTokenRefreshFailure(t *testing.T) {
+	var failing atomic.Bool
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(15*time.Minute).Unix())))
+	accessToken := fmt.Sprintf("header.%s.signature", payload)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"%s"}`, accessToken)
+	}))
+	defer server.Close()
+
+	tokenSource := sandbox.NewKeycloakTokenSource(&sandbox.SandboxController{HttpClient: server.Client()}, "user", server.URL, "offline-token")
+	fw := &Framework{UserName: "user", TokenSource: tokenSource}
+
+	token, err := fw.CurrentUserToken()
+	assert.NoError(t, err)
+	assert.Equal(t, accessToken, token)
+
+	// the current token is still valid, but the failed refresh reaches the caller
+	failing.Store(true)
+	_, err = tokenSource.Refresh()
+	assert.NoError(t, err)
+	_, err = fw.CurrentUserToken()
+	assert.ErrorContains(t, err, "failed to refresh keycloak token for user user")
+
+	failing.Store(false)
+	_, err = tokenSource.Refresh()
+	assert.NoError(t, err)
+	token, err = fw.CurrentUserToken()
+	assert.NoError(t, err)
+	assert.Equal(t, accessToken, token)
+}
+
+func TestTenantModeRequiresAppDomain(t *testing.T) {
+	t.Setenv(constants.FRAMEWORK_MODE_ENV, constants.FrameworkModeTenant)
+	t.Setenv(constants.CLUSTER_APP_DOMAIN_ENV, "")
+
+	_, err := newFrameworkWithTimeout("user", time.Second)
+	assert.ErrorContains(t, err, constants.CLUSTER_APP_DOMAIN_ENV)
+}
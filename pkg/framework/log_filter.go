@@ -0,0 +1,234 @@
+package framework
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	rfc3339Regex = regexp.MustCompile(`(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2}))`)
+	// klog header, i.e. "I0818 01:18:56.213456   12345 controller.go:123] msg"
+	klogRegex = regexp.MustCompile(`^[IWEF](\d{2})(\d{2}) (\d{2}):(\d{2}):(\d{2})\.(\d+)\s`)
+	// key="value" pairs of klog structured logs
+	keyValueRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// JSON keys holding the timestamp of zap and logr JSON logs
+var jsonTimeKeys = []string{"ts", "time", "timestamp", "@timestamp"}
+
+// LogFilter selects the log lines of a time window, optionally only the ones related to a namespace or resources.
+// Lines without a timestamp (i.e. stack traces) belong to the closest preceding line with a timestamp.
+type LogFilter struct {
+	Start time.Time
+	// End of the window, a zero value means no upper bound
+	End time.Time
+	// Namespace keeps only the lines whose namespace fields match it
+	Namespace string
+	// ResourceNames keeps only the lines whose resource name fields match one of them
+	ResourceNames []string
+}
+
+// LogLine is a parsed log line.
+type LogLine struct {
+	Raw  string
+	Time time.Time
+	// Namespaces and Names are the values of the namespace and resource name fields found in the line
+	Namespaces []string
+	Names      []string
+	// Structured is true when the line contains JSON or key="value" fields
+	Structured bool
+}
+
+// ParseLogLine parses a line of a JSON zap log, a klog log or a plain log with RFC 3339 timestamps.
+// The year of klog timestamps is unknown, so it is taken from the reference time.
+func ParseLogLine(line string, reference time.Time) LogLine {
+	l := LogLine{Raw: line}
+
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(trimmed), &fields); err == nil {
+			l.Structured = true
+			l.Time = jsonTime(fields)
+			l.addFields(fields)
+			return l
+		}
+	}
+
+	if match := klogRegex.FindStringSubmatch(line); match != nil {
+		l.Time = klogTime(match, reference)
+	} else if match := rfc3339Regex.FindStringSubmatch(line); match != nil {
+		l.Time = parseTimestamp(match[1])
+	}
+
+	// zap console encoder appends the fields as JSON, i.e. "<ts>	INFO	<logger>	<caller>	<msg>	{...}"
+	if i := strings.Index(line, "{"); i >= 0 {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line[i:])), &fields); err == nil {
+			l.Structured = true
+			l.addFields(fields)
+		}
+	}
+	for _, kv := range keyValueRegex.FindAllStringSubmatch(line, -1) {
+		l.Structured = true
+		l.addField(kv[1], kv[2])
+	}
+
+	return l
+}
+
+func (l *LogLine) addFields(fields map[string]any) {
+	for key, value := range fields {
+		switch v := value.(type) {
+		case string:
+			l.addField(key, v)
+		case map[string]any:
+			l.addFields(v)
+		}
+	}
+}
+
+func (l *LogLine) addField(key, value string) {
+	switch key {
+	case "namespace":
+		l.Namespaces = dedupeAppendString(l.Namespaces, value)
+	case "name", "resource":
+		l.Names = dedupeAppendString(l.Names, value)
+	case "request", "pod", "object":
+		// namespaced name, i.e. "user-tenant/my-component"
+		if ns, name, ok := strings.Cut(value, "/"); ok {
+			l.Namespaces = dedupeAppendString(l.Namespaces, ns)
+			l.Names = dedupeAppendString(l.Names, name)
+		} else {
+			l.Names = dedupeAppendString(l.Names, value)
+		}
+	}
+}
+
+// IsZero returns true if the filter matches everything.
+func (lf LogFilter) IsZero() bool {
+	return lf.Start.IsZero() && lf.End.IsZero() && lf.Namespace == "" && len(lf.ResourceNames) == 0
+}
+
+// HasTime returns true if a timestamp was found in the line.
+func (l LogLine) HasTime() bool {
+	return !l.Time.IsZero()
+}
+
+// Matches returns true if the timestamped line falls into the window and matches the namespace and resource names.
+func (lf LogFilter) Matches(l LogLine) bool {
+	if l.Time.Before(lf.Start) || (!lf.End.IsZero() && l.Time.After(lf.End)) {
+		return false
+	}
+	if lf.Namespace != "" && !l.matches(lf.Namespace, l.Namespaces) {
+		return false
+	}
+	if len(lf.ResourceNames) != 0 {
+		for _, name := range lf.ResourceNames {
+			if l.matches(name, l.Names) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// matches looks the value up in the given fields, unstructured lines are searched as a whole.
+func (l LogLine) matches(value string, fields []string) bool {
+	if !l.Structured {
+		return strings.Contains(l.Raw, value)
+	}
+	for _, f := range fields {
+		if f == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the lines of the logs matched by the filter. Lines preceding the first timestamped line are dropped.
+func (lf LogFilter) Filter(logs string) string {
+	reference := lf.Start
+	if reference.IsZero() {
+		reference = time.Now()
+	}
+
+	ret := []string{}
+	include := false
+	for _, line := range strings.Split(logs, "\n") {
+		l := ParseLogLine(line, reference)
+		if l.HasTime() {
+			include = lf.Matches(l)
+		}
+		if include {
+			ret = append(ret, line)
+		}
+	}
+
+	return strings.Join(ret, "\n")
+}
+
+func jsonTime(fields map[string]any) time.Time {
+	for _, key := range jsonTimeKeys {
+		switch ts := fields[key].(type) {
+		case string:
+			if t := parseTimestamp(ts); !t.IsZero() {
+				return t
+			}
+			// zap epoch encoders can be configured to quote the value
+			if epoch, err := strconv.ParseFloat(ts, 64); err == nil {
+				return epochTime(epoch)
+			}
+		case float64:
+			return epochTime(ts)
+		}
+	}
+	return time.Time{}
+}
+
+// epochTime converts zap epoch timestamps, in seconds, milliseconds, microseconds or nanoseconds, to time.
+func epochTime(epoch float64) time.Time {
+	switch {
+	case epoch > 1e17:
+		return time.Unix(0, int64(epoch)).UTC()
+	case epoch > 1e14:
+		return time.UnixMicro(int64(epoch)).UTC()
+	case epoch > 1e11:
+		return time.UnixMilli(int64(epoch)).UTC()
+	default:
+		sec, frac := math.Modf(epoch)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC().Round(time.Microsecond)
+	}
+}
+
+func parseTimestamp(ts string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700"} {
+		if t, err := time.Parse(layout, ts); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func klogTime(match []string, reference time.Time) time.Time {
+	var parts [5]int
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(match[i+1])
+	}
+	nsec, _ := strconv.Atoi((match[6] + "000000000")[:9])
+	return time.Date(reference.Year(), time.Month(parts[0]), parts[1], parts[2], parts[3], parts[4], nsec, time.UTC)
+}
+
+func dedupeAppendString(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
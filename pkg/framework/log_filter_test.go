@@ -0,0 +1,76 @@
+package framework
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mixedLogs = `I0818 01:18:56.213456       1 controller.go:123] "Reconciling" namespace="other-tenant" name="comp-a"
+I0818 01:20:00.000000       1 controller.go:123] "Reconciling" namespace="user-tenant" name="comp-b"
+E0818 01:20:01.500000       1 controller.go:140] "Reconciler error" err="conflict" request="user-tenant/comp-b"
+goroutine 1 [running]:
+main.main()
+{"level":"info","ts":1692321602.5,"msg":"Updated","namespace":"other-tenant","name":"comp-a"}
+{"level":"error","ts":1692321603.25,"msg":"Failed","Component":{"name":"comp-c","namespace":"user-tenant"}}
+2023-08-18T01:20:04.000Z	INFO	controller	Plain line without fields
+2023-08-18T01:25:00.000Z	INFO	controller	Done	{"namespace": "user-tenant", "name": "comp-b"}`
+
+func TestParseLogLine(t *testing.T) {
+
+	reference := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	klog := ParseLogLine(`E0818 01:20:01.500000       1 controller.go:140] "Reconciler error" request="user-tenant/comp-b"`, reference)
+	assert.Equal(t, time.Date(2023, 8, 18, 1, 20, 1, 500000000, time.UTC), klog.Time)
+	assert.Equal(t, []string{"user-tenant"}, klog.Namespaces)
+	assert.Equal(t, []string{"comp-b"}, klog.Names)
+
+	zap := ParseLogLine(`{"level":"info","ts":1692321602.5,"msg":"Updated","Component":{"name":"comp-a","namespace":"other-tenant"}}`, reference)
+	assert.Equal(t, time.Date(2023, 8, 18, 1, 20, 2, 500000000, time.UTC), zap.Time)
+	assert.Equal(t, []string{"other-tenant"}, zap.Namespaces)
+	assert.Equal(t, []string{"comp-a"}, zap.Names)
+
+	plain := ParseLogLine("2023-08-18T01:20:04.000+02:00\tINFO\tno fields", reference)
+	assert.True(t, plain.HasTime())
+	assert.False(t, plain.Structured)
+
+	assert.False(t, ParseLogLine("goroutine 1 [running]:", reference).HasTime())
+}
+
+func TestEpochTime(t *testing.T) {
+
+	expected := time.Date(2023, 8, 18, 1, 20, 2, 500000000, time.UTC)
+	for _, tc := range []struct {
+		name  string
+		epoch float64
+	}{
+		{name: "seconds", epoch: 1692321602.5},
+		{name: "milliseconds", epoch: 1692321602500},
+		{name: "microseconds", epoch: 1692321602500000},
+		{name: "nanoseconds", epoch: 1692321602500000000},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, expected, epochTime(tc.epoch))
+		})
+	}
+}
+
+func TestLogFilterWindowAndNamespace(t *testing.T) {
+
+	start := time.Date(2023, 8, 18, 1, 19, 0, 0, time.UTC)
+	end := time.Date(2023, 8, 18, 1, 21, 0, 0, time.UTC)
+
+	assert.Equal(t, `I0818 01:20:00.000000       1 controller.go:123] "Reconciling" namespace="user-tenant" name="comp-b"
+E0818 01:20:01.500000       1 controller.go:140] "Reconciler error" err="conflict" request="user-tenant/comp-b"
+goroutine 1 [running]:
+main.main()
+{"level":"error","ts":1692321603.25,"msg":"Failed","Component":{"name":"comp-c","namespace":"user-tenant"}}`,
+		LogFilter{Start: start, End: end, Namespace: "user-tenant"}.Filter(mixedLogs))
+
+	assert.Equal(t, `{"level":"error","ts":1692321603.25,"msg":"Failed","Component":{"name":"comp-c","namespace":"user-tenant"}}`,
+		LogFilter{Start: start, End: end, ResourceNames: []string{"comp-c"}}.Filter(mixedLogs))
+
+	assert.Equal(t, `2023-08-18T01:25:00.000Z	INFO	controller	Done	{"namespace": "user-tenant", "name": "comp-b"}`,
+		LogFilter{Start: end, Namespace: "user-tenant"}.Filter(mixedLogs))
+}
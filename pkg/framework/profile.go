@@ -0,0 +1,283 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"sigs.k8s.io/yaml"
+)
+
+// Kinds of clusters the tests run against
+const (
+	ClusterKindLocal = "local"
+	ClusterKindCI    = "ci"
+	ClusterKindStage = "stage"
+)
+
+// Sources of the developer identity of the test users
+const (
+	IdentitySourceSandbox  = constants.FrameworkModeSandbox // toolchain UserSignup, accessed through the sandbox proxy
+	IdentitySourceTenant   = constants.FrameworkModeTenant  // tenant namespace with ServiceAccount token, see kubeCl.NewTenantClient
+	IdentitySourceKeycloak = "keycloak"                     // existing Stage user authenticated with Keycloak offline token
+)
+
+// Feature toggles which can be set in the profile and environment variables they map to
+var featureToggles = map[string]string{
+	"skipPacTests":       constants.SKIP_PAC_TESTS_ENV,
+	"skipHasSecretCheck": constants.SKIP_HAS_SECRET_CHECK_ENV,
+	"skipCleanup":        constants.E2E_SKIP_CLEANUP_ENV,
+}
+
+// EnvironmentProfile describes the environment tests run against, replacing the individual environment variables.
+// Tokens in the profile file can reference environment variables as ${NAME}, so secrets don't need to be stored in it.
+type EnvironmentProfile struct {
+	Cluster  ClusterProfile  `json:"cluster"`
+	Identity IdentityProfile `json:"identity"`
+	Registry RegistryProfile `json:"registry,omitempty"`
+	Git      GitProfile      `json:"git,omitempty"`
+	Features map[string]bool `json:"features,omitempty"`
+}
+
+type ClusterProfile struct {
+	// One of "local", "ci" or "stage"
+	Kind string `json:"kind"`
+	// Application domain of the cluster, taken from the OpenShift console route when empty
+	AppDomain string `json:"appDomain,omitempty"`
+	// Tekton Results API, defaults to the tekton-results proxy plugin
+	TektonResultsUrl string `json:"tektonResultsUrl,omitempty"`
+}
+
+type IdentityProfile struct {
+	// One of "sandbox", "tenant" or "keycloak", defaults to "keycloak" on Stage and to "sandbox" elsewhere
+	Source string `json:"source,omitempty"`
+	// ClusterRole of the developer in "tenant" source
+	TenantClusterRole string `json:"tenantClusterRole,omitempty"`
+	// Required by "keycloak" source
+	ToolchainApiUrl string `json:"toolchainApiUrl,omitempty"`
+	KeycloakUrl     string `json:"keycloakUrl,omitempty"`
+	OfflineToken    string `json:"offlineToken,omitempty"`
+}
+
+type RegistryProfile struct {
+	// Quay organization where test images are pushed
+	Organization string `json:"organization,omitempty"`
+	// Base64 encoded docker config with push access to the organization
+	Token string `json:"token,omitempty"`
+}
+
+type GitProfile struct {
+	GitHubOrganization string `json:"githubOrganization,omitempty"`
+	GitHubToken        string `json:"githubToken,omitempty"`
+	GitLabOrganization string `json:"gitlabOrganization,omitempty"`
+	GitLabToken        string `json:"gitlabToken,omitempty"`
+	GitLabApiUrl       string `json:"gitlabApiUrl,omitempty"`
+}
+
+// LoadEnvironmentProfile reads profile from YAML file, expands environment variable references of the tokens and validates it
+func LoadEnvironmentProfile(path string) (*EnvironmentProfile, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error when reading environment profile %s: %+v", path, err)
+	}
+	profile := &EnvironmentProfile{}
+	if err = yaml.UnmarshalStrict(data, profile); err != nil {
+		return nil, fmt.Errorf("error when parsing environment profile %s: %+v", path, err)
+	}
+	for _, token := range []*string{&profile.Identity.OfflineToken, &profile.Registry.Token, &profile.Git.GitHubToken, &profile.Git.GitLabToken} {
+		*token = expandEnvReference(*token)
+	}
+	if err = profile.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid environment profile %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// Validate checks the profile is complete and consistent, reporting all the problems at once. Defaults are filled in.
+func (p *EnvironmentProfile) Validate() error {
+	var errs []error
+
+	switch p.Cluster.Kind {
+	case ClusterKindLocal, ClusterKindCI, ClusterKindStage:
+	case "":
+		errs = append(errs, fmt.Errorf("cluster.kind is required, one of %q, %q or %q", ClusterKindLocal, ClusterKindCI, ClusterKindStage))
+	default:
+		errs = append(errs, fmt.Errorf("unknown cluster.kind %q, expected one of %q, %q or %q", p.Cluster.Kind, ClusterKindLocal, ClusterKindCI, ClusterKindStage))
+	}
+
+	if p.Identity.Source == "" {
+		p.Identity.Source = IdentitySourceSandbox
+		if p.Cluster.Kind == ClusterKindStage {
+			p.Identity.Source = IdentitySourceKeycloak
+		}
+	}
+	switch p.Identity.Source {
+	case IdentitySourceSandbox:
+	case IdentitySourceTenant:
+		if p.Cluster.AppDomain == "" {
+			errs = append(errs, fmt.Errorf("cluster.appDomain is required with %q identity source as it can not be taken from the OpenShift console route", IdentitySourceTenant))
+		}
+	case IdentitySourceKeycloak:
+		if p.Identity.ToolchainApiUrl == "" {
+			errs = append(errs, fmt.Errorf("identity.toolchainApiUrl is required with %q identity source", IdentitySourceKeycloak))
+		}
+		if p.Identity.KeycloakUrl == "" {
+			errs = append(errs, fmt.Errorf("identity.keycloakUrl is required with %q identity source", IdentitySourceKeycloak))
+		}
+		if p.Identity.OfflineToken == "" {
+			errs = append(errs, fmt.Errorf("identity.offlineToken is required with %q identity source", IdentitySourceKeycloak))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown identity.source %q, expected one of %q, %q or %q", p.Identity.Source, IdentitySourceSandbox, IdentitySourceTenant, IdentitySourceKeycloak))
+	}
+	if (p.Cluster.Kind == ClusterKindStage) != (p.Identity.Source == IdentitySourceKeycloak) {
+		errs = append(errs, fmt.Errorf("%q identity source can be used only with (and is required by) %q cluster.kind", IdentitySourceKeycloak, ClusterKindStage))
+	}
+	if p.Identity.TenantClusterRole != "" && p.Identity.Source != IdentitySourceTenant {
+		errs = append(errs, fmt.Errorf("identity.tenantClusterRole can be used only with %q identity source", IdentitySourceTenant))
+	}
+
+	if p.Registry.Token != "" && p.Registry.Organization == "" {
+		errs = append(errs, fmt.Errorf("registry.organization is required when registry.token is set"))
+	}
+
+	for _, feature := range p.features() {
+		if _, ok := featureToggles[feature]; !ok {
+			errs = append(errs, fmt.Errorf("unknown feature %q, known features are %v", feature, knownFeatures()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Apply exports the profile to environment variables read by the controllers. Values missing in the profile are left untouched.
+func (p *EnvironmentProfile) Apply() error {
+	env := map[string]string{
+		constants.CLUSTER_APP_DOMAIN_ENV:      p.Cluster.AppDomain,
+		constants.TEKTON_RESULTS_URL_ENV:      p.Cluster.TektonResultsUrl,
+		constants.TENANT_CLUSTER_ROLE_ENV:     p.Identity.TenantClusterRole,
+		constants.QUAY_E2E_ORGANIZATION_ENV:   p.Registry.Organization,
+		constants.QUAY_TOKEN_ENV:              p.Registry.Token,
+		constants.GITHUB_E2E_ORGANIZATION_ENV: p.Git.GitHubOrganization,
+		constants.GITHUB_TOKEN_ENV:            p.Git.GitHubToken,
+		constants.GITLAB_QE_ORG_ENV:           p.Git.GitLabOrganization,
+		constants.GITLAB_BOT_TOKEN_ENV:        p.Git.GitLabToken,
+		constants.GITLAB_API_URL_ENV:          p.Git.GitLabApiUrl,
+	}
+	if p.Identity.Source != IdentitySourceKeycloak {
+		env[constants.FRAMEWORK_MODE_ENV] = p.Identity.Source
+	}
+	for _, feature := range p.features() {
+		env[featureToggles[feature]] = strconv.FormatBool(p.Features[feature])
+	}
+
+	for name, value := range env {
+		if value == "" {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("error when setting %s environment variable: %+v", name, err)
+		}
+	}
+	return nil
+}
+
+// Options returns Stage options expected by NewFramework, empty for other cluster kinds
+func (p *EnvironmentProfile) Options() []utils.Options {
+	if p.Identity.Source != IdentitySourceKeycloak {
+		return nil
+	}
+	return []utils.Options{{
+		ToolchainApiUrl: p.Identity.ToolchainApiUrl,
+		KeycloakUrl:     p.Identity.KeycloakUrl,
+		OfflineToken:    p.Identity.OfflineToken,
+	}}
+}
+
+// expandEnvReference returns value of the environment variable when the whole value is ${NAME}, other values are kept as they are
+func expandEnvReference(value string) string {
+	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
+		return os.Getenv(value[2 : len(value)-1])
+	}
+	return value
+}
+
+func (p *EnvironmentProfile) features() []string {
+	var features []string
+	for feature := range p.Features {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
+
+func knownFeatures() []string {
+	var features []string
+	for feature := range featureToggles {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// NewFrameworkWithProfile validates and applies given environment profile and creates framework for the user
+func NewFrameworkWithProfile(userName string, profile *EnvironmentProfile) (*Framework, error) {
+	options, err := applyEnvironmentProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return newFramework(context.Background(), userName, time.Second*60, options...)
+}
+
+func applyEnvironmentProfile(profile *EnvironmentProfile) ([]utils.Options, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid environment profile: %w", err)
+	}
+	if err := profile.Apply(); err != nil {
+		return nil, err
+	}
+	return profile.Options(), nil
+}
+
+// environmentProfileCache loads the profile from E2E_ENVIRONMENT_PROFILE file only once per test run
+type environmentProfileCache struct {
+	once    sync.Once
+	options []utils.Options
+	err     error
+}
+
+var environmentProfile = &environmentProfileCache{}
+
+// Options of the profile from E2E_ENVIRONMENT_PROFILE file, used when framework is created without options
+func environmentProfileOptions() ([]utils.Options, error) {
+	return environmentProfile.get()
+}
+
+// get loads, validates and applies the profile on the first call, a copy of the options is returned as newFramework modifies them
+func (c *environmentProfileCache) get() ([]utils.Options, error) {
+	c.once.Do(func() {
+		path := utils.GetEnv(constants.ENVIRONMENT_PROFILE_ENV, "")
+		if path == "" {
+			return
+		}
+		profile, err := LoadEnvironmentProfile(path)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.options, c.err = applyEnvironmentProfile(profile)
+	})
+	if c.err != nil {
+		return nil, c.err
+	}
+	return append([]utils.Options(nil), c.options...), nil
+}
@@ -0,0 +1,108 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEnvironmentProfile(t *testing.T) {
+	t.Setenv("PROFILE_TEST_QUAY_TOKEN", "ewogI3")
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+cluster:
+  kind: local
+  appDomain: apps.example.com
+identity:
+  source: tenant
+registry:
+  organization: my-org
+  token: ${PROFILE_TEST_QUAY_TOKEN}
+git:
+  githubToken: ghp_$notAVariable
+  gitlabApiUrl: https://gitlab.example.com/${PATH}
+features:
+  skipPacTests: true
+`), 0600))
+
+	profile, err := LoadEnvironmentProfile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "ewogI3", profile.Registry.Token)
+	assert.Equal(t, "ghp_$notAVariable", profile.Git.GitHubToken)
+	assert.Equal(t, "https://gitlab.example.com/${PATH}", profile.Git.GitLabApiUrl)
+	assert.Nil(t, profile.Options())
+
+	t.Setenv(constants.FRAMEWORK_MODE_ENV, "")
+	t.Setenv(constants.QUAY_TOKEN_ENV, "")
+	t.Setenv(constants.QUAY_E2E_ORGANIZATION_ENV, "")
+	t.Setenv(constants.CLUSTER_APP_DOMAIN_ENV, "")
+	t.Setenv(constants.SKIP_PAC_TESTS_ENV, "")
+	t.Setenv(constants.GITHUB_TOKEN_ENV, "")
+	t.Setenv(constants.GITLAB_API_URL_ENV, "")
+	assert.NoError(t, profile.Apply())
+	assert.Equal(t, constants.FrameworkModeTenant, os.Getenv(constants.FRAMEWORK_MODE_ENV))
+	assert.Equal(t, "ewogI3", os.Getenv(constants.QUAY_TOKEN_ENV))
+	assert.Equal(t, "my-org", os.Getenv(constants.QUAY_E2E_ORGANIZATION_ENV))
+	assert.Equal(t, "apps.example.com", os.Getenv(constants.CLUSTER_APP_DOMAIN_ENV))
+	assert.Equal(t, "true", os.Getenv(constants.SKIP_PAC_TESTS_ENV))
+
+	assert.NoError(t, os.WriteFile(path, []byte("cluster:\n  kind: local\n  appDomian: typo\n"), 0600))
+	_, err = LoadEnvironmentProfile(path)
+	assert.ErrorContains(t, err, "appDomian")
+}
+
+func TestValidateEnvironmentProfile(t *testing.T) {
+	stage := &EnvironmentProfile{Cluster: ClusterProfile{Kind: ClusterKindStage}, Identity: IdentityProfile{ToolchainApiUrl: "https://toolchain", KeycloakUrl: "https://sso", OfflineToken: "token"}}
+	assert.NoError(t, stage.Validate())
+	assert.Equal(t, IdentitySourceKeycloak, stage.Identity.Source)
+	assert.Equal(t, []utils.Options{{ToolchainApiUrl: "https://toolchain", KeycloakUrl: "https://sso", OfflineToken: "token"}}, stage.Options())
+
+	local := &EnvironmentProfile{Cluster: ClusterProfile{Kind: ClusterKindLocal}}
+	assert.NoError(t, local.Validate())
+	assert.Equal(t, IdentitySourceSandbox, local.Identity.Source)
+
+	invalid := &EnvironmentProfile{
+		Cluster:  ClusterProfile{Kind: ClusterKindCI},
+		Identity: IdentityProfile{Source: IdentitySourceKeycloak},
+		Registry: RegistryProfile{Token: "ewogI3"},
+		Features: map[string]bool{"fastMode": true},
+	}
+	err := invalid.Validate()
+	assert.ErrorContains(t, err, "identity.toolchainApiUrl is required")
+	assert.ErrorContains(t, err, "identity.keycloakUrl is required")
+	assert.ErrorContains(t, err, "identity.offlineToken is required")
+	assert.ErrorContains(t, err, `"keycloak" identity source can be used only with`)
+	assert.ErrorContains(t, err, "registry.organization is required")
+	assert.ErrorContains(t, err, `unknown feature "fastMode"`)
+
+	assert.ErrorContains(t, (&EnvironmentProfile{}).Validate(), "cluster.kind is required")
+	assert.ErrorContains(t, (&EnvironmentProfile{Cluster: ClusterProfile{Kind: ClusterKindLocal}, Identity: IdentityProfile{Source: IdentitySourceTenant}}).Validate(), "cluster.appDomain is required")
+}
+
+func TestEnvironmentProfileLoadedOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+cluster:
+  kind: stage
+identity:
+  toolchainApiUrl: https://toolchain
+  keycloakUrl: https://sso
+  offlineToken: token
+`), 0600))
+	t.Setenv(constants.ENVIRONMENT_PROFILE_ENV, path)
+
+	cache := &environmentProfileCache{}
+	options, err := cache.get()
+	assert.NoError(t, err)
+	assert.Equal(t, []utils.Options{{ToolchainApiUrl: "https://toolchain", KeycloakUrl: "https://sso", OfflineToken: "token"}}, options)
+	options[0].ToolchainApiUrl = "https://toolchain/workspaces/user"
+
+	assert.NoError(t, os.WriteFile(path, []byte("cluster:\n  kind: unknown\n"), 0600))
+	options, err = cache.get()
+	assert.NoError(t, err)
+	assert.Equal(t, []utils.Options{{ToolchainApiUrl: "https://toolchain", KeycloakUrl: "https://sso", OfflineToken: "token"}}, options)
+}
@@ -60,6 +60,9 @@ type SandboxController struct {
 
 	// Wrapper of valid kubernetes with admin access to the cluster
 	KubeRest crclient.Client
+
+	// Users are provisioned as plain tenant namespaces instead of UserSignups
+	TenantMode bool
 }
 
 // Return specs to authenticate with toolchain proxy
@@ -78,6 +81,9 @@ type SandboxUserAuthInfo struct {
 
 	// User token used as bearer to authenticate against kubernetes host
 	UserToken string
+
+	// Source of refreshed user tokens, set for Stage users whose tokens expire during the tests
+	TokenSource *KeycloakTokenSource
 }
 
 // Values to create a valid user for testing purposes
@@ -162,12 +168,18 @@ func (s *SandboxController) ReconcileUserCreationStage(userName, toolchainApiUrl
 	}
 	kubeconfigPath := utils.GetEnv(constants.USER_KUBE_CONFIG_PATH_ENV, fmt.Sprintf("%s/tmp/%s.kubeconfig", wd, userName))
 
-	userToken, err := s.GetKeycloakTokenStage(userName, keycloakUrl, offlineToken)
+	tokenSource := NewKeycloakTokenSource(s, userName, keycloakUrl, offlineToken)
+	userToken, err := tokenSource.Token()
 	if err != nil {
 		return nil, err
 	}
 
-	return s.GetKubeconfigPathForSpecificUser(true, toolchainApiUrl, userName, kubeconfigPath, userToken)
+	authInfo, err := s.GetKubeconfigPathForSpecificUser(true, toolchainApiUrl, userName, kubeconfigPath, &KeycloakAuth{AccessToken: userToken.AccessToken})
+	if err != nil {
+		return nil, err
+	}
+	authInfo.TokenSource = tokenSource
+	return authInfo, nil
 }
 
 // ReconcileUserCreation create a user in sandbox and return a valid kubeconfig for user to be used for the tests
@@ -451,6 +463,9 @@ func (s *SandboxController) GetOpenshiftRouteHost(namespace string, name string)
 }
 
 func (s *SandboxController) DeleteUserSignup(userName string) (bool, error) {
+	if s.TenantMode {
+		return s.DeleteTenant(userName)
+	}
 	userSignup := &toolchainApi.UserSignup{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      userName,
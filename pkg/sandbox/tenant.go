@@ -0,0 +1,113 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// Label marking namespaces as Konflux tenant namespaces
+	TenantNamespaceLabelKey   = "konflux-ci.dev/type"
+	TenantNamespaceLabelValue = "tenant"
+
+	// Lifetime of the developer ServiceAccount token
+	TenantTokenExpiration = 24 * time.Hour
+)
+
+// TenantNamespace returns the name of the tenant namespace of the given user
+func TenantNamespace(userName string) string {
+	return fmt.Sprintf("%s-tenant", userName)
+}
+
+// ReconcileTenantCreation provisions the user as a plain Konflux tenant, without the toolchain operators and Keycloak:
+// it creates the tenant namespace, a ServiceAccount named after the user bound to the tenant cluster role (used as the developer identity)
+// and the pipeline ServiceAccount, and returns a token of the developer ServiceAccount to be used against the given API server.
+func (s *SandboxController) ReconcileTenantCreation(userName, apiServerUrl string) (*SandboxUserAuthInfo, error) {
+	ctx := context.Background()
+	namespace := TenantNamespace(userName)
+	clusterRole := utils.GetEnv(constants.TENANT_CLUSTER_ROLE_ENV, constants.DefaultTenantClusterRole)
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   namespace,
+			Labels: map[string]string{TenantNamespaceLabelKey: TenantNamespaceLabelValue},
+		},
+	}
+	if _, err := s.KubeClient.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("error when creating %s namespace: %v", namespace, err)
+	}
+
+	for _, sa := range []string{userName, constants.DefaultPipelineServiceAccount} {
+		serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: sa, Namespace: namespace}}
+		if _, err := s.KubeClient.CoreV1().ServiceAccounts(namespace).Create(ctx, serviceAccount, metav1.CreateOptions{}); err != nil && !k8sErrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("error when creating %s serviceaccount: %v", sa, err)
+		}
+	}
+
+	roleBindings := []rbacv1.RoleBinding{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", userName, clusterRole), Namespace: namespace},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: userName, Namespace: namespace}},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRole},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPipelineServiceAccountRoleBinding, Namespace: namespace},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: constants.DefaultPipelineServiceAccount, Namespace: namespace}},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: constants.DefaultPipelineServiceAccountClusterRole},
+		},
+	}
+	for i := range roleBindings {
+		if _, err := s.KubeClient.RbacV1().RoleBindings(namespace).Create(ctx, &roleBindings[i], metav1.CreateOptions{}); err != nil && !k8sErrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("error when creating %s roleBinding: %v", roleBindings[i].Name, err)
+		}
+	}
+
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: ptr.To(int64(TenantTokenExpiration.Seconds()))},
+	}
+	token, err := s.KubeClient.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, userName, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when creating token for %s serviceaccount: %v", userName, err)
+	}
+
+	return &SandboxUserAuthInfo{
+		UserName:      userName,
+		UserNamespace: namespace,
+		ProxyUrl:      apiServerUrl,
+		UserToken:     token.Status.Token,
+	}, nil
+}
+
+// DeleteTenant deletes the tenant namespace of the given user and waits until it is gone
+func (s *SandboxController) DeleteTenant(userName string) (bool, error) {
+	namespace := TenantNamespace(userName)
+	if err := s.KubeClient.CoreV1().Namespaces().Delete(context.Background(), namespace, metav1.DeleteOptions{}); err != nil {
+		return false, err
+	}
+	err := utils.WaitUntil(func() (done bool, err error) {
+		_, err = s.KubeClient.CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
+		if err != nil {
+			if k8sErrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		return false, nil
+	}, 5*time.Minute)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package sandbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestReconcileTenantCreation(t *testing.T) {
+	kube := fake.NewSimpleClientset()
+	kube.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		return true, &authenticationv1.TokenRequest{Status: authenticationv1.TokenRequestStatus{Token: "developer-token"}}, nil
+	})
+	s := &SandboxController{KubeClient: kube, TenantMode: true}
+
+	authInfo, err := s.ReconcileTenantCreation("e2e-user", "https://api.example.com:6443")
+	assert.NoError(t, err)
+	assert.Equal(t, &SandboxUserAuthInfo{UserName: "e2e-user", UserNamespace: "e2e-user-tenant", ProxyUrl: "https://api.example.com:6443", UserToken: "developer-token"}, authInfo)
+
+	ns, err := kube.CoreV1().Namespaces().Get(context.Background(), "e2e-user-tenant", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, TenantNamespaceLabelValue, ns.Labels[TenantNamespaceLabelKey])
+
+	for _, sa := range []string{"e2e-user", constants.DefaultPipelineServiceAccount} {
+		_, err = kube.CoreV1().ServiceAccounts("e2e-user-tenant").Get(context.Background(), sa, metav1.GetOptions{})
+		assert.NoError(t, err)
+	}
+
+	rb, err := kube.RbacV1().RoleBindings("e2e-user-tenant").Get(context.Background(), "e2e-user-"+constants.DefaultTenantClusterRole, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.DefaultTenantClusterRole, rb.RoleRef.Name)
+	assert.Equal(t, "e2e-user", rb.Subjects[0].Name)
+
+	// provisioning an existing tenant again succeeds
+	_, err = s.ReconcileTenantCreation("e2e-user", "https://api.example.com:6443")
+	assert.NoError(t, err)
+
+	deleted, err := s.DeleteUserSignup("e2e-user")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+}
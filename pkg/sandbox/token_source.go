@@ -0,0 +1,153 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// Token is refreshed this long before it expires
+	DefaultTokenRefreshBefore = 2 * time.Minute
+
+	// Lifetime assumed for tokens without readable expiration, deliberately shorter than the 15 minutes Keycloak access
+	// tokens on Stage last, so such tokens are refreshed early rather than late
+	DefaultTokenLifetime = 5 * time.Minute
+
+	// Wait between attempts when refresh fails
+	tokenRefreshRetryInterval = 15 * time.Second
+)
+
+// KeycloakTokenSource provides access token of Stage user obtained with the offline token and refreshes it before it expires.
+// It is used by the proxy clients for every request, so refreshed token is used without recreating the clients.
+type KeycloakTokenSource struct {
+	controller   *SandboxController
+	userName     string
+	keycloakUrl  string
+	offlineToken string
+
+	// How long before expiration the token is refreshed
+	RefreshBefore time.Duration
+
+	lock     sync.Mutex
+	token    *oauth2.Token
+	obtained time.Time
+	lastErr  error
+}
+
+func NewKeycloakTokenSource(controller *SandboxController, userName, keycloakUrl, offlineToken string) *KeycloakTokenSource {
+	return &KeycloakTokenSource{
+		controller:    controller,
+		userName:      userName,
+		keycloakUrl:   keycloakUrl,
+		offlineToken:  offlineToken,
+		RefreshBefore: DefaultTokenRefreshBefore,
+	}
+}
+
+// Token returns current access token, fetching new one when it is about to expire. It implements oauth2.TokenSource.
+func (ts *KeycloakTokenSource) Token() (*oauth2.Token, error) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	if ts.token != nil && time.Until(ts.token.Expiry) > ts.RefreshBefore {
+		return ts.token, nil
+	}
+	return ts.refresh()
+}
+
+// Refresh fetches new access token regardless of expiration of the current one
+func (ts *KeycloakTokenSource) Refresh() (*oauth2.Token, error) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	return ts.refresh()
+}
+
+func (ts *KeycloakTokenSource) refresh() (*oauth2.Token, error) {
+	keycloakAuth, err := ts.controller.GetKeycloakTokenStage(ts.userName, ts.keycloakUrl, ts.offlineToken)
+	if err != nil {
+		ts.lastErr = fmt.Errorf("failed to refresh keycloak token for user %s: %+v", ts.userName, err)
+		// keep using the current token while it is still valid
+		if ts.token != nil && time.Now().Before(ts.token.Expiry) {
+			return ts.token, nil
+		}
+		return nil, ts.lastErr
+	}
+
+	ts.token = &oauth2.Token{
+		AccessToken: keycloakAuth.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      TokenExpiry(keycloakAuth.AccessToken),
+	}
+	ts.obtained = time.Now()
+	ts.lastErr = nil
+	return ts.token, nil
+}
+
+// ResetTokenOlderThan drops the current token when it was obtained before given time, so it is fetched again on next use.
+// It is called by the client transport when the API server rejects the token.
+func (ts *KeycloakTokenSource) ResetTokenOlderThan(t time.Time) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	if ts.obtained.Before(t) {
+		ts.token = nil
+	}
+}
+
+// Err returns error of the last refresh, nil when it succeeded
+func (ts *KeycloakTokenSource) Err() error {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	return ts.lastErr
+}
+
+// Run refreshes the token in background shortly before it expires until the context is done.
+// Failed refreshes are reported to onError and retried until they succeed.
+func (ts *KeycloakTokenSource) Run(ctx context.Context, onError func(error)) {
+	for {
+		wait := tokenRefreshRetryInterval
+		token, err := ts.Token()
+		if err == nil {
+			err = ts.Err()
+		}
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+		} else {
+			wait = max(time.Until(token.Expiry)-ts.RefreshBefore, time.Second)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// TokenExpiry returns expiration of JWT access token, tokens without readable "exp" claim are assumed to last DefaultTokenLifetime
+func TokenExpiry(accessToken string) time.Time {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) == 3 {
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err == nil {
+			claims := struct {
+				Exp int64 `json:"exp"`
+			}{}
+			if json.Unmarshal(payload, &claims) == nil && claims.Exp > 0 {
+				return time.Unix(claims.Exp, 0)
+			}
+		}
+	}
+	return time.Now().Add(DefaultTokenLifetime)
+}
@@ -0,0 +1,74 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	return fmt.Sprintf("header.%s.signature", payload)
+}
+
+func TestKeycloakTokenSource(t *testing.T) {
+	var requests atomic.Int32
+	var failing atomic.Bool
+	exp := time.Now().Add(15 * time.Minute).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "offline-token", r.Form.Get("refresh_token"))
+		if failing.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"%s"}`, testJWT(exp))
+	}))
+	defer server.Close()
+
+	ts := NewKeycloakTokenSource(&SandboxController{HttpClient: server.Client()}, "user", server.URL, "offline-token")
+
+	token, err := ts.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, exp, token.Expiry)
+	_, err = ts.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "valid token is reused")
+
+	// failed refresh keeps the still valid token, but is reported
+	failing.Store(true)
+	token, err = ts.Refresh()
+	assert.NoError(t, err)
+	assert.Equal(t, testJWT(exp), token.AccessToken)
+	assert.ErrorContains(t, ts.Err(), "failed to refresh keycloak token for user user")
+
+	// rejected token is dropped and the refresh error is returned
+	ts.ResetTokenOlderThan(time.Now().Add(time.Second))
+	_, err = ts.Token()
+	assert.ErrorContains(t, err, "failed to refresh keycloak token")
+
+	failing.Store(false)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ts.Run(ctx, func(err error) { t.Errorf("unexpected refresh error: %v", err) })
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return ts.Err() == nil }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, exp.Equal(TokenExpiry(testJWT(exp))))
+	assert.WithinDuration(t, time.Now().Add(DefaultTokenLifetime), TokenExpiry("not-a-jwt"), time.Minute)
+}
@@ -0,0 +1,133 @@
+package gc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	toolchainApi "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/sprayproxy"
+	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Namespaces collects test namespaces of the cluster, namespaces which are already terminating are skipped
+type Namespaces struct {
+	KubeClient kubernetes.Interface
+	Cluster    string
+	Policy     Policy
+	// Optional label selector limiting the listed namespaces, e.g. "konflux-ci.dev/type=tenant"
+	LabelSelector string
+}
+
+func (c *Namespaces) Name() string {
+	return "namespaces"
+}
+
+func (c *Namespaces) Collect(ctx context.Context) ([]Resource, error) {
+	namespaces, err := c.KubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: c.LabelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %+v", err)
+	}
+	var resources []Resource
+	for _, ns := range namespaces.Items {
+		if ns.Status.Phase == corev1.NamespaceTerminating || !c.Policy.Matches(ns.Name, ns.CreationTimestamp.Time) {
+			continue
+		}
+		name := ns.Name
+		resources = append(resources, NewResource("namespace", c.Cluster, name, ns.CreationTimestamp.Time, func() error {
+			err := c.KubeClient.CoreV1().Namespaces().Delete(context.Background(), name, metav1.DeleteOptions{})
+			if err != nil && !k8sErrors.IsNotFound(err) {
+				return err
+			}
+			return nil
+		}))
+	}
+	return resources, nil
+}
+
+// UserSignups collects UserSignups of test users, deleting them removes also namespaces of the users
+type UserSignups struct {
+	KubeRest crclient.Client
+	Cluster  string
+	Policy   Policy
+}
+
+func (c *UserSignups) Name() string {
+	return "usersignups"
+}
+
+func (c *UserSignups) Collect(ctx context.Context) ([]Resource, error) {
+	userSignups := &toolchainApi.UserSignupList{}
+	if err := c.KubeRest.List(ctx, userSignups, crclient.InNamespace(sandbox.DEFAULT_TOOLCHAIN_NAMESPACE)); err != nil {
+		return nil, fmt.Errorf("failed to list UserSignups: %+v", err)
+	}
+	var resources []Resource
+	for i := range userSignups.Items {
+		userSignup := &userSignups.Items[i]
+		if userSignup.DeletionTimestamp != nil || !c.Policy.Matches(userSignup.Name, userSignup.CreationTimestamp.Time) {
+			continue
+		}
+		resources = append(resources, NewResource("usersignup", c.Cluster, userSignup.Name, userSignup.CreationTimestamp.Time, func() error {
+			return crclient.IgnoreNotFound(c.KubeRest.Delete(context.Background(), userSignup))
+		}))
+	}
+	return resources, nil
+}
+
+// SprayProxyServers collects PaC servers registered in SprayProxy which are not reachable anymore, e.g. of deleted clusters.
+// SprayProxy doesn't record when a server was registered, so the policy is matched only against the server URL.
+type SprayProxyServers struct {
+	Config *sprayproxy.SprayProxyConfig
+	Policy Policy
+	// Returns true when the server is still in use, IsPacServerReachable when not set
+	IsAlive func(server string) bool
+}
+
+func (c *SprayProxyServers) Name() string {
+	return "sprayproxy-servers"
+}
+
+func (c *SprayProxyServers) Collect(ctx context.Context) ([]Resource, error) {
+	servers, err := c.Config.GetServers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registered PaC servers from SprayProxy: %+v", err)
+	}
+	isAlive := c.IsAlive
+	if isAlive == nil {
+		isAlive = IsPacServerReachable
+	}
+	var resources []Resource
+	for _, server := range strings.Split(servers, ",") {
+		server = strings.TrimSpace(server)
+		if server == "" || !c.Policy.MatchesName(server) || isAlive(server) {
+			continue
+		}
+		resources = append(resources, NewResource("sprayproxy-server", c.Config.BaseURL, server, time.Time{}, func() error {
+			if _, err := c.Config.UnregisterServer(server); err != nil {
+				return fmt.Errorf("error when unregistering PaC server %s from SprayProxy server %s: %+v", server, c.Config.BaseURL, err)
+			}
+			return nil
+		}))
+	}
+	return resources, nil
+}
+
+// IsPacServerReachable returns true when the PaC server responds to HTTP requests
+func IsPacServerReachable(server string) bool {
+	// #nosec G402
+	httpClient := http.Client{Timeout: time.Minute, Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := httpClient.Get(server)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
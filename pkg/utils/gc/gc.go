@@ -0,0 +1,193 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Number of deletions running at the same time
+	DefaultWorkers = 10
+
+	// Deletions per second sent to a single collector's service
+	DefaultRateLimit = 5.0
+)
+
+// Resource is a leaked e2e resource found by a Collector
+type Resource struct {
+	Kind     string    `json:"kind"`
+	Location string    `json:"location"`
+	Name     string    `json:"name"`
+	Created  time.Time `json:"created"`
+
+	delete func() error
+}
+
+func NewResource(kind, location, name string, created time.Time, delete func() error) Resource {
+	return Resource{Kind: kind, Location: location, Name: name, Created: created, delete: delete}
+}
+
+func (r Resource) String() string {
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Location, r.Name)
+}
+
+// Collector discovers leaked resources of one service, e.g. branches of GitHub repositories
+type Collector interface {
+	// Name identifies the collector in the report and shares rate limit of its deletions
+	Name() string
+	Collect(ctx context.Context) ([]Resource, error)
+}
+
+// Policy selects resources by naming convention and age
+type Policy struct {
+	// Resources with matching name are selected, nil matches any name
+	Name *regexp.Regexp
+	// Only resources older than MaxAge are selected
+	MaxAge time.Duration
+}
+
+// NewPolicy compiles the name pattern, empty pattern matches any name
+func NewPolicy(pattern string, maxAge time.Duration) (Policy, error) {
+	policy := Policy{MaxAge: maxAge}
+	if pattern == "" {
+		return policy, nil
+	}
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return policy, fmt.Errorf("unable to compile regex %q: %+v", pattern, err)
+	}
+	policy.Name = r
+	return policy, nil
+}
+
+// Matches returns true when the resource name matches and it was created more than MaxAge ago
+func (p Policy) Matches(name string, created time.Time) bool {
+	return p.MatchesName(name) && p.Expired(created)
+}
+
+func (p Policy) MatchesName(name string) bool {
+	return p.Name == nil || p.Name.MatchString(name)
+}
+
+func (p Policy) Expired(created time.Time) bool {
+	return time.Since(created) > p.MaxAge
+}
+
+type Options struct {
+	// Only report the resources without deleting them
+	DryRun bool
+	// Number of concurrent deletions, DefaultWorkers when not set
+	Workers int
+	// Deletions per second for each collector, zero disables the rate limiting
+	RateLimit float64
+}
+
+// GarbageCollector deletes resources leaked by e2e tests across all services of its collectors
+type GarbageCollector struct {
+	options    Options
+	collectors []Collector
+}
+
+func NewGarbageCollector(options Options, collectors ...Collector) *GarbageCollector {
+	if options.Workers <= 0 {
+		options.Workers = DefaultWorkers
+	}
+	return &GarbageCollector{options: options, collectors: collectors}
+}
+
+type job struct {
+	index    int
+	resource Resource
+	limiter  *rate.Limiter
+}
+
+// Run collects the resources and deletes them concurrently, failures of single collectors or deletions
+// don't stop the run and are recorded in the report
+func (gc *GarbageCollector) Run(ctx context.Context) *Report {
+	report := &Report{DryRun: gc.options.DryRun, Started: time.Now()}
+
+	// jobs of each collector, they are queued in turns so workers don't wait for rate limit of a single collector
+	var jobs [][]job
+	for _, collector := range gc.collectors {
+		resources, err := collector.Collect(ctx)
+		if err != nil {
+			report.CollectErrors = append(report.CollectErrors, fmt.Sprintf("%s: %+v", collector.Name(), err))
+			klog.Errorf("failed to collect resources by %s: %+v", collector.Name(), err)
+		}
+		sort.SliceStable(resources, func(i, j int) bool {
+			return resources[i].String() < resources[j].String()
+		})
+
+		var limiter *rate.Limiter
+		if gc.options.RateLimit > 0 {
+			limiter = rate.NewLimiter(rate.Limit(gc.options.RateLimit), 1)
+		}
+		var collectorJobs []job
+		for _, resource := range resources {
+			collectorJobs = append(collectorJobs, job{index: len(report.Results), resource: resource, limiter: limiter})
+			report.Results = append(report.Results, Result{Resource: resource, Collector: collector.Name()})
+		}
+		jobs = append(jobs, collectorJobs)
+	}
+
+	if gc.options.DryRun {
+		report.Finished = time.Now()
+		return report
+	}
+
+	queue := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < gc.options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				result := &report.Results[j.index]
+				if err := gc.delete(ctx, j); err != nil {
+					result.Error = err.Error()
+					klog.Errorf("failed to delete %s: %+v", j.resource, err)
+					continue
+				}
+				result.Deleted = true
+				klog.Infof("deleted %s", j.resource)
+			}
+		}()
+	}
+	for queued := true; queued; {
+		queued = false
+		for i := range jobs {
+			if len(jobs[i]) > 0 {
+				queue <- jobs[i][0]
+				jobs[i] = jobs[i][1:]
+				queued = true
+			}
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	report.Finished = time.Now()
+	return report
+}
+
+func (gc *GarbageCollector) delete(ctx context.Context, j job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if j.limiter != nil {
+		if err := j.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if j.resource.delete == nil {
+		return fmt.Errorf("%s can't be deleted", j.resource.Kind)
+	}
+	return j.resource.delete()
+}
@@ -0,0 +1,140 @@
+package gc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v44/github"
+	"github.com/stretchr/testify/assert"
+	gl "github.com/xanzy/go-gitlab"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeCollector struct {
+	name      string
+	resources []Resource
+	err       error
+}
+
+func (c *fakeCollector) Name() string {
+	return c.name
+}
+
+func (c *fakeCollector) Collect(ctx context.Context) ([]Resource, error) {
+	return c.resources, c.err
+}
+
+func TestPolicy(t *testing.T) {
+	policy, err := NewPolicy("^e2e-", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, policy.Matches("e2e-abcd", time.Now().Add(-2*time.Hour)))
+	assert.False(t, policy.Matches("e2e-abcd", time.Now()))
+	assert.False(t, policy.Matches("main", time.Now().Add(-2*time.Hour)))
+
+	policy, err = NewPolicy("", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, policy.Matches("anything", time.Now().Add(-2*time.Hour)))
+
+	_, err = NewPolicy("(", time.Hour)
+	assert.Error(t, err)
+}
+
+func TestGarbageCollector(t *testing.T) {
+	var lock sync.Mutex
+	deleted := map[string]bool{}
+	var running, maxRunning atomic.Int32
+	deleteFunc := func(name string) func() error {
+		return func() error {
+			maxRunning.Store(max(maxRunning.Load(), running.Add(1)))
+			defer running.Add(-1)
+			time.Sleep(10 * time.Millisecond)
+			lock.Lock()
+			defer lock.Unlock()
+			deleted[name] = true
+			return nil
+		}
+	}
+
+	var resources []Resource
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("branch-%02d", i)
+		resources = append(resources, NewResource("github-branch", "org/repo", name, time.Now().Add(-time.Hour), deleteFunc(name)))
+	}
+	collectors := []Collector{
+		&fakeCollector{name: "branches", resources: resources},
+		&fakeCollector{name: "webhooks", resources: []Resource{
+			NewResource("github-webhook", "org/repo", "1", time.Now(), func() error { return errors.New("forbidden") }),
+		}},
+		&fakeCollector{name: "broken", err: errors.New("unauthorized")},
+	}
+
+	report := NewGarbageCollector(Options{DryRun: true}, collectors...).Run(context.Background())
+	assert.Len(t, report.Results, 21)
+	assert.Empty(t, deleted, "nothing is deleted in dry run")
+	assert.Equal(t, []string{"broken: unauthorized"}, report.CollectErrors)
+
+	report = NewGarbageCollector(Options{Workers: 5}, collectors...).Run(context.Background())
+	assert.Len(t, deleted, 20)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(5))
+	assert.Greater(t, maxRunning.Load(), int32(1), "deletions run concurrently")
+	assert.Len(t, report.Failed(), 1)
+	assert.ErrorContains(t, report.Err(), "failed to delete github-webhook org/repo/1: forbidden")
+	assert.ErrorContains(t, report.Err(), "failed to collect resources by broken: unauthorized")
+
+	var text bytes.Buffer
+	assert.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), "branch-00")
+	assert.Contains(t, text.String(), "deleted 20 of 21 resources")
+}
+
+func TestGarbageCollectorRateLimit(t *testing.T) {
+	var resources []Resource
+	for i := 0; i < 5; i++ {
+		resources = append(resources, NewResource("quay-tag", "org/repo", fmt.Sprint(i), time.Time{}, func() error { return nil }))
+	}
+
+	start := time.Now()
+	report := NewGarbageCollector(Options{Workers: 5, RateLimit: 20}, &fakeCollector{name: "tags", resources: resources}).Run(context.Background())
+	assert.NoError(t, report.Err())
+	// first deletion is allowed immediately, the others wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+}
+
+func TestNamespaces(t *testing.T) {
+	old := metav1.NewTime(time.Now().Add(-48 * time.Hour))
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "build-e2e-abcd-tenant", CreationTimestamp: old}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "build-e2e-efgh-tenant", CreationTimestamp: metav1.Now()}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", CreationTimestamp: old}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "build-e2e-ijkl-tenant", CreationTimestamp: old}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating}},
+	)
+	policy, err := NewPolicy("^build-e2e", 24*time.Hour)
+	assert.NoError(t, err)
+
+	report := NewGarbageCollector(Options{}, &Namespaces{KubeClient: kubeClient, Cluster: "cluster", Policy: policy}).Run(context.Background())
+	assert.NoError(t, report.Err())
+	assert.Len(t, report.Results, 1)
+	assert.Equal(t, "build-e2e-abcd-tenant", report.Results[0].Name)
+
+	namespaces, err := kubeClient.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, namespaces.Items, 3)
+}
+
+func TestBranchesInUse(t *testing.T) {
+	prs := []*gh.PullRequest{
+		{Head: &gh.PullRequestBranch{Ref: gh.String("e2e-abcd")}, Base: &gh.PullRequestBranch{Ref: gh.String("base-abcd")}},
+	}
+	assert.Equal(t, map[string]bool{"e2e-abcd": true, "base-abcd": true}, githubBranchesInUse(prs))
+
+	mrs := []*gl.MergeRequest{{SourceBranch: "e2e-efgh", TargetBranch: "main"}}
+	assert.Equal(t, map[string]bool{"e2e-efgh": true, "main": true}, gitlabBranchesInUse(mrs))
+}
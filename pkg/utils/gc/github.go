@@ -0,0 +1,171 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+
+	gh "github.com/google/go-github/v44/github"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
+)
+
+// GithubRepositories collects repositories (including forks) of the organization generated by tests
+type GithubRepositories struct {
+	Client       *github.Github
+	Organization string
+	Policy       Policy
+	// Repositories with this description are selected regardless of their name, e.g. "GitOps Repository"
+	Description string
+}
+
+func (c *GithubRepositories) Name() string {
+	return "github-repositories"
+}
+
+func (c *GithubRepositories) Collect(ctx context.Context) ([]Resource, error) {
+	repos, err := c.Client.GetAllRepositories()
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, repo := range repos {
+		if !c.Policy.Expired(repo.GetCreatedAt().Time) {
+			continue
+		}
+		if !c.Policy.MatchesName(repo.GetName()) && (c.Description == "" || repo.GetDescription() != c.Description) {
+			continue
+		}
+		kind := "github-repository"
+		if repo.GetFork() {
+			kind = "github-fork"
+		}
+		repo := repo
+		resources = append(resources, NewResource(kind, c.Organization, repo.GetName(), repo.GetCreatedAt().Time, func() error {
+			return c.Client.DeleteRepository(repo)
+		}))
+	}
+	return resources, nil
+}
+
+// GithubPullRequests collects open pull requests from test branches in given repositories, they are closed
+type GithubPullRequests struct {
+	Client       *github.Github
+	Organization string
+	Repositories []string
+	// Matched against the head branch of the pull request
+	Policy Policy
+}
+
+func (c *GithubPullRequests) Name() string {
+	return "github-pull-requests"
+}
+
+func (c *GithubPullRequests) Collect(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	for _, repository := range c.Repositories {
+		prs, err := c.Client.ListPullRequests(repository)
+		if err != nil {
+			return resources, err
+		}
+		for _, pr := range prs {
+			if !c.Policy.Matches(pr.GetHead().GetRef(), pr.GetCreatedAt()) {
+				continue
+			}
+			repository, number := repository, pr.GetNumber()
+			resources = append(resources, NewResource("github-pull-request", fmt.Sprintf("%s/%s", c.Organization, repository), fmt.Sprintf("#%d %s", number, pr.GetHead().GetRef()), pr.GetCreatedAt(), func() error {
+				return c.Client.ClosePullRequest(repository, number)
+			}))
+		}
+	}
+	return resources, nil
+}
+
+// GithubBranches collects test branches in given repositories. GitHub doesn't record when a branch was created,
+// so the age of the branch is the age of its head commit. A branch created recently from an old commit looks expired,
+// hence branches used by an open pull request as head or base are kept regardless of their age.
+type GithubBranches struct {
+	Client       *github.Github
+	Organization string
+	Repositories []string
+	Policy       Policy
+}
+
+func (c *GithubBranches) Name() string {
+	return "github-branches"
+}
+
+func (c *GithubBranches) Collect(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	for _, repository := range c.Repositories {
+		branches, err := c.Client.ListBranches(repository)
+		if err != nil {
+			return resources, err
+		}
+		prs, err := c.Client.ListPullRequests(repository)
+		if err != nil {
+			return resources, err
+		}
+		inUse := githubBranchesInUse(prs)
+
+		for _, b := range branches {
+			if b.GetProtected() || !c.Policy.MatchesName(b.GetName()) || inUse[b.GetName()] {
+				continue
+			}
+			branch, err := c.Client.GetBranch(repository, b.GetName())
+			if err != nil {
+				return resources, err
+			}
+			created := branch.GetCommit().GetCommit().GetCommitter().GetDate()
+			if !c.Policy.Expired(created) {
+				continue
+			}
+			repository, name := repository, b.GetName()
+			resources = append(resources, NewResource("github-branch", fmt.Sprintf("%s/%s", c.Organization, repository), name, created, func() error {
+				return c.Client.DeleteRef(repository, name)
+			}))
+		}
+	}
+	return resources, nil
+}
+
+// githubBranchesInUse returns head and base branches of the open pull requests
+func githubBranchesInUse(prs []*gh.PullRequest) map[string]bool {
+	inUse := map[string]bool{}
+	for _, pr := range prs {
+		inUse[pr.GetHead().GetRef()] = true
+		inUse[pr.GetBase().GetRef()] = true
+	}
+	return inUse
+}
+
+// GithubWebhooks collects webhooks of given repositories, the policy is matched against the webhook URL
+type GithubWebhooks struct {
+	Client       *github.Github
+	Organization string
+	Repositories []string
+	Policy       Policy
+}
+
+func (c *GithubWebhooks) Name() string {
+	return "github-webhooks"
+}
+
+func (c *GithubWebhooks) Collect(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	for _, repository := range c.Repositories {
+		hooks, err := c.Client.ListRepoWebhooks(repository)
+		if err != nil {
+			return resources, err
+		}
+		for _, hook := range hooks {
+			url, _ := hook.Config["url"].(string)
+			if !c.Policy.Matches(url, hook.GetCreatedAt()) {
+				continue
+			}
+			repository, id := repository, hook.GetID()
+			resources = append(resources, NewResource("github-webhook", fmt.Sprintf("%s/%s", c.Organization, repository), fmt.Sprintf("%d %s", id, url), hook.GetCreatedAt(), func() error {
+				return c.Client.DeleteWebhook(repository, id)
+			}))
+		}
+	}
+	return resources, nil
+}
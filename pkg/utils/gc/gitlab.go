@@ -0,0 +1,170 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/clients/gitlab"
+	gl "github.com/xanzy/go-gitlab"
+)
+
+// GitlabMergeRequests collects opened merge requests from test branches in given projects, they are closed
+type GitlabMergeRequests struct {
+	Client     *gitlab.GitlabClient
+	ProjectIDs []string
+	// Matched against the source branch of the merge request
+	Policy Policy
+}
+
+func (c *GitlabMergeRequests) Name() string {
+	return "gitlab-merge-requests"
+}
+
+func (c *GitlabMergeRequests) Collect(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	for _, projectID := range c.ProjectIDs {
+		mrs, err := listOpenedMergeRequests(c.Client, projectID)
+		if err != nil {
+			return resources, err
+		}
+		for _, mr := range mrs {
+			if mr.CreatedAt == nil || !c.Policy.Matches(mr.SourceBranch, *mr.CreatedAt) {
+				continue
+			}
+			projectID, iid := projectID, mr.IID
+			resources = append(resources, NewResource("gitlab-merge-request", projectID, fmt.Sprintf("!%d %s", iid, mr.SourceBranch), *mr.CreatedAt, func() error {
+				return c.Client.CloseMergeRequest(projectID, iid)
+			}))
+		}
+	}
+	return resources, nil
+}
+
+func listOpenedMergeRequests(client *gitlab.GitlabClient, projectID string) ([]*gl.MergeRequest, error) {
+	opt := &gl.ListProjectMergeRequestsOptions{State: gl.Ptr("opened"), ListOptions: gl.ListOptions{PerPage: 100}}
+	var all []*gl.MergeRequest
+	for {
+		mrs, resp, err := client.GetClient().MergeRequests.ListProjectMergeRequests(projectID, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list merge requests of project %s: %v", projectID, err)
+		}
+		all = append(all, mrs...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// GitlabBranches collects test branches in given projects, the age of the branch is the age of its head commit.
+// A branch created recently from an old commit looks expired, hence branches used by an opened merge request
+// as source or target are kept regardless of their age.
+type GitlabBranches struct {
+	Client     *gitlab.GitlabClient
+	ProjectIDs []string
+	Policy     Policy
+}
+
+func (c *GitlabBranches) Name() string {
+	return "gitlab-branches"
+}
+
+func (c *GitlabBranches) Collect(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	for _, projectID := range c.ProjectIDs {
+		branches, err := c.list(projectID)
+		if err != nil {
+			return resources, err
+		}
+		mrs, err := listOpenedMergeRequests(c.Client, projectID)
+		if err != nil {
+			return resources, err
+		}
+		inUse := gitlabBranchesInUse(mrs)
+		for _, branch := range branches {
+			if branch.Default || branch.Protected || inUse[branch.Name] || branch.Commit == nil || branch.Commit.CommittedDate == nil {
+				continue
+			}
+			if !c.Policy.Matches(branch.Name, *branch.Commit.CommittedDate) {
+				continue
+			}
+			projectID, name := projectID, branch.Name
+			resources = append(resources, NewResource("gitlab-branch", projectID, name, *branch.Commit.CommittedDate, func() error {
+				return c.Client.DeleteBranch(projectID, name)
+			}))
+		}
+	}
+	return resources, nil
+}
+
+func (c *GitlabBranches) list(projectID string) ([]*gl.Branch, error) {
+	opt := &gl.ListBranchesOptions{ListOptions: gl.ListOptions{PerPage: 100}}
+	var all []*gl.Branch
+	for {
+		branches, resp, err := c.Client.GetClient().Branches.ListBranches(projectID, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches of project %s: %v", projectID, err)
+		}
+		all = append(all, branches...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// gitlabBranchesInUse returns source and target branches of the opened merge requests
+func gitlabBranchesInUse(mrs []*gl.MergeRequest) map[string]bool {
+	inUse := map[string]bool{}
+	for _, mr := range mrs {
+		inUse[mr.SourceBranch] = true
+		inUse[mr.TargetBranch] = true
+	}
+	return inUse
+}
+
+// GitlabWebhooks collects webhooks of given projects, the policy is matched against the webhook URL
+type GitlabWebhooks struct {
+	Client     *gitlab.GitlabClient
+	ProjectIDs []string
+	Policy     Policy
+}
+
+func (c *GitlabWebhooks) Name() string {
+	return "gitlab-webhooks"
+}
+
+func (c *GitlabWebhooks) Collect(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	for _, projectID := range c.ProjectIDs {
+		opt := &gl.ListProjectHooksOptions{PerPage: 100}
+		for {
+			webhooks, resp, err := c.Client.GetClient().Projects.ListProjectHooks(projectID, opt)
+			if err != nil {
+				return resources, fmt.Errorf("failed to list project hooks: %v", err)
+			}
+			for _, webhook := range webhooks {
+				created := time.Time{}
+				if webhook.CreatedAt != nil {
+					created = *webhook.CreatedAt
+				}
+				if !c.Policy.Matches(webhook.URL, created) {
+					continue
+				}
+				projectID, id := projectID, webhook.ID
+				resources = append(resources, NewResource("gitlab-webhook", projectID, fmt.Sprintf("%d %s", id, webhook.URL), created, func() error {
+					if _, err := c.Client.GetClient().Projects.DeleteProjectHook(projectID, id); err != nil {
+						return fmt.Errorf("failed to delete webhook (ID: %d): %v", id, err)
+					}
+					return nil
+				}))
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opt.Page = resp.NextPage
+		}
+	}
+	return resources, nil
+}
@@ -0,0 +1,163 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/image-controller/pkg/quay"
+)
+
+const quayRobotTimeFormat = "Mon, 02 Jan 2006 15:04:05 -0700"
+
+// QuayRobots collects robot accounts of the organization generated by tests together with their repositories.
+// Quay doesn't report when a repository was created, so its age is the age of the robot account with the same name
+// (repository name without slashes).
+type QuayRobots struct {
+	Service      quay.QuayService
+	Organization string
+	// Matched against the robot short name, i.e. without the organization prefix
+	Policy Policy
+}
+
+func (c *QuayRobots) Name() string {
+	return "quay-robots"
+}
+
+func (c *QuayRobots) Collect(ctx context.Context) ([]Resource, error) {
+	repos, err := c.Service.GetAllRepositories(c.Organization)
+	if err != nil {
+		return nil, err
+	}
+	// Key is the repo name without slashes which is the same as robot name
+	reposMap := make(map[string]string)
+	for _, repo := range repos {
+		if c.Policy.MatchesName(repo.Name) {
+			reposMap[strings.ReplaceAll(repo.Name, "/", "")] = repo.Name
+		}
+	}
+
+	robots, err := c.Service.GetAllRobotAccounts(c.Organization)
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, robot := range robots {
+		// redhat-appstudio-qe+e2e-demos turns to e2e-demos
+		splitRobotName := strings.Split(robot.Name, "+")
+		if len(splitRobotName) != 2 {
+			return resources, fmt.Errorf("failed to split robot name %s into 2 parts, got %d parts", robot.Name, len(splitRobotName))
+		}
+		shortName := splitRobotName[1]
+		if !c.Policy.MatchesName(shortName) {
+			continue
+		}
+		created, err := time.Parse(quayRobotTimeFormat, robot.Created)
+		if err != nil {
+			return resources, err
+		}
+		if !c.Policy.Expired(created) {
+			continue
+		}
+
+		if repo, exists := reposMap[shortName]; exists {
+			resources = append(resources, NewResource("quay-repository", c.Organization, repo, created, func() error {
+				return c.deleteRepository(repo)
+			}))
+		}
+		resources = append(resources, NewResource("quay-robot", c.Organization, shortName, created, func() error {
+			// DeleteRobotAccount uses robot shortname
+			if _, err := c.Service.DeleteRobotAccount(c.Organization, shortName); err != nil {
+				return fmt.Errorf("failed to delete robot account %s, error: %s", robot.Name, err)
+			}
+			return nil
+		}))
+	}
+	return resources, nil
+}
+
+func (c *QuayRobots) deleteRepository(repo string) error {
+	if _, err := c.Service.DeleteRepository(c.Organization, repo); err != nil {
+		return fmt.Errorf("failed to delete repository %s, error: %s", repo, err)
+	}
+	return nil
+}
+
+// QuayRepositories collects repositories of the organization by their name and last modification
+type QuayRepositories struct {
+	Service      quay.QuayService
+	Organization string
+	Policy       Policy
+	// Select only private repositories
+	PrivateOnly bool
+}
+
+func (c *QuayRepositories) Name() string {
+	return "quay-repositories"
+}
+
+func (c *QuayRepositories) Collect(ctx context.Context) ([]Resource, error) {
+	repos, err := c.Service.GetAllRepositories(c.Organization)
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, repo := range repos {
+		lastModified := time.Unix(int64(repo.LastModified), 0)
+		if (c.PrivateOnly && repo.IsPublic) || !c.Policy.Matches(repo.Name, lastModified) {
+			continue
+		}
+		name := repo.Name
+		resources = append(resources, NewResource("quay-repository", c.Organization, name, lastModified, func() error {
+			if _, err := c.Service.DeleteRepository(c.Organization, name); err != nil {
+				return fmt.Errorf("failed to delete repository %s with error: %v", name, err)
+			}
+			return nil
+		}))
+	}
+	return resources, nil
+}
+
+// QuayTags collects tags of the repository by their name and age
+type QuayTags struct {
+	Service      quay.QuayService
+	Organization string
+	Repository   string
+	Policy       Policy
+}
+
+func (c *QuayTags) Name() string {
+	return "quay-tags"
+}
+
+func (c *QuayTags) Collect(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	location := fmt.Sprintf("%s/%s", c.Organization, c.Repository)
+	for page := 1; ; page++ {
+		tags, hasAdditional, err := c.Service.GetTagsFromPage(c.Organization, c.Repository, page)
+		if err != nil {
+			return resources, fmt.Errorf("error getting tags of `%s` repository of `%s` organization on page `%d`, error: %s", c.Repository, c.Organization, page, err)
+		}
+		for _, tag := range tags {
+			created := time.Unix(tag.StartTS, 0)
+			if !c.Policy.Matches(tag.Name, created) {
+				continue
+			}
+			name := tag.Name
+			resources = append(resources, NewResource("quay-tag", location, name, created, func() error {
+				deleted, err := c.Service.DeleteTag(c.Organization, c.Repository, name)
+				if err != nil {
+					return fmt.Errorf("error during deletion of tag `%s` in repository `%s` of organization `%s`, error: `%s`", name, c.Repository, c.Organization, err)
+				}
+				if !deleted {
+					return fmt.Errorf("tag `%s` in repository `%s` of organization `%s` was not deleted", name, c.Repository, c.Organization)
+				}
+				return nil
+			}))
+		}
+		if !hasAdditional {
+			return resources, nil
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// Report lists resources found by the garbage collector and results of their deletion
+type Report struct {
+	DryRun        bool      `json:"dryRun"`
+	Started       time.Time `json:"started"`
+	Finished      time.Time `json:"finished"`
+	Results       []Result  `json:"results"`
+	CollectErrors []string  `json:"collectErrors,omitempty"`
+}
+
+type Result struct {
+	Resource
+	Collector string `json:"collector"`
+	Deleted   bool   `json:"deleted"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Failed returns results of resources which couldn't be deleted
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Results {
+		if result.Error != "" {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err joins errors of collectors and failed deletions, nil when everything succeeded
+func (r *Report) Err() error {
+	var errs []error
+	for _, err := range r.CollectErrors {
+		errs = append(errs, fmt.Errorf("failed to collect resources by %s", err))
+	}
+	for _, result := range r.Failed() {
+		errs = append(errs, fmt.Errorf("failed to delete %s: %s", result.Resource, result.Error))
+	}
+	return errors.Join(errs...)
+}
+
+// WriteText writes the report as a table with a summary
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tLOCATION\tNAME\tAGE\tSTATUS")
+	deleted := 0
+	for _, result := range r.Results {
+		age := "-"
+		if !result.Created.IsZero() {
+			age = r.Started.Sub(result.Created).Round(time.Minute).String()
+		}
+		status := "would be deleted"
+		switch {
+		case result.Error != "":
+			status = "failed: " + result.Error
+		case result.Deleted:
+			status = "deleted"
+			deleted++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", result.Kind, result.Location, result.Name, age, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, err := range r.CollectErrors {
+		fmt.Fprintf(w, "collector %s\n", err)
+	}
+	if r.DryRun {
+		_, err := fmt.Fprintf(w, "dry run: %d resources would be deleted\n", len(r.Results))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "deleted %d of %d resources in %s\n", deleted, len(r.Results), r.Finished.Sub(r.Started).Round(time.Second))
+	return err
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Save writes the report in JSON format to the file
+func (r *Report) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file %s: %+v", path, err)
+	}
+	defer f.Close()
+	return r.WriteJSON(f)
+}
@@ -0,0 +1,136 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Ledger records how to undo creation of test resources and undoes it in reverse order via Ginkgo DeferCleanup:
+//   - resources created in Ordered containers are undone when the node which started the ledger ends (i.e. after AfterAll
+//     when framework is created in BeforeAll), as following specs usually depend on them
+//   - other resources are undone when the spec or container node which created them ends
+//
+// Resources are kept when E2E_SKIP_CLEANUP is set to "true", the same way suites skip their cleanup, and when the spec
+// failed and KEEP_RESOURCES_ON_FAILURE is set to "true", for debugging.
+// Outside of Ginkgo nodes (e.g. in load tests) nothing is tracked. All methods can be called on nil Ledger.
+type Ledger struct {
+	lock    sync.Mutex
+	started bool
+	actions []action
+}
+
+type action struct {
+	description string
+	undo        func() error
+}
+
+func New() *Ledger {
+	return &Ledger{}
+}
+
+// inGinkgoNode returns true when running inside a spec or container node, where DeferCleanup can be called
+func inGinkgoNode() bool {
+	return CurrentSpecReport().LeafNodeType != types.NodeTypeInvalid
+}
+
+// Start binds the ledger to the scope of current Ginkgo node, resources tracked in Ordered containers are undone once it ends
+func (l *Ledger) Start() {
+	if l == nil || !inGinkgoNode() {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.started {
+		return
+	}
+	l.started = true
+	DeferCleanup(l.flush)
+}
+
+// Track registers undo action of a created resource
+func (l *Ledger) Track(description string, undo func() error) {
+	if l == nil || !inGinkgoNode() {
+		return
+	}
+	a := action{description: description, undo: undo}
+
+	if CurrentSpecReport().IsInOrderedContainer {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		if !l.started {
+			GinkgoWriter.Printf("WARNING: not tracking %s for cleanup as the framework was not created in a Ginkgo node\n", description)
+			return
+		}
+		l.actions = append(l.actions, a)
+		return
+	}
+	DeferCleanup(a.run)
+}
+
+// TrackObject registers deletion of a created Kubernetes object, objects which are already gone are ignored
+func (l *Ledger) TrackObject(kubeRest crclient.Client, obj crclient.Object) {
+	if l == nil {
+		return
+	}
+	obj = obj.DeepCopyObject().(crclient.Object)
+	kind := strings.TrimPrefix(fmt.Sprintf("%T", obj), "*")
+	l.Track(fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName()), func() error {
+		return crclient.IgnoreNotFound(kubeRest.Delete(context.Background(), obj))
+	})
+}
+
+// Len returns number of actions waiting for the end of the ledger scope
+func (l *Ledger) Len() int {
+	if l == nil {
+		return 0
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.actions)
+}
+
+// flush undoes actions tracked in Ordered containers in reverse order
+func (l *Ledger) flush() {
+	l.lock.Lock()
+	actions := l.actions
+	l.actions = nil
+	l.started = false
+	l.lock.Unlock()
+
+	for i := len(actions) - 1; i >= 0; i-- {
+		actions[i].run()
+	}
+}
+
+func (a action) run() {
+	if reason := keepReason(CurrentSpecReport().Failed()); reason != "" {
+		GinkgoWriter.Printf("Keeping %s as %s\n", a.description, reason)
+		return
+	}
+	if err := a.undo(); err != nil {
+		GinkgoWriter.Printf("Failed to clean up %s: %+v\n", a.description, err)
+	}
+}
+
+// keepReason returns why tracked resources are kept instead of being undone, empty when they are undone
+func keepReason(specFailed bool) string {
+	if isEnvTrue(constants.E2E_SKIP_CLEANUP_ENV) {
+		return fmt.Sprintf("%s is set", constants.E2E_SKIP_CLEANUP_ENV)
+	}
+	if specFailed && isEnvTrue(constants.KEEP_RESOURCES_ON_FAILURE_ENV) {
+		return fmt.Sprintf("the spec failed and %s is set", constants.KEEP_RESOURCES_ON_FAILURE_ENV)
+	}
+	return ""
+}
+
+func isEnvTrue(name string) bool {
+	return strings.EqualFold(utils.GetEnv(name, "false"), "true")
+}
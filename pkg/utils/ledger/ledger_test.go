@@ -0,0 +1,116 @@
+package ledger
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLedger(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ledger Suite")
+}
+
+func TestLedgerOutsideGinkgo(t *testing.T) {
+	l := New()
+	l.Start()
+	l.Track("resource", func() error {
+		t.Fatal("nothing should be tracked outside of Ginkgo")
+		return nil
+	})
+	if l.Len() != 0 {
+		t.Fatalf("expected no tracked actions, got %d", l.Len())
+	}
+	var nilLedger *Ledger
+	nilLedger.Track("resource", func() error { return nil })
+}
+
+func TestKeepReason(t *testing.T) {
+	tests := []struct {
+		name          string
+		skipCleanup   string
+		keepOnFailure string
+		specFailed    bool
+		expectedKept  bool
+	}{
+		{name: "passed spec", specFailed: false, expectedKept: false},
+		{name: "failed spec", specFailed: true, expectedKept: false},
+		{name: "failed spec with KEEP_RESOURCES_ON_FAILURE", keepOnFailure: "true", specFailed: true, expectedKept: true},
+		{name: "passed spec with KEEP_RESOURCES_ON_FAILURE", keepOnFailure: "true", specFailed: false, expectedKept: false},
+		{name: "passed spec with E2E_SKIP_CLEANUP", skipCleanup: "true", specFailed: false, expectedKept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("E2E_SKIP_CLEANUP", tt.skipCleanup)
+			t.Setenv("KEEP_RESOURCES_ON_FAILURE", tt.keepOnFailure)
+			if reason := keepReason(tt.specFailed); (reason != "") != tt.expectedKept {
+				t.Fatalf("expected kept=%v, got reason %q", tt.expectedKept, reason)
+			}
+		})
+	}
+}
+
+var _ = Describe("Ledger", Ordered, func() {
+	var undone []string
+	kubeRest := fake.NewClientBuilder().Build()
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+
+	Describe("in Ordered container", Ordered, func() {
+		l := New()
+
+		BeforeAll(func() {
+			l.Start()
+			Expect(kubeRest.Create(context.Background(), cm)).To(Succeed())
+			l.TrackObject(kubeRest, cm)
+		})
+
+		It("tracks resources until the end of the container", func() {
+			l.Track("first", func() error { undone = append(undone, "first"); return nil })
+			l.Track("second", func() error { undone = append(undone, "second"); return nil })
+			Expect(l.Len()).To(Equal(3))
+		})
+
+		It("keeps resources for following specs", func() {
+			Expect(undone).To(BeEmpty())
+			Expect(kubeRest.Get(context.Background(), types.NamespacedName{Name: "cm", Namespace: "default"}, &corev1.ConfigMap{})).To(Succeed())
+		})
+	})
+
+	It("undoes them in reverse order once the container ends", func() {
+		Expect(undone).To(Equal([]string{"second", "first"}))
+		err := kubeRest.Get(context.Background(), types.NamespacedName{Name: "cm", Namespace: "default"}, &corev1.ConfigMap{})
+		Expect(k8sErrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Ledger in spec", func() {
+	It("undoes resources once the spec ends", func() {
+		var undone []string
+		DeferCleanup(func() {
+			Expect(undone).To(Equal([]string{"second", "first"}))
+		})
+		l := New()
+		l.Track("first", func() error { undone = append(undone, "first"); return nil })
+		l.Track("second", func() error { undone = append(undone, "second"); return nil })
+		Expect(undone).To(BeEmpty())
+	})
+
+	It("keeps resources when E2E_SKIP_CLEANUP is set", func() {
+		var undone []string
+		DeferCleanup(func() {
+			Expect(undone).To(BeEmpty())
+		})
+		Expect(os.Setenv("E2E_SKIP_CLEANUP", "true")).To(Succeed())
+		DeferCleanup(os.Unsetenv, "E2E_SKIP_CLEANUP")
+		l := New()
+		l.Track("first", func() error { undone = append(undone, "first"); return nil })
+	})
+})
@@ -0,0 +1,259 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// filterTermRegex matches the `field == "value"` terms of the CEL filters supported by the fake server
+var filterTermRegex = regexp.MustCompile(`^\s*([\w.]+)\s*==\s*"([^"]*)"\s*$`)
+
+// FakeResultsServer is an in-memory Tekton Results API server for unit tests.
+// It supports paging and CEL filters made of `field == "value"` terms joined by `&&`,
+// where the field is `data_type` or a path in the record data, i.e. `data.metadata.name`.
+type FakeResultsServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	results []Result
+	records []Record
+	logs    map[string]string
+	// Filters received by the server, in order
+	Filters  []string
+	failures []int
+}
+
+func NewFakeResultsServer() *FakeResultsServer {
+	s := &FakeResultsServer{logs: map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// NewFakeResultsTLSServer starts the fake server with TLS, its certificate is in Certificate().
+func NewFakeResultsTLSServer() *FakeResultsServer {
+	s := &FakeResultsServer{logs: map[string]string{}}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// ResultClient returns a client of the server which doesn't wait between retries.
+func (s *FakeResultsServer) ResultClient() *ResultClient {
+	client := NewClient(s.URL, "token")
+	client.RetryInterval = 0
+	return client
+}
+
+// FailNextRequests makes the next requests fail with the given status codes, one per request.
+func (s *FakeResultsServer) FailNextRequests(statusCodes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statusCodes...)
+}
+
+func (s *FakeResultsServer) AddResult(namespace, resultID string) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := Result{Name: fmt.Sprintf("%s/results/%s", namespace, resultID), ID: resultID, UID: resultID}
+	s.results = append(s.results, result)
+	return result
+}
+
+func (s *FakeResultsServer) AddRecord(namespace, resultID, recordID, dataType string, value any) (Record, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Record{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := Record{
+		Name: fmt.Sprintf("%s/results/%s/records/%s", namespace, resultID, recordID),
+		ID:   recordID,
+		UID:  recordID,
+		Data: RecordData{Type: dataType, Value: data},
+	}
+	s.records = append(s.records, record)
+	return record, nil
+}
+
+// AddPipelineRun stores the PipelineRun in a result with the PipelineRun's UID, the way the Results watcher does.
+func (s *FakeResultsServer) AddPipelineRun(pr *pipelinev1.PipelineRun) (Record, error) {
+	s.AddResult(pr.Namespace, string(pr.UID))
+	return s.AddRecord(pr.Namespace, string(pr.UID), string(pr.UID), PipelineRunRecordType, pr)
+}
+
+// AddTaskRun stores the TaskRun in the result of its PipelineRun.
+func (s *FakeResultsServer) AddTaskRun(pipelineRunUID string, tr *pipelinev1.TaskRun) (Record, error) {
+	return s.AddRecord(tr.Namespace, pipelineRunUID, string(tr.UID), TaskRunRecordType, tr)
+}
+
+// AddLog stores the log of the resource in the given result.
+func (s *FakeResultsServer) AddLog(resultID string, resource LogResource, content string) (Record, error) {
+	log := map[string]any{"spec": map[string]any{"resource": resource}}
+	record, err := s.AddRecord(resource.Namespace, resultID, resource.UID+"-log", LogRecordType, log)
+	if err != nil {
+		return Record{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// log records are named after the log they hold
+	record.Name = strings.Replace(record.Name, "/records/", "/logs/", 1)
+	s.records[len(s.records)-1] = record
+	s.logs[record.Name] = content
+	return record, nil
+}
+
+func (s *FakeResultsServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	if r.Header.Get("Authorization") == "" {
+		http.Error(w, "missing token", http.StatusUnauthorized)
+		return
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/"+resultsAPIPath+"/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	query := r.URL.Query()
+	if filter := query.Get("filter"); filter != "" {
+		s.Filters = append(s.Filters, filter)
+	}
+
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 2 && parts[1] == "results":
+		var results []Result
+		for _, result := range s.results {
+			if matchesParent(result.Name, parts[0], Wildcard) {
+				results = append(results, result)
+			}
+		}
+		page, next, err := paginate(results, query)
+		writeJSON(w, Results{Results: page, NextPageToken: next}, err)
+	case len(parts) == 4 && (parts[3] == "records" || parts[3] == "logs"):
+		var records []Record
+		for _, record := range s.records {
+			isLog := record.Data.Type == LogRecordType
+			if matchesParent(record.Name, parts[0], parts[2]) && isLog == (parts[3] == "logs") {
+				matched, err := matchesFilter(record, query.Get("filter"))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				if matched {
+					records = append(records, record)
+				}
+			}
+		}
+		page, next, err := paginate(records, query)
+		writeJSON(w, Records{Record: page, NextPageToken: next}, err)
+	case len(parts) == 5 && parts[3] == "logs":
+		content, ok := s.logs[path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		// The log is streamed in chunks, like the real server does
+		for len(content) > 0 {
+			n := min(len(content), 16)
+			var chunk logChunk
+			chunk.Result.Name = path
+			chunk.Result.Data = []byte(content[:n])
+			_ = json.NewEncoder(w).Encode(chunk)
+			content = content[n:]
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// matchesParent returns true if the name "<parent>/results/<result>/..." belongs to the parent and the result.
+func matchesParent(name, parent, resultID string) bool {
+	parts := strings.Split(name, "/")
+	return (parent == Wildcard || parts[0] == parent) && (resultID == Wildcard || parts[2] == resultID)
+}
+
+func matchesFilter(record Record, filter string) (bool, error) {
+	if filter == "" {
+		return true, nil
+	}
+	var data any
+	if err := json.Unmarshal(record.Data.Value, &data); err != nil {
+		return false, err
+	}
+	for _, term := range strings.Split(filter, "&&") {
+		match := filterTermRegex.FindStringSubmatch(term)
+		if match == nil {
+			return false, fmt.Errorf("unsupported filter term %q", term)
+		}
+		var value any = data
+		if match[1] == "data_type" {
+			value = record.Data.Type
+		} else {
+			path, ok := strings.CutPrefix(match[1], "data.")
+			if !ok {
+				return false, fmt.Errorf("unsupported filter field %q", match[1])
+			}
+			for _, key := range strings.Split(path, ".") {
+				fields, _ := value.(map[string]any)
+				value = fields[key]
+			}
+		}
+		if value != match[2] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// paginate returns the page selected by the page_size and page_token query parameters, the token is the offset of the page.
+func paginate[T any](items []T, query map[string][]string) ([]T, string, error) {
+	offset, size := 0, len(items)
+	if token := first(query["page_token"]); token != "" {
+		var err error
+		if offset, err = strconv.Atoi(token); err != nil || offset > len(items) {
+			return nil, "", fmt.Errorf("invalid page token %q", token)
+		}
+	}
+	if pageSize := first(query["page_size"]); pageSize != "" {
+		size, _ = strconv.Atoi(pageSize)
+	}
+	end := min(offset+size, len(items))
+	next := ""
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	return items[offset:end], next, nil
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func writeJSON(w http.ResponseWriter, body any, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -1,95 +1,273 @@
 package pipeline
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"golang.org/x/oauth2"
+)
+
+const (
+	resultsAPIPath = "apis/results.tekton.dev/v1alpha2/parents"
+
+	PipelineRunRecordType = "tekton.dev/v1.PipelineRun"
+	TaskRunRecordType     = "tekton.dev/v1.TaskRun"
+	LogRecordType         = "results.tekton.dev/v1alpha3.Log"
+
+	// Wildcard selects all the parents (namespaces) or all the results of a parent
+	Wildcard = "-"
 )
 
 type ResultClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	Token      string
+	// TokenSource takes precedence over Token when set, used for tokens refreshed during the tests
+	TokenSource oauth2.TokenSource
+	// MaxRetries of a request which failed with a 5xx status code or a connection error
+	MaxRetries    int
+	RetryInterval time.Duration
+}
+
+// ClientOptions configures the client created by NewClientWithOptions.
+type ClientOptions struct {
+	// CACert is a PEM encoded CA bundle used to verify the Results API server, the system CAs are used when it's empty
+	CACert             []byte
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+	// MaxRetries defaults to 3, a negative value disables the retries
+	MaxRetries    int
+	RetryInterval time.Duration
 }
 
+// NewClient creates a client which doesn't verify the certificate of the Results API server.
 func NewClient(url, token string) *ResultClient {
+	client, _ := NewClientWithOptions(url, token, ClientOptions{InsecureSkipVerify: true})
+	return client
+}
+
+func NewClientWithOptions(url, token string, opts ClientOptions) (*ResultClient, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: opts.InsecureSkipVerify, // #nosec G402 -- opt-in for test clusters with self-signed certificates
+	}
+	if len(opts.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(opts.CACert) {
+			return nil, fmt.Errorf("failed to parse the CA certificate of Tekton Results")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = time.Minute
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryInterval == 0 {
+		opts.RetryInterval = 2 * time.Second
+	}
+
 	return &ResultClient{
 		BaseURL: url,
 		HTTPClient: &http.Client{
-			Timeout: time.Minute,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
+			Timeout:   opts.Timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
 		},
-		Token: token,
+		Token:         token,
+		MaxRetries:    opts.MaxRetries,
+		RetryInterval: opts.RetryInterval,
+	}, nil
+}
+
+// ListOptions filters and pages the listed results, records and logs.
+type ListOptions struct {
+	// Filter is a CEL expression, i.e. `data_type == "tekton.dev/v1.PipelineRun" && data.metadata.name == "my-run"`
+	Filter    string
+	OrderBy   string
+	PageSize  int
+	PageToken string
+}
+
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	if o.Filter != "" {
+		query.Set("filter", o.Filter)
+	}
+	if o.OrderBy != "" {
+		query.Set("order_by", o.OrderBy)
 	}
+	if o.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(o.PageSize))
+	}
+	if o.PageToken != "" {
+		query.Set("page_token", o.PageToken)
+	}
+	return query
 }
 
-func (c *ResultClient) sendRequest(path string) (body []byte, err error) {
+func (c *ResultClient) sendRequest(ctx context.Context, path string, query url.Values) ([]byte, error) {
 	requestURL := fmt.Sprintf("%s/%s", c.BaseURL, path)
-	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if len(query) > 0 {
+		requestURL = fmt.Sprintf("%s?%s", requestURL, query.Encode())
+	}
+
+	for attempt := 0; ; attempt++ {
+		body, retry, err := c.doRequest(ctx, requestURL)
+		if err == nil || !retry || attempt >= c.MaxRetries {
+			return body, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%+v (last error: %+v)", ctx.Err(), err)
+		case <-time.After(c.RetryInterval * time.Duration(attempt+1)):
+		}
+	}
+}
+
+// doRequest sends a single GET request, it returns whether the request can be retried when it fails.
+func (c *ResultClient) doRequest(ctx context.Context, requestURL string) (body []byte, retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("Accept", "application/json; charset=utf-8")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	token := c.Token
+	if c.TokenSource != nil {
+		t, err := c.TokenSource.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		token = t.AccessToken
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
 	res, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, ctx.Err() == nil, err
 	}
+	defer res.Body.Close()
 
 	body, err = io.ReadAll(res.Body)
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to access Tekton Result Service with status code: %d and\nbody: %s", res.StatusCode, string(body))
+		return nil, res.StatusCode >= http.StatusInternalServerError, fmt.Errorf("failed to access Tekton Result Service with status code: %d and\nbody: %s", res.StatusCode, string(body))
 	}
 
-	defer res.Body.Close()
-
-	return body, err
+	return body, false, err
 }
 
-func (c *ResultClient) GetRecords(namespace, resultId string) (*Records, error) {
-	path := fmt.Sprintf("apis/results.tekton.dev/v1alpha2/parents/%s/results/%s/records", namespace, resultId)
-
-	body, err := c.sendRequest(path)
+func (c *ResultClient) list(ctx context.Context, path string, opts ListOptions, out any) error {
+	body, err := c.sendRequest(ctx, path, opts.query())
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var records *Records
-	err = json.Unmarshal(body, &records)
-	if err != nil {
-		return nil, err
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode the response of %s: %+v", path, err)
 	}
-	return records, nil
+	return nil
 }
 
-func (c *ResultClient) GetLogs(namespace, resultId string) (*Logs, error) {
-	path := fmt.Sprintf("apis/results.tekton.dev/v1alpha2/parents/%s/results/%s/logs", namespace, resultId)
+// ListResults returns a page of the results of the parent (namespace), use Wildcard for all the namespaces.
+func (c *ResultClient) ListResults(ctx context.Context, parent string, opts ListOptions) (*Results, error) {
+	results := &Results{}
+	return results, c.list(ctx, fmt.Sprintf("%s/%s/results", resultsAPIPath, parent), opts, results)
+}
+
+// ListRecords returns a page of the records of the result, use Wildcard for the records of all the results.
+func (c *ResultClient) ListRecords(ctx context.Context, parent, resultId string, opts ListOptions) (*Records, error) {
+	records := &Records{}
+	return records, c.list(ctx, fmt.Sprintf("%s/%s/results/%s/records", resultsAPIPath, parent, resultId), opts, records)
+}
+
+// ListLogs returns a page of the log records of the result, use Wildcard for the logs of all the results.
+func (c *ResultClient) ListLogs(ctx context.Context, parent, resultId string, opts ListOptions) (*Logs, error) {
+	logs := &Logs{}
+	return logs, c.list(ctx, fmt.Sprintf("%s/%s/results/%s/logs", resultsAPIPath, parent, resultId), opts, logs)
+}
+
+// ListAllRecords returns the records of all the pages, starting at the page token of the options.
+func (c *ResultClient) ListAllRecords(ctx context.Context, parent, resultId string, opts ListOptions) ([]Record, error) {
+	var all []Record
+	for {
+		records, err := c.ListRecords(ctx, parent, resultId, opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, records.Record...)
+		if records.NextPageToken == "" {
+			return all, nil
+		}
+		opts.PageToken = records.NextPageToken
+	}
+}
+
+// ListAllLogs returns the log records of all the pages, starting at the page token of the options.
+func (c *ResultClient) ListAllLogs(ctx context.Context, parent, resultId string, opts ListOptions) ([]Record, error) {
+	var all []Record
+	for {
+		logs, err := c.ListLogs(ctx, parent, resultId, opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, logs.Record...)
+		if logs.NextPageToken == "" {
+			return all, nil
+		}
+		opts.PageToken = logs.NextPageToken
+	}
+}
 
-	body, err := c.sendRequest(path)
+// GetLog returns the decoded content of the log record with the given name.
+func (c *ResultClient) GetLog(ctx context.Context, logName string) (string, error) {
+	body, err := c.sendRequest(ctx, fmt.Sprintf("%s/%s", resultsAPIPath, logName), nil)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	var logs *Logs
-	err = json.Unmarshal(body, &logs)
+	return DecodeLogContent(body), nil
+}
+
+// FindPipelineRun returns the PipelineRun with the given name stored by Tekton Results, i.e. once it was pruned from the cluster.
+// The latest one is returned if there are more PipelineRuns with the name.
+func (c *ResultClient) FindPipelineRun(ctx context.Context, namespace, name string) (*pipelinev1.PipelineRun, error) {
+	opts := ListOptions{
+		Filter:   fmt.Sprintf(`data_type == %q && data.metadata.name == %q`, PipelineRunRecordType, name),
+		OrderBy:  "create_time desc",
+		PageSize: 1,
+	}
+	records, err := c.ListRecords(ctx, namespace, Wildcard, opts)
 	if err != nil {
 		return nil, err
 	}
-	return logs, nil
+	if len(records.Record) == 0 {
+		return nil, fmt.Errorf("PipelineRun %s/%s not found in tekton results", namespace, name)
+	}
+
+	return records.Record[0].PipelineRun()
 }
 
-func (c *ResultClient) GetLogByName(logName string) (string, error) {
-	path := fmt.Sprintf("apis/results.tekton.dev/v1alpha2/parents/%s", logName)
+func (c *ResultClient) GetRecords(namespace, resultId string) (*Records, error) {
+	return c.ListRecords(context.Background(), namespace, resultId, ListOptions{})
+}
+
+func (c *ResultClient) GetLogs(namespace, resultId string) (*Logs, error) {
+	return c.ListLogs(context.Background(), namespace, resultId, ListOptions{})
+}
 
-	body, err := c.sendRequest(path)
+func (c *ResultClient) GetLogByName(logName string) (string, error) {
+	body, err := c.sendRequest(context.Background(), fmt.Sprintf("%s/%s", resultsAPIPath, logName), nil)
 	if err != nil {
 		return "", err
 	}
@@ -97,14 +275,149 @@ func (c *ResultClient) GetLogByName(logName string) (string, error) {
 	return string(body), nil
 }
 
+// GetAllLogs returns the log records of all the pages of the given result.
+func (c *ResultClient) GetAllLogs(namespace, resultId string) ([]Record, error) {
+	return c.ListAllLogs(context.Background(), namespace, resultId, ListOptions{})
+}
+
+// GetLogContent returns the decoded content of the given log record.
+func (c *ResultClient) GetLogContent(logName string) (string, error) {
+	return c.GetLog(context.Background(), logName)
+}
+
+// DecodeLogContent decodes the log content streamed by Tekton Results as a sequence of JSON objects
+// with base64 encoded chunks of the log. The body is returned as is if it isn't a stream of chunks.
+func DecodeLogContent(body []byte) string {
+	var content bytes.Buffer
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	for decoder.More() {
+		var chunk logChunk
+		if err := decoder.Decode(&chunk); err != nil {
+			return string(body)
+		}
+		content.Write(chunk.Result.Data)
+	}
+
+	return content.String()
+}
+
+type logChunk struct {
+	Result struct {
+		Name string `json:"name"`
+		Data []byte `json:"data"`
+	} `json:"result"`
+}
+
+type Result struct {
+	Name        string            `json:"name"`
+	ID          string            `json:"id"`
+	UID         string            `json:"uid"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Summary     *RecordSummary    `json:"summary,omitempty"`
+}
+
+// RecordSummary describes the main record of a result, i.e. the PipelineRun.
+type RecordSummary struct {
+	Record string `json:"record"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type Results struct {
+	Results       []Result `json:"results"`
+	NextPageToken string   `json:"nextPageToken"`
+}
+
 type Record struct {
-	Name string `json:"name"`
-	ID   string `json:"id"`
-	UID  string `json:"uid"`
+	Name string     `json:"name"`
+	ID   string     `json:"id"`
+	UID  string     `json:"uid"`
+	Data RecordData `json:"data"`
+}
+
+// RecordData holds the type and the JSON encoded object of a record, i.e. a PipelineRun or a Log.
+type RecordData struct {
+	Type  string `json:"type"`
+	Value []byte `json:"value"`
+}
+
+// PipelineRun decodes the PipelineRun of the record, v1beta1 PipelineRuns are converted to v1.
+func (r Record) PipelineRun() (*pipelinev1.PipelineRun, error) {
+	pr := &pipelinev1.PipelineRun{}
+	switch r.Data.Type {
+	case PipelineRunRecordType:
+		if err := json.Unmarshal(r.Data.Value, pr); err != nil {
+			return nil, fmt.Errorf("failed to decode PipelineRun of record %s: %+v", r.Name, err)
+		}
+	case "tekton.dev/v1beta1.PipelineRun":
+		v1beta1 := &pipelinev1beta1.PipelineRun{}
+		if err := json.Unmarshal(r.Data.Value, v1beta1); err != nil {
+			return nil, fmt.Errorf("failed to decode PipelineRun of record %s: %+v", r.Name, err)
+		}
+		if err := v1beta1.ConvertTo(context.Background(), pr); err != nil {
+			return nil, fmt.Errorf("failed to convert PipelineRun of record %s to v1: %+v", r.Name, err)
+		}
+	default:
+		return nil, fmt.Errorf("record %s of type %q is not a PipelineRun", r.Name, r.Data.Type)
+	}
+	return pr, nil
+}
+
+// TaskRun decodes the TaskRun of the record, v1beta1 TaskRuns are converted to v1.
+func (r Record) TaskRun() (*pipelinev1.TaskRun, error) {
+	tr := &pipelinev1.TaskRun{}
+	switch r.Data.Type {
+	case TaskRunRecordType:
+		if err := json.Unmarshal(r.Data.Value, tr); err != nil {
+			return nil, fmt.Errorf("failed to decode TaskRun of record %s: %+v", r.Name, err)
+		}
+	case "tekton.dev/v1beta1.TaskRun":
+		v1beta1 := &pipelinev1beta1.TaskRun{}
+		if err := json.Unmarshal(r.Data.Value, v1beta1); err != nil {
+			return nil, fmt.Errorf("failed to decode TaskRun of record %s: %+v", r.Name, err)
+		}
+		if err := v1beta1.ConvertTo(context.Background(), tr); err != nil {
+			return nil, fmt.Errorf("failed to convert TaskRun of record %s to v1: %+v", r.Name, err)
+		}
+	default:
+		return nil, fmt.Errorf("record %s of type %q is not a TaskRun", r.Name, r.Data.Type)
+	}
+	return tr, nil
+}
+
+// LogResource returns the TaskRun or PipelineRun the log record belongs to.
+func (r Record) LogResource() (LogResource, error) {
+	var log struct {
+		Spec struct {
+			Resource LogResource `json:"resource"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(r.Data.Value, &log); err != nil {
+		return LogResource{}, fmt.Errorf("failed to decode log record %s: %+v", r.Name, err)
+	}
+
+	return log.Spec.Resource, nil
+}
+
+// ResultID returns the ID of the result the record belongs to, the name of a record is "<parent>/results/<result>/records/<record>".
+func (r Record) ResultID() string {
+	parts := strings.Split(r.Name, "/")
+	if len(parts) < 3 || parts[1] != "results" {
+		return ""
+	}
+	return parts[2]
+}
+
+type LogResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
 }
 
 type Records struct {
-	Record []Record `json:"records"`
+	Record        []Record `json:"records"`
+	NextPageToken string   `json:"nextPageToken"`
 }
 
 type Log struct {
@@ -113,5 +426,6 @@ type Log struct {
 	UID  string `json:"uid"`
 }
 type Logs struct {
-	Record []Record `json:"records"`
+	Record        []Record `json:"records"`
+	NextPageToken string   `json:"nextPageToken"`
 }
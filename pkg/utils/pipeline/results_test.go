@@ -0,0 +1,176 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGetAllLogs(t *testing.T) {
+	logValue := base64.StdEncoding.EncodeToString([]byte(`{"spec":{"resource":{"kind":"TaskRun","name":"build-pr-init","namespace":"user-tenant","uid":"tr-uid"}}}`))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/apis/results.tekton.dev/v1alpha2/parents/user-tenant/results/pr-uid/logs", r.URL.Path)
+		switch r.URL.Query().Get("page_token") {
+		case "":
+			fmt.Fprintf(w, `{"records":[{"name":"user-tenant/results/pr-uid/logs/1","data":{"type":"results.tekton.dev/v1alpha3.Log","value":"%s"}}],"nextPageToken":"next"}`, logValue)
+		case "next":
+			fmt.Fprint(w, `{"records":[{"name":"user-tenant/results/pr-uid/logs/2"}]}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	records, err := NewClient(server.URL, "token").GetAllLogs("user-tenant", "pr-uid")
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "user-tenant/results/pr-uid/logs/2", records[1].Name)
+
+	resource, err := records[0].LogResource()
+	assert.NoError(t, err)
+	assert.Equal(t, LogResource{Kind: "TaskRun", Name: "build-pr-init", Namespace: "user-tenant", UID: "tr-uid"}, resource)
+}
+
+func TestDecodeLogContent(t *testing.T) {
+	chunk := func(data string) string {
+		return fmt.Sprintf(`{"result":{"name":"log","data":"%s"}}`, base64.StdEncoding.EncodeToString([]byte(data)))
+	}
+
+	assert.Equal(t, "[init] first\n[init] second\n", DecodeLogContent([]byte(chunk("[init] first\n")+"\n"+chunk("[init] second\n"))))
+	assert.Equal(t, "plain log", DecodeLogContent([]byte("plain log")))
+}
+
+func TestListRecords(t *testing.T) {
+	server := NewFakeResultsServer()
+	defer server.Close()
+
+	for _, name := range []string{"on-push", "on-pull-request", "on-push"} {
+		pr := &pipelinev1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "user-tenant", UID: types.UID(fmt.Sprintf("uid-%d", len(server.results)))}}
+		_, err := server.AddPipelineRun(pr)
+		assert.NoError(t, err)
+	}
+	_, err := server.AddTaskRun("uid-0", &pipelinev1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "on-push-build", Namespace: "user-tenant", UID: "tr-uid"}})
+	assert.NoError(t, err)
+
+	client := server.ResultClient()
+	filter := fmt.Sprintf(`data_type == %q && data.metadata.name == "on-push"`, PipelineRunRecordType)
+	page, err := client.ListRecords(context.Background(), "user-tenant", Wildcard, ListOptions{Filter: filter, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Record, 1)
+	assert.Equal(t, "1", page.NextPageToken)
+	assert.Equal(t, []string{filter}, server.Filters)
+
+	records, err := client.ListAllRecords(context.Background(), "user-tenant", Wildcard, ListOptions{Filter: filter, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "uid-2", records[1].ResultID())
+
+	records, err = client.ListAllRecords(context.Background(), "user-tenant", "uid-0", ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	pr, err := records[0].PipelineRun()
+	assert.NoError(t, err)
+	assert.Equal(t, "on-push", pr.Name)
+	tr, err := records[1].TaskRun()
+	assert.NoError(t, err)
+	assert.Equal(t, "on-push-build", tr.Name)
+	_, err = records[1].PipelineRun()
+	assert.Error(t, err)
+
+	results, err := client.ListResults(context.Background(), Wildcard, ListOptions{PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, results.Results, 2)
+	assert.Equal(t, "2", results.NextPageToken)
+}
+
+func TestV1beta1RecordConversion(t *testing.T) {
+	pr := &pipelinev1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "on-push", Namespace: "user-tenant"},
+		Spec:       pipelinev1beta1.PipelineRunSpec{ServiceAccountName: "pipeline"},
+	}
+	value, err := json.Marshal(pr)
+	assert.NoError(t, err)
+
+	converted, err := Record{Data: RecordData{Type: "tekton.dev/v1beta1.PipelineRun", Value: value}}.PipelineRun()
+	assert.NoError(t, err)
+	assert.Equal(t, "on-push", converted.Name)
+	assert.Equal(t, "pipeline", converted.Spec.TaskRunTemplate.ServiceAccountName)
+}
+
+func TestRetries(t *testing.T) {
+	server := NewFakeResultsServer()
+	defer server.Close()
+	server.AddResult("user-tenant", "uid")
+
+	client := server.ResultClient()
+	server.FailNextRequests(http.StatusServiceUnavailable, http.StatusBadGateway)
+	results, err := client.ListResults(context.Background(), "user-tenant", ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, results.Results, 1)
+
+	// Client errors aren't retried
+	server.FailNextRequests(http.StatusForbidden)
+	_, err = client.ListResults(context.Background(), "user-tenant", ListOptions{})
+	assert.ErrorContains(t, err, "status code: 403")
+
+	server.FailNextRequests(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	_, err = client.ListResults(context.Background(), "user-tenant", ListOptions{})
+	assert.ErrorContains(t, err, "status code: 500")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.ListResults(ctx, "user-tenant", ListOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFindPipelineRunAndLogs(t *testing.T) {
+	server := NewFakeResultsTLSServer()
+	defer server.Close()
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	client, err := NewClientWithOptions(server.URL, "token", ClientOptions{CACert: caCert, RetryInterval: time.Millisecond})
+	assert.NoError(t, err)
+
+	_, err = server.AddPipelineRun(&pipelinev1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "on-push", Namespace: "user-tenant", UID: "pr-uid"}})
+	assert.NoError(t, err)
+	_, err = server.AddLog("pr-uid", LogResource{Kind: "TaskRun", Name: "on-push-build", Namespace: "user-tenant", UID: "tr-uid"}, "[build] a log longer than a single chunk\n")
+	assert.NoError(t, err)
+
+	pr, err := client.FindPipelineRun(context.Background(), "user-tenant", "on-push")
+	assert.NoError(t, err)
+	assert.Equal(t, types.UID("pr-uid"), pr.UID)
+	_, err = client.FindPipelineRun(context.Background(), "user-tenant", "missing")
+	assert.ErrorContains(t, err, "not found")
+
+	logs, err := client.ListAllLogs(context.Background(), "user-tenant", string(pr.UID), ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	content, err := client.GetLog(context.Background(), "user-tenant/results/pr-uid/logs/tr-uid-log")
+	assert.NoError(t, err)
+	assert.Equal(t, "[build] a log longer than a single chunk\n", content)
+
+	// The certificate of the server isn't trusted without the CA
+	_, err = NewClient(server.URL, "token").GetLogContent("user-tenant/results/pr-uid/logs/tr-uid-log")
+	assert.NoError(t, err)
+	untrusted, err := NewClientWithOptions(server.URL, "token", ClientOptions{MaxRetries: -1})
+	assert.NoError(t, err)
+	_, err = untrusted.GetLog(context.Background(), "user-tenant/results/pr-uid/logs/tr-uid-log")
+	assert.ErrorContains(t, err, "certificate")
+
+	_, err = NewClientWithOptions(server.URL, "token", ClientOptions{CACert: []byte("invalid")})
+	assert.Error(t, err)
+}
@@ -35,7 +35,7 @@ python3 -m pip install matplotlib
 } &>"${ARTIFACT_DIR}/monitoring-setup.log"
 
 echo "[$(date --utc -Ins)] Create summary JSON with timings"
-./evaluate.py "${ARTIFACT_DIR}/load-test-timings.csv" "${ARTIFACT_DIR}/load-test-timings.json"
+go run loadtest.go evaluate --output-dir "${ARTIFACT_DIR}"
 
 echo "[$(date --utc -Ins)] Counting PRs and TRs"
 ci-scripts/utility_scripts/count-multiarch-taskruns.py --data-dir "${ARTIFACT_DIR}" >"${ARTIFACT_DIR}/count-multiarch-taskruns.log"
@@ -15,11 +15,6 @@ export THRESHOLD
 QUAY_E2E_ORGANIZATION=$(cat /usr/local/ci-secrets/redhat-appstudio-load-test/quay-org)
 MY_GITHUB_ORG=$(cat /usr/local/ci-secrets/redhat-appstudio-load-test/github-org)
 
-
-if [ "${RUN_ON_STAGE:-}" == "true" ]; then
-    ./run-stage-max-concurrency.sh
-else
-    ./run-max-concurrency.sh
-fi
+./run-max-concurrency.sh
 
 popd
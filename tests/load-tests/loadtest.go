@@ -1,8 +1,11 @@
 package main
 
 import "fmt"
+import "os"
+import "path/filepath"
 import "time"
 
+import evaluate "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/evaluate"
 import journey "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey"
 import options "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/options"
 import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
@@ -23,10 +26,53 @@ import klog "k8s.io/klog/v2"
 
 var opts = options.Opts{}
 
+// Exit code of the command, set by commands instead of calling os.Exit so their deferred functions run
+var exitCode int
+
 var rootCmd = &cobra.Command{
 	Use:   "load-test",
 	Short: "Konflux performance test",
 	Long:  `Konflux performance test`,
+	Run: func(cmd *cobra.Command, args []string) {
+		exitCode = runLoadTest()
+	},
+}
+
+var evaluateOutputDir string
+var evaluateSloFile string
+
+var evaluateCmd = &cobra.Command{
+	Use:   "evaluate",
+	Short: "Compute KPI statistics out of load test results",
+	Long:  `Reads load-test-timings.csv and load-test-errors.csv and stores per metric statistics, KPI and error counts to load-test-timings.json, with --slo-file also checks SLOs, stores JUnit report to load-test-slo.xml and exits with non-zero code if any SLO failed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		exitCode = runEvaluate()
+	},
+}
+
+var compareOpts evaluate.CompareOptions
+var compareOutputFile string
+var compareFailOnRegression bool
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <baseline-dir> <candidate-dir>",
+	Short: "Compare load test run with a baseline run",
+	Long:  `Reads load-test-timings.csv of both runs, compares durations of passed measurements metric by metric using Mann-Whitney U test and prints table of regressions and improvements`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		exitCode = runCompare(args[0], args[1])
+	},
+}
+
+var purgeCheckpointFile string
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete resources created by previous load test run",
+	Long:  `Reads checkpoint of previous (possibly crashed) run and deletes exactly the users (or on Stage applications, components, integration test scenarios and releases) and forked repositories it created`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPurge()
+	},
 }
 
 func init() {
@@ -49,29 +95,155 @@ func init() {
 	rootCmd.Flags().BoolVar(&opts.FailFast, "fail-fast", false, "if you want the test to fail fast at first failure")
 	rootCmd.Flags().IntVarP(&opts.Concurrency, "concurrency", "c", 1, "number of concurrent threads to execute")
 	rootCmd.Flags().IntVar(&opts.JourneyRepeats, "journey-repeats", 1, "number of times to repeat user journey (either this or --journey-duration)")
+	rootCmd.Flags().StringVar(&opts.JourneySpecFile, "journey", "", "YAML file with journey steps to run for each application and component (if not set, journey is defined by --waitpipelines and --waitintegrationtestspipelines)")
 	rootCmd.Flags().StringVar(&opts.JourneyDuration, "journey-duration", "1h", "repeat user journey until this timeout (either this or --journey-repeats)")
 	rootCmd.Flags().BoolVar(&opts.PipelineMintmakerDisabled, "pipeline-mintmaker-disabled", true, "if you want to stop Mintmaker to be creating update PRs for your component (default in loadtest different from Konflux default)")
 	rootCmd.Flags().BoolVar(&opts.PipelineRepoTemplating, "pipeline-repo-templating", false, "if we should use in repo template pipelines (merge PaC PR, template repo pipelines and ignore custom pipeline run, e.g. required for multi arch test)")
+	rootCmd.Flags().BoolVar(&opts.Resume, "resume", false, "continue run whose state was checkpointed to load-test-checkpoint.json in --output-dir (e.g. after a crash), users not yet purged are reused")
 	rootCmd.Flags().StringVarP(&opts.OutputDir, "output-dir", "o", ".", "directory where output files such as load-tests.log or load-tests.json are stored")
 	rootCmd.Flags().StringVar(&opts.BuildPipelineSelectorBundle, "build-pipeline-selector-bundle", "", "BuildPipelineSelector bundle to use when testing with build-definition PR")
 	rootCmd.Flags().BoolVarP(&opts.LogInfo, "log-info", "v", false, "log messages with info level and above")
 	rootCmd.Flags().BoolVarP(&opts.LogDebug, "log-debug", "d", false, "log messages with debug level and above")
+	rootCmd.Flags().StringVar(&opts.LoadProfile, "load-profile", options.LoadProfileConstant, "how to apply load: 'constant' (all --concurrency threads at once), 'ramp-up' (start threads evenly over --ramp-up-duration), 'arrival-rate' (start --arrival-rate journeys per minute using pool of --concurrency threads until --journey-duration) or 'step' (start threads per --step-concurrency until SLO is violated)")
+	rootCmd.Flags().DurationVar(&opts.RampUpDuration, "ramp-up-duration", 0, "with 'ramp-up' load profile, time over which all the threads are started")
+	rootCmd.Flags().Float64Var(&opts.ArrivalRate, "arrival-rate", 0, "with 'arrival-rate' load profile, number of journeys to start per minute")
+	rootCmd.Flags().IntSliceVar(&opts.StepConcurrency, "step-concurrency", []int{1, 5, 10, 25, 50, 100, 150, 200}, "with 'step' load profile, concurrency of each step, steps above --concurrency are skipped")
+	rootCmd.Flags().DurationVar(&opts.StepDuration, "step-duration", 30*time.Minute, "with 'step' load profile, how long each step runs before SLO is checked")
+	rootCmd.Flags().StringVar(&opts.SloFile, "slo-file", "", "file with SLOs checked when test is done, one per line like 'validatePipelineRunCondition p90 < 8m', 'createApplication error_rate < 2%' or 'KPI mean < 10m', JUnit report is stored to load-test-slo.xml and test exits with non-zero code if any SLO failed")
+	rootCmd.Flags().StringVar(&opts.SloMetric, "slo-metric", "KPI", "with 'step' load profile, measured function (e.g. 'validatePipelineRunCondition') the SLO is checked on, 'KPI' means sum over all KPI metrics")
+	rootCmd.Flags().Float64Var(&opts.SloPercentile, "slo-percentile", 0, "with 'step' load profile, percentile of SLO metric durations to compare with threshold, 0 means mean")
+	rootCmd.Flags().Float64Var(&opts.SloThreshold, "slo-threshold", 300, "with 'step' load profile, SLO threshold in seconds")
+	rootCmd.Flags().Float64Var(&opts.SloMaxErrorRate, "slo-max-error-rate", 10, "with 'step' load profile, maximal percentage of failed SLO metric measurements")
+	rootCmd.Flags().DurationVar(&opts.ClusterSampleInterval, "cluster-sample-interval", 0, "how often to sample counts of PipelineRuns, TaskRuns and pods by state in user namespaces and restarts of controllers to load-test-cluster.csv, disabled when 0")
+	rootCmd.Flags().StringSliceVar(&opts.ClusterSampleNamespaces, "cluster-sample-namespaces", []string{"application-service", "build-service", "image-controller", "integration-service", "openshift-pipelines", "release-service"}, "namespaces of controllers whose pod restarts are sampled")
+	rootCmd.Flags().StringSliceVar(&opts.RedactPatterns, "redact", []string{}, "regular expressions of secrets to redact from stored measurements, in addition to built-in ones for tokens")
+	rootCmd.Flags().StringVar(&opts.MetricsAddress, "metrics-address", "", "address (e.g. ':9090') where to expose live Prometheus metrics on '/metrics' during the test, disabled when empty")
 	rootCmd.Flags().BoolVarP(&opts.LogTrace, "log-trace", "t", false, "log messages with trace level and above (i.e. everything)")
+
+	evaluateCmd.Flags().StringVarP(&evaluateOutputDir, "output-dir", "o", ".", "directory where load test stored its output files and where to store evaluated results")
+	evaluateCmd.Flags().StringVar(&evaluateSloFile, "slo-file", "", "file with SLOs to check, see load-test --slo-file")
+	rootCmd.AddCommand(evaluateCmd)
+
+	compareCmd.Flags().Float64Var(&compareOpts.Alpha, "alpha", 0.05, "significance level, difference with higher p-value is not reported")
+	compareCmd.Flags().Float64Var(&compareOpts.Threshold, "threshold", 10, "minimal change of median duration in percent to be reported as regression or improvement")
+	compareCmd.Flags().IntVar(&compareOpts.MinSamples, "min-samples", 5, "metrics with fewer passed samples in either run are not compared")
+	compareCmd.Flags().StringSliceVar(&compareOpts.Metrics, "metrics", []string{}, "metrics to compare, all metrics measured in both runs when empty")
+	compareCmd.Flags().StringVarP(&compareOutputFile, "output", "o", "", "file to store comparison to, as CSV if name ends with '.csv', JSON otherwise")
+	compareCmd.Flags().BoolVar(&compareFailOnRegression, "fail-on-regression", false, "exit with non-zero code if any metric regressed")
+	rootCmd.AddCommand(compareCmd)
+
+	purgeCmd.Flags().StringVar(&purgeCheckpointFile, "from-checkpoint", "", "checkpoint file (load-test-checkpoint.json) written by previous run to the --output-dir")
+	_ = purgeCmd.MarkFlagRequired("from-checkpoint")
+	rootCmd.AddCommand(purgeCmd)
 }
 
 func main() {
-	var err error
-
-	// Setup argument parser
-	err = rootCmd.Execute()
+	// Setup argument parser and run the command
+	err := rootCmd.Execute()
 	if err != nil {
 		klog.Fatalln(err)
 	}
-	if rootCmd.Flags().Lookup("help").Value.String() == "true" {
-		fmt.Println(rootCmd.UsageString())
-		return
+	os.Exit(exitCode)
+}
+
+// Evaluate results of a finished load test, returns non-zero exit code if any SLO failed
+func runEvaluate() int {
+	var slos []evaluate.SLO
+	if evaluateSloFile != "" {
+		var err error
+		slos, err = evaluate.LoadSLOFile(evaluateSloFile)
+		if err != nil {
+			klog.Fatalf("Failed to load SLOs: %v", err)
+		}
+	}
+
+	result, err := evaluate.EvaluateFiles(
+		filepath.Join(evaluateOutputDir, "load-test-timings.csv"),
+		filepath.Join(evaluateOutputDir, "load-test-errors.csv"),
+		filepath.Join(evaluateOutputDir, "load-test-timings.json"),
+		evaluate.KPIMetrics,
+	)
+	if err != nil {
+		klog.Fatalf("Failed to evaluate results: %v", err)
+	}
+
+	fmt.Printf("KPI mean: %v\n", result.KPI.Mean)
+	fmt.Printf("KPI errors: %d\n", result.KPI.Errors)
+
+	if evaluateSloFile != "" && !checkSlos(evaluateOutputDir, slos) {
+		return 1
+	}
+	return 0
+}
+
+// Check SLOs on results stored in output directory, returns false if any SLO failed
+func checkSlos(outputDir string, slos []evaluate.SLO) bool {
+	results, err := evaluate.CheckSLOFiles(outputDir, slos)
+	if err != nil {
+		klog.Fatalf("Failed to check SLOs: %v", err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+		fmt.Println(r.String())
+	}
+	fmt.Printf("SLOs failed: %d of %d\n", failed, len(results))
+	return failed == 0
+}
+
+// Compare candidate load test run with baseline run, returns non-zero exit code on regression when requested
+func runCompare(baselineDir, candidateDir string) int {
+	comparisons, err := evaluate.CompareDirs(baselineDir, candidateDir, compareOpts)
+	if err != nil {
+		klog.Fatalf("Failed to compare runs: %v", err)
+	}
+
+	err = evaluate.WriteCompareTable(os.Stdout, comparisons)
+	if err != nil {
+		klog.Fatalf("Failed to print comparison: %v", err)
+	}
+
+	if compareOutputFile != "" {
+		err = evaluate.WriteCompareFile(compareOutputFile, comparisons)
+		if err != nil {
+			klog.Fatalf("Failed to store comparison: %v", err)
+		}
+	}
+
+	regressions := 0
+	for _, c := range comparisons {
+		if c.Verdict == evaluate.VerdictRegression {
+			regressions++
+		}
+	}
+	fmt.Printf("Regressions: %d of %d metrics\n", regressions, len(comparisons))
+	if compareFailOnRegression && regressions > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Purge resources recorded in checkpoint of previous run
+func runPurge() {
+	logging.Logger.Level = logging.INFO
+
+	cp, err := journey.LoadCheckpoint(purgeCheckpointFile)
+	if err != nil {
+		klog.Fatalf("Failed to load checkpoint: %v", err)
+	}
+
+	err = journey.PurgeCheckpoint(cp)
+	if err != nil {
+		klog.Fatalf("Purging failed: %v", err)
 	}
-	err = opts.ProcessOptions()
+}
+
+// Run the load test, returns non-zero exit code if any SLO failed
+func runLoadTest() int {
+	err := opts.ProcessOptions()
 	if err != nil {
 		logging.Logger.Fatal("Failed to process options: %v", err)
 	}
@@ -88,31 +260,75 @@ func main() {
 		logging.Logger.Level = logging.TRACE
 	}
 
+	// Setup redaction of secrets in stored measurements
+	err = logging.AddRedactPatterns(opts.RedactPatterns)
+	if err != nil {
+		logging.Logger.Fatal("Failed to setup redaction: %v", err)
+	}
+
 	// Show test options
 	logging.Logger.Debug("Options: %+v", opts)
 
+	// Load SLOs now so a typo does not surface only after the whole test
+	var slos []evaluate.SLO
+	if opts.SloFile != "" {
+		slos, err = evaluate.LoadSLOFile(opts.SloFile)
+		if err != nil {
+			logging.Logger.Fatal("Failed to load SLOs: %v", err)
+		}
+	}
+
+	// Load journey steps
+	spec, err := journey.LoadJourneySpec(&opts)
+	if err != nil {
+		logging.Logger.Fatal("Failed to load journey: %v", err)
+	}
+	logging.Logger.Debug("Journey: %+v", spec)
+
 	// Tier up measurements logger
 	logging.MeasurementsStart(opts.OutputDir)
 
+	// Expose live metrics if requested
+	if opts.MetricsAddress != "" {
+		err = logging.MetricsStart(opts.MetricsAddress)
+		if err != nil {
+			logging.Logger.Fatal("Failed to expose metrics: %v", err)
+		}
+		defer logging.MetricsStop()
+	}
+
 	// Start given number of `perUserThread()` threads using `journey.Setup()` and wait for them to finish
-	_, err = logging.Measure(journey.Setup, perUserThread, &opts)
+	_, err = logging.Measure(nil, "Setup", nil, func() (string, error) {
+		return journey.Setup(perUserThread, &opts, spec)
+	})
 	if err != nil {
 		logging.Logger.Fatal("Threads setup failed: %v", err)
 	}
 
 	// Cleanup resources
-	_, err = logging.Measure(journey.Purge)
+	err = logging.MeasureErr(nil, "Purge", nil, journey.Purge)
 	if err != nil {
 		logging.Logger.Error("Purging failed: %v", err)
 	}
 
+	// Return Stage users to the pool and report its health
+	journey.CloseUserPool(opts.OutputDir)
+
 	// Tier down measurements logger
 	logging.MeasurementsStop()
+
+	// Judge the results so CI can gate on performance regressions
+	if opts.SloFile != "" && !checkSlos(opts.OutputDir, slos) {
+		return 1
+	}
+	return 0
 }
 
 // Single user journey
 func perUserThread(threadCtx *journey.MainContext) {
 	defer threadCtx.ThreadsWG.Done()
+	defer threadCtx.Span.End(nil)
+	defer logging.TrackThread("user")()
 
 	var err error
 
@@ -193,24 +409,31 @@ func perUserThread(threadCtx *journey.MainContext) {
 	//watcher.Stop()
 	//os.Exit(10)
 
-	for threadCtx.JourneyRepeatsCounter = 1; threadCtx.JourneyRepeatsCounter <= threadCtx.Opts.JourneyRepeats; threadCtx.JourneyRepeatsCounter++ {
+	// Load profile decides how many times and when we repeat the journey, resumed run continues where it ended
+	for threadCtx.JourneyRepeatsCounter = threadCtx.JourneyRepeatsDone + 1; threadCtx.NextIteration(); threadCtx.JourneyRepeatsCounter++ {
 
 		// Start given number of `perApplicationThread()` threads using `journey.PerApplicationSetup()` and wait for them to finish
-		_, err = logging.Measure(journey.PerApplicationSetup, perApplicationThread, threadCtx)
+		_, err = logging.Measure(threadCtx.Span, "PerApplicationSetup", logging.Labels{"iteration": fmt.Sprint(threadCtx.JourneyRepeatsCounter)}, func() (string, error) {
+			return journey.PerApplicationSetup(perApplicationThread, threadCtx)
+		})
 		if err != nil {
 			logging.Logger.Fatal("Per application threads setup failed: %v", err)
 		}
-
-		// Check if we are supposed to quit based on --journey-duration
-		if time.Now().UTC().After(threadCtx.Opts.JourneyUntil) {
-			logging.Logger.Debug("Done with user journey because of timeout")
-			break
-		}
+		logging.JourneyIterationDone()
 
 	}
 
 	// Collect info about PVCs
-	_, err = logging.Measure(journey.HandlePersistentVolumeClaim, threadCtx)
+	err = logging.MeasureErr(threadCtx.Span, "HandlePersistentVolumeClaim", nil, func() error {
+		return journey.HandlePersistentVolumeClaim(threadCtx)
+	})
+	if err != nil {
+		logging.Logger.Error("Thread failed: %v", err)
+		return
+	}
+
+	// Run journey steps that are done once per user (e.g. purge)
+	err = journey.RunUserSteps(threadCtx)
 	if err != nil {
 		logging.Logger.Error("Thread failed: %v", err)
 		return
@@ -221,32 +444,31 @@ func perUserThread(threadCtx *journey.MainContext) {
 // Single application journey (there can be multiple parallel apps per user)
 func perApplicationThread(perApplicationCtx *journey.PerApplicationContext) {
 	defer perApplicationCtx.PerApplicationWG.Done()
+	defer perApplicationCtx.Span.End(nil)
+	defer logging.TrackThread("application")()
 
 	var err error
 
 	// Create framework so we do not have to share framework with parent thread
-	_, err = logging.Measure(journey.HandleNewFrameworkForApp, perApplicationCtx)
+	err = logging.MeasureErr(perApplicationCtx.Span, "HandleNewFrameworkForApp", nil, func() error {
+		return journey.HandleNewFrameworkForApp(perApplicationCtx)
+	})
 	if err != nil {
 		logging.Logger.Error("Per application thread failed: %v", err)
 		return
 	}
 
-	// Create application
-	_, err = logging.Measure(journey.HandleApplication, perApplicationCtx)
-	if err != nil {
-		logging.Logger.Error("Thread failed: %v", err)
-		return
-	}
-
-	// Create integration test scenario
-	_, err = logging.Measure(journey.HandleIntegrationTestScenario, perApplicationCtx)
+	// Run journey steps of application level (e.g. create application and integration test scenario)
+	err = journey.RunApplicationSteps(perApplicationCtx)
 	if err != nil {
 		logging.Logger.Error("Thread failed: %v", err)
 		return
 	}
 
 	// Start given number of `perComponentThread()` threads using `journey.PerComponentSetup()` and wait for them to finish
-	_, err = logging.Measure(journey.PerComponentSetup, perComponentThread, perApplicationCtx)
+	_, err = logging.Measure(perApplicationCtx.Span, "PerComponentSetup", nil, func() (string, error) {
+		return journey.PerComponentSetup(perComponentThread, perApplicationCtx)
+	})
 	if err != nil {
 		logging.Logger.Fatal("Per component threads setup failed: %v", err)
 	}
@@ -256,8 +478,12 @@ func perApplicationThread(perApplicationCtx *journey.PerApplicationContext) {
 // Single component journey (there can be multiple parallel comps per app)
 func perComponentThread(perComponentCtx *journey.PerComponentContext) {
 	defer perComponentCtx.PerComponentWG.Done()
+	defer perComponentCtx.Span.End(nil)
+	defer logging.TrackThread("component")()
 	defer func() {
-		_, err := logging.Measure(journey.HandlePerComponentCollection, perComponentCtx)
+		err := logging.MeasureErr(perComponentCtx.Span, "HandlePerComponentCollection", nil, func() error {
+			return journey.HandlePerComponentCollection(perComponentCtx)
+		})
 		if err != nil {
 			logging.Logger.Error("Per component thread failed: %v", err)
 		}
@@ -266,28 +492,16 @@ func perComponentThread(perComponentCtx *journey.PerComponentContext) {
 	var err error
 
 	// Create framework so we do not have to share framework with parent thread
-	_, err = logging.Measure(journey.HandleNewFrameworkForComp, perComponentCtx)
-	if err != nil {
-		logging.Logger.Error("Per component thread failed: %v", err)
-		return
-	}
-
-	// Create component
-	_, err = logging.Measure(journey.HandleComponent, perComponentCtx)
-	if err != nil {
-		logging.Logger.Error("Per component thread failed: %v", err)
-		return
-	}
-
-	// Wait for build pipiline run
-	_, err = logging.Measure(journey.HandlePipelineRun, perComponentCtx)
+	err = logging.MeasureErr(perComponentCtx.Span, "HandleNewFrameworkForComp", nil, func() error {
+		return journey.HandleNewFrameworkForComp(perComponentCtx)
+	})
 	if err != nil {
 		logging.Logger.Error("Per component thread failed: %v", err)
 		return
 	}
 
-	// Wait for test pipiline run
-	_, err = logging.Measure(journey.HandleTest, perComponentCtx)
+	// Run journey steps of component level (e.g. create component, wait for build and tests)
+	err = journey.RunComponentSteps(perComponentCtx)
 	if err != nil {
 		logging.Logger.Error("Per component thread failed: %v", err)
 		return
@@ -0,0 +1,246 @@
+package evaluate
+
+import "encoding/csv"
+import "encoding/json"
+import "fmt"
+import "io"
+import "math"
+import "os"
+import "path/filepath"
+import "sort"
+import "strconv"
+import "strings"
+import "text/tabwriter"
+
+// Verdicts of metric comparison
+const (
+	VerdictRegression   = "regression"
+	VerdictImprovement  = "improvement"
+	VerdictNoChange     = "no change"
+	VerdictInsufficient = "insufficient data"
+)
+
+// Thresholds deciding when a difference between runs is a regression
+type CompareOptions struct {
+	Alpha      float64  // significance level of Mann-Whitney U test
+	Threshold  float64  // minimal change of median in percent to be reported
+	MinSamples int      // metrics with fewer passed samples in either run are not compared
+	Metrics    []string // metrics to compare, all metrics measured in both runs when empty
+}
+
+// Comparison of one metric between baseline and candidate run
+type MetricComparison struct {
+	Metric           string  `json:"metric"`
+	BaselineSamples  int     `json:"baselineSamples"`
+	CandidateSamples int     `json:"candidateSamples"`
+	BaselineMedian   float64 `json:"baselineMedian"`
+	CandidateMedian  float64 `json:"candidateMedian"`
+	Change           float64 `json:"change"` // change of median in percent
+	U                float64 `json:"u"`
+	PValue           float64 `json:"pValue"`
+	Verdict          string  `json:"verdict"`
+}
+
+// Read durations of passed measurements per metric out of timings CSV, old full function names are shortened
+func ReadSamples(timings io.Reader) (map[string][]float64, error) {
+	samples := map[string][]float64{}
+	reader := csv.NewReader(timings)
+	reader.FieldsPerRecord = -1
+	line := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("Error reading timings CSV: %v", err)
+		}
+		if len(row) <= columnError {
+			continue
+		}
+		if row[columnError] != "<nil>" {
+			continue
+		}
+		duration, err := strconv.ParseFloat(row[columnDuration], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Row %d of timings CSV has invalid duration: %v", line, err)
+		}
+		metric := row[columnMetric]
+		if strings.Contains(metric, "/") {
+			metric = metric[strings.LastIndex(metric, ".")+1:]
+		}
+		samples[metric] = append(samples[metric], duration)
+	}
+	return samples, nil
+}
+
+// Mann-Whitney U statistic of x and two-sided p-value using normal approximation with tie and continuity correction
+func MannWhitney(x, y []float64) (float64, float64) {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 == 0 || n2 == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	type value struct {
+		v     float64
+		first bool
+	}
+	all := make([]value, 0, len(x)+len(y))
+	for _, v := range x {
+		all = append(all, value{v, true})
+	}
+	for _, v := range y {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Average ranks of ties, remember tie sizes for variance correction
+	rankSum, ties := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	u := rankSum - n1*(n1+1)/2
+	n := n1 + n2
+	mean := n1 * n2 / 2
+	sd := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sd == 0 {
+		return u, 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / sd
+	if z < 0 {
+		z = 0
+	}
+	return u, math.Erfc(z / math.Sqrt2)
+}
+
+func median(data []float64) float64 {
+	sorted := append([]float64{}, data...)
+	sort.Float64s(sorted)
+	return Percentile(sorted, 50)
+}
+
+// Compare samples of baseline and candidate run metric by metric
+func Compare(baseline, candidate map[string][]float64, opts CompareOptions) []MetricComparison {
+	metrics := opts.Metrics
+	if len(metrics) == 0 {
+		for m := range baseline {
+			if _, ok := candidate[m]; ok {
+				metrics = append(metrics, m)
+			}
+		}
+		sort.Strings(metrics)
+	}
+
+	var comparisons []MetricComparison
+	for _, m := range metrics {
+		c := MetricComparison{Metric: m, BaselineSamples: len(baseline[m]), CandidateSamples: len(candidate[m]), Verdict: VerdictInsufficient}
+		if c.BaselineSamples == 0 || c.CandidateSamples == 0 {
+			comparisons = append(comparisons, c)
+			continue
+		}
+		c.BaselineMedian = median(baseline[m])
+		c.CandidateMedian = median(candidate[m])
+		if c.BaselineMedian != 0 {
+			c.Change = (c.CandidateMedian - c.BaselineMedian) / c.BaselineMedian * 100
+		}
+		c.U, c.PValue = MannWhitney(baseline[m], candidate[m])
+
+		switch {
+		case c.BaselineSamples < opts.MinSamples || c.CandidateSamples < opts.MinSamples:
+		case c.PValue < opts.Alpha && c.Change > opts.Threshold:
+			c.Verdict = VerdictRegression
+		case c.PValue < opts.Alpha && c.Change < -opts.Threshold:
+			c.Verdict = VerdictImprovement
+		default:
+			c.Verdict = VerdictNoChange
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons
+}
+
+// Print comparison as a table
+func WriteCompareTable(w io.Writer, comparisons []MetricComparison) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tBASELINE\tCANDIDATE\tMEDIAN [s]\tCHANGE\tP-VALUE\tVERDICT")
+	for _, c := range comparisons {
+		if c.BaselineSamples == 0 || c.CandidateSamples == 0 {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t-\t-\t-\t%s\n", c.Metric, c.BaselineSamples, c.CandidateSamples, c.Verdict)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f -> %.2f\t%+.1f%%\t%.4f\t%s\n", c.Metric, c.BaselineSamples, c.CandidateSamples, c.BaselineMedian, c.CandidateMedian, c.Change, c.PValue, c.Verdict)
+	}
+	return tw.Flush()
+}
+
+// Store comparison to JSON or, when file name ends with ".csv", CSV file
+func WriteCompareFile(path string, comparisons []MetricComparison) error {
+	var data []byte
+	var err error
+	if strings.HasSuffix(path, ".csv") {
+		var b strings.Builder
+		writer := csv.NewWriter(&b)
+		_ = writer.Write([]string{"metric", "baseline_samples", "candidate_samples", "baseline_median", "candidate_median", "change", "u", "p_value", "verdict"})
+		for _, c := range comparisons {
+			_ = writer.Write([]string{
+				c.Metric,
+				strconv.Itoa(c.BaselineSamples),
+				strconv.Itoa(c.CandidateSamples),
+				fmt.Sprintf("%f", c.BaselineMedian),
+				fmt.Sprintf("%f", c.CandidateMedian),
+				fmt.Sprintf("%f", c.Change),
+				fmt.Sprintf("%f", c.U),
+				fmt.Sprintf("%f", c.PValue),
+				c.Verdict,
+			})
+		}
+		writer.Flush()
+		data, err = []byte(b.String()), writer.Error()
+	} else {
+		data, err = json.MarshalIndent(comparisons, "", "    ")
+	}
+	if err != nil {
+		return fmt.Errorf("Error marshalling comparison: %v", err)
+	}
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		return fmt.Errorf("Error writing to file: %v", err)
+	}
+	return nil
+}
+
+// Compare timings of load test runs stored in given output directories
+func CompareDirs(baselineDir, candidateDir string, opts CompareOptions) ([]MetricComparison, error) {
+	read := func(dir string) (map[string][]float64, error) {
+		fd, err := os.Open(filepath.Join(filepath.Clean(dir), "load-test-timings.csv"))
+		if err != nil {
+			return nil, fmt.Errorf("Error opening timings file: %v", err)
+		}
+		defer fd.Close()
+		return ReadSamples(fd)
+	}
+
+	baseline, err := read(baselineDir)
+	if err != nil {
+		return nil, err
+	}
+	candidate, err := read(candidateDir)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(baseline, candidate, opts), nil
+}
@@ -0,0 +1,56 @@
+package evaluate
+
+import "bytes"
+import "strings"
+import "testing"
+
+import "github.com/stretchr/testify/assert"
+
+func TestMannWhitney(t *testing.T) {
+	// Same as scipy.stats.mannwhitneyu(x, y, method="asymptotic")
+	u, p := MannWhitney([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10})
+	assert.Equal(t, 0.0, u)
+	assert.InDelta(t, 0.01219, p, 1e-5)
+
+	u, p = MannWhitney([]float64{1, 2, 2, 3}, []float64{2, 3, 3, 4})
+	assert.Equal(t, 3.0, u)
+	assert.InDelta(t, 0.17203, p, 1e-5)
+
+	_, p = MannWhitney([]float64{5, 5}, []float64{5, 5})
+	assert.Equal(t, 1.0, p)
+}
+
+func TestCompare(t *testing.T) {
+	samples, err := ReadSamples(strings.NewReader(timingsCSV))
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 20, 30, 40}, samples["HandleUser"])
+	assert.Equal(t, []float64{1.5}, samples["createApplication"])
+
+	baseline := map[string][]float64{
+		"fast": {10, 11, 12, 13, 14, 15},
+		"slow": {10, 11, 12, 13, 14, 15},
+		"same": {10, 11, 12, 13, 14, 15},
+		"few":  {10, 11},
+		"gone": {10, 11},
+	}
+	candidate := map[string][]float64{
+		"fast": {5, 6, 5, 6, 5, 6},
+		"slow": {20, 21, 22, 23, 24, 25},
+		"same": {11, 12, 13, 14, 15, 10},
+		"few":  {20, 21},
+	}
+	comparisons := Compare(baseline, candidate, CompareOptions{Alpha: 0.05, Threshold: 10, MinSamples: 5})
+	var verdicts []string
+	for _, c := range comparisons {
+		verdicts = append(verdicts, c.Metric+": "+c.Verdict)
+	}
+	assert.Equal(t, []string{"fast: improvement", "few: insufficient data", "same: no change", "slow: regression"}, verdicts)
+	assert.InDelta(t, 80.0, comparisons[3].Change, 1e-9)
+
+	comparisons = Compare(baseline, candidate, CompareOptions{Metrics: []string{"gone"}})
+	assert.Equal(t, VerdictInsufficient, comparisons[0].Verdict)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCompareTable(&buf, comparisons))
+	assert.Contains(t, buf.String(), "gone    2         0          -           -       -        insufficient data")
+}
@@ -0,0 +1,376 @@
+package evaluate
+
+import "bytes"
+import "encoding/csv"
+import "encoding/json"
+import "fmt"
+import "io"
+import "math"
+import "os"
+import "path/filepath"
+import "sort"
+import "strconv"
+import "strings"
+import "time"
+
+// Column indexes in timings CSV written by logging.MeasurementEntry
+const (
+	columnWhen = iota
+	columnMetric
+	columnDuration
+	columnParams
+	columnError
+)
+
+// Column indexes in errors CSV written by logging.ErrorEntry
+const (
+	columnErrorWhen = iota
+	columnErrorCode
+	columnErrorMessage
+)
+
+// Metrics we care about that together form KPI metric duration
+var KPIMetrics = []string{
+	"HandleUser",
+	"createApplication",
+	"validateApplication",
+	"createIntegrationTestScenario",
+	"validateIntegrationTestScenario",
+	"createComponent",
+	"validatePipelineRunCreation",
+	"validatePipelineRunCondition",
+	"validatePipelineRunSignature",
+	"validateSnapshotCreation",
+	"validateTestPipelineRunCreation",
+	"validateTestPipelineRunCondition",
+}
+
+// Statistics of durations (in seconds) of metric samples, only sample count is set when there are no samples
+type DurationStats struct {
+	Samples int      `json:"samples"`
+	Min     *float64 `json:"min,omitempty"`
+	Mean    *float64 `json:"mean,omitempty"`
+	Max     *float64 `json:"max,omitempty"`
+	P50     *float64 `json:"p50,omitempty"`
+	P90     *float64 `json:"p90,omitempty"`
+	P99     *float64 `json:"p99,omitempty"`
+}
+
+// Statistics of times when metric samples were taken, empty when there are no samples
+type WhenStats struct {
+	Min  string   `json:"min,omitempty"`
+	Max  string   `json:"max,omitempty"`
+	Mean string   `json:"mean,omitempty"`
+	Span *float64 `json:"span,omitempty"`
+}
+
+type SampleStats struct {
+	Duration DurationStats `json:"duration"`
+	When     WhenStats     `json:"when"`
+}
+
+type MetricStats struct {
+	Pass      SampleStats `json:"pass"`
+	Fail      SampleStats `json:"fail"`
+	ErrorRate *float64    `json:"error_rate"` // null when there are no samples
+}
+
+type KPIStats struct {
+	Mean   float64 `json:"mean"` // sum of mean durations of all KPI metrics, -1 if some metric has no passed samples
+	Errors int     `json:"errors"`
+}
+
+// Failures logged by logging.Logger.Fail, counted by error code
+type ErrorStats struct {
+	Total int         `json:"total"`
+	Codes map[int]int `json:"codes"`
+}
+
+// Evaluated load test results
+type Result struct {
+	Metrics []string // order of metrics in the output
+	Stats   map[string]MetricStats
+	KPI     KPIStats
+	Errors  ErrorStats
+}
+
+// Serialize in the same shape 'evaluate.py' used: metrics first, then "KPI" and "ERRORS"
+func (r *Result) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	write := func(key string, value interface{}) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if buf.Len() > 1 {
+			buf.WriteString(",")
+		}
+		keyData, _ := json.Marshal(key)
+		buf.Write(keyData)
+		buf.WriteString(":")
+		buf.Write(data)
+		return nil
+	}
+	for _, m := range r.Metrics {
+		if err := write(m, r.Stats[m]); err != nil {
+			return nil, err
+		}
+	}
+	if err := write("KPI", r.KPI); err != nil {
+		return nil, err
+	}
+	if err := write("ERRORS", r.Errors); err != nil {
+		return nil, err
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+type samples struct {
+	durations []float64
+	whens     []time.Time
+}
+
+// Compute statistics of given metrics out of timings CSV and failures CSV (errors can be nil),
+// KPI covers these of given metrics which are KPI metrics
+func Evaluate(timings, errors io.Reader, metrics []string) (*Result, error) {
+	passed := map[string]*samples{}
+	failed := map[string]*samples{}
+	for _, m := range metrics {
+		passed[m] = &samples{}
+		failed[m] = &samples{}
+	}
+
+	reader := csv.NewReader(timings)
+	reader.FieldsPerRecord = -1
+	line := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("Error reading timings CSV: %v", err)
+		}
+		if len(row) == 0 || (len(row) == 1 && row[0] == "") {
+			continue
+		}
+		if len(row) <= columnError {
+			return nil, fmt.Errorf("Row %d of timings CSV has %d columns, expected %d", line, len(row), columnError+1)
+		}
+
+		when, err := time.Parse(time.RFC3339Nano, row[columnWhen])
+		if err != nil {
+			return nil, fmt.Errorf("Row %d of timings CSV has invalid timestamp: %v", line, err)
+		}
+		duration, err := strconv.ParseFloat(row[columnDuration], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Row %d of timings CSV has invalid duration: %v", line, err)
+		}
+
+		for _, m := range metrics {
+			if !MetricMatches(row[columnMetric], m) {
+				continue
+			}
+			s := passed[m]
+			if row[columnError] != "<nil>" {
+				s = failed[m]
+			}
+			s.durations = append(s.durations, duration)
+			s.whens = append(s.whens, when)
+		}
+	}
+
+	result := &Result{
+		Metrics: metrics,
+		Stats:   map[string]MetricStats{},
+	}
+	for _, m := range metrics {
+		stats := MetricStats{
+			Pass: SampleStats{Duration: durationStats(passed[m].durations), When: whenStats(passed[m].whens)},
+			Fail: SampleStats{Duration: durationStats(failed[m].durations), When: whenStats(failed[m].whens)},
+		}
+
+		total := stats.Pass.Duration.Samples + stats.Fail.Duration.Samples
+		if total > 0 {
+			rate := float64(stats.Fail.Duration.Samples) / float64(total)
+			stats.ErrorRate = &rate
+		}
+		result.Stats[m] = stats
+
+		if !isKPIMetric(m) {
+			continue
+		}
+
+		// If we had 0 passed measurements in some metric, that means not a single build made it
+		// through all steps, so KPI mean does not make sense as it would only cover part of the journey
+		if stats.Pass.Duration.Samples == 0 {
+			result.KPI.Mean = -1
+		} else if result.KPI.Mean != -1 {
+			result.KPI.Mean += *stats.Pass.Duration.Mean
+		}
+		result.KPI.Errors += stats.Fail.Duration.Samples
+	}
+
+	var err error
+	result.Errors, err = errorStats(errors)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func errorStats(errors io.Reader) (ErrorStats, error) {
+	stats := ErrorStats{Codes: map[int]int{}}
+	if errors == nil {
+		return stats, nil
+	}
+
+	reader := csv.NewReader(errors)
+	reader.FieldsPerRecord = -1
+	line := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return stats, fmt.Errorf("Error reading errors CSV: %v", err)
+		}
+		if len(row) <= columnErrorCode {
+			continue
+		}
+		code, err := strconv.Atoi(row[columnErrorCode])
+		if err != nil {
+			return stats, fmt.Errorf("Row %d of errors CSV has invalid error code: %v", line, err)
+		}
+		stats.Codes[code]++
+		stats.Total++
+	}
+
+	return stats, nil
+}
+
+func durationStats(data []float64) DurationStats {
+	stats := DurationStats{Samples: len(data)}
+	if len(data) == 0 {
+		return stats
+	}
+
+	sorted := append([]float64{}, data...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, d := range sorted {
+		sum += d
+	}
+	mean := sum / float64(len(sorted))
+	p50, p90, p99 := Percentile(sorted, 50), Percentile(sorted, 90), Percentile(sorted, 99)
+
+	stats.Min = &sorted[0]
+	stats.Max = &sorted[len(sorted)-1]
+	stats.Mean = &mean
+	stats.P50, stats.P90, stats.P99 = &p50, &p90, &p99
+	return stats
+}
+
+func isKPIMetric(name string) bool {
+	for _, m := range KPIMetrics {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if measured metric is the given one, older timings have metrics named by full function name, e.g. "github.com/.../journey.HandleUser"
+func MetricMatches(metric, name string) bool {
+	return metric == name || strings.HasSuffix(metric, "."+name)
+}
+
+// Percentile of sorted data, linearly interpolated between closest ranks
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func whenStats(data []time.Time) WhenStats {
+	if len(data) == 0 {
+		return WhenStats{}
+	}
+
+	min, max := data[0], data[0]
+	sum := 0.0
+	for _, t := range data {
+		if t.Before(min) {
+			min = t
+		}
+		if t.After(max) {
+			max = t
+		}
+		sum += float64(t.UnixMicro()) / 1e6
+	}
+	mean := time.UnixMicro(int64(math.Round(sum / float64(len(data)) * 1e6))).UTC()
+	if len(data) == 1 {
+		mean = data[0]
+	}
+	span := max.Sub(min).Seconds()
+
+	return WhenStats{
+		Min:  isoFormat(min),
+		Max:  isoFormat(max),
+		Mean: isoFormat(mean),
+		Span: &span,
+	}
+}
+
+// Format time the way Python's datetime.isoformat() does
+func isoFormat(t time.Time) string {
+	if t.Nanosecond()/1000 == 0 {
+		return t.Format("2006-01-02T15:04:05-07:00")
+	}
+	return t.Format("2006-01-02T15:04:05.000000-07:00")
+}
+
+// Evaluate timings and errors CSV files written by load test and store results as JSON to output file
+func EvaluateFiles(timingsFile, errorsFile, outputFile string, metrics []string) (*Result, error) {
+	timings, err := os.Open(filepath.Clean(timingsFile))
+	if err != nil {
+		return nil, fmt.Errorf("Error opening timings file: %v", err)
+	}
+	defer timings.Close()
+
+	var errors io.Reader
+	errorsFd, err := os.Open(filepath.Clean(errorsFile))
+	if err == nil {
+		defer errorsFd.Close()
+		errors = errorsFd
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Error opening errors file: %v", err)
+	}
+
+	result, err := Evaluate(timings, errors, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling results: %v", err)
+	}
+	err = os.WriteFile(outputFile, data, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Error writing to file: %v", err)
+	}
+
+	return result, nil
+}
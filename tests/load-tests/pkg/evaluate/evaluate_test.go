@@ -0,0 +1,68 @@
+package evaluate
+
+import "encoding/json"
+import "strings"
+import "testing"
+
+import "github.com/stretchr/testify/assert"
+
+const timingsCSV = `2024-05-01T10:00:00.5+02:00,github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey.HandleUser,10.000000,,<nil>
+2024-05-01T10:00:10+02:00,github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey.HandleUser,20.000000,,<nil>
+2024-05-01T10:00:20+02:00,github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey.HandleUser,30.000000,,<nil>
+2024-05-01T10:00:30+02:00,github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey.HandleUser,40.000000,,<nil>
+
+2024-05-01T10:01:00+02:00,github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey.createApplication,1.500000,,<nil>
+2024-05-01T10:02:00+02:00,github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey.createApplication,60.000000,,Unable to create the Application
+2024-05-01T10:03:00+02:00,github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey.HandleApplication,61.500000,,<nil>
+`
+
+const errorsCSV = `2024-05-01T10:02:00+02:00,30,FAIL(30): Application failed creation
+2024-05-01T10:02:00+02:00,30,FAIL(30): Application failed creation
+2024-05-01T10:05:00+02:00,83,FAIL(83): Test Pipeline Run failed run
+`
+
+func TestEvaluate(t *testing.T) {
+	result, err := Evaluate(strings.NewReader(timingsCSV), strings.NewReader(errorsCSV), []string{"HandleUser", "createApplication"})
+	assert.NoError(t, err)
+
+	user := result.Stats["HandleUser"]
+	assert.Equal(t, 4, user.Pass.Duration.Samples)
+	assert.Equal(t, 10.0, *user.Pass.Duration.Min)
+	assert.Equal(t, 25.0, *user.Pass.Duration.Mean)
+	assert.Equal(t, 25.0, *user.Pass.Duration.P50)
+	assert.InDelta(t, 37.0, *user.Pass.Duration.P90, 1e-9)
+	assert.Equal(t, "2024-05-01T10:00:00.500000+02:00", user.Pass.When.Min)
+	assert.Equal(t, 29.5, *user.Pass.When.Span)
+	assert.Equal(t, 0.0, *user.ErrorRate)
+
+	app := result.Stats["createApplication"]
+	assert.Equal(t, 1, app.Pass.Duration.Samples)
+	assert.Equal(t, 1, app.Fail.Duration.Samples)
+	assert.Equal(t, 0.5, *app.ErrorRate)
+
+	assert.Equal(t, KPIStats{Mean: 26.5, Errors: 1}, result.KPI)
+	assert.Equal(t, ErrorStats{Total: 3, Codes: map[int]int{30: 2, 83: 1}}, result.Errors)
+
+	data, err := json.Marshal(result)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), `{"HandleUser":{"pass":{"duration":{"samples":4,`))
+	assert.Contains(t, string(data), `"fail":{"duration":{"samples":0},"when":{}},"error_rate":0}`)
+	assert.True(t, strings.HasSuffix(string(data), `"KPI":{"mean":26.5,"errors":1},"ERRORS":{"total":3,"codes":{"30":2,"83":1}}}`))
+}
+
+func TestEvaluateMissingMetric(t *testing.T) {
+	result, err := Evaluate(strings.NewReader(timingsCSV), nil, []string{"HandleUser", "validateApplication"})
+	assert.NoError(t, err)
+	assert.Equal(t, -1.0, result.KPI.Mean)
+	assert.Nil(t, result.Stats["validateApplication"].ErrorRate)
+	assert.Equal(t, 0, result.Errors.Total)
+
+	_, err = Evaluate(strings.NewReader("2024-05-01,metric,abc,,<nil>\n"), nil, KPIMetrics)
+	assert.Error(t, err)
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, 5.0, Percentile([]float64{5}, 99))
+	assert.Equal(t, 1.5, Percentile([]float64{1, 2}, 50))
+	assert.Equal(t, 2.0, Percentile([]float64{1, 2}, 100))
+}
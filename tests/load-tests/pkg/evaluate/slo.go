@@ -0,0 +1,283 @@
+package evaluate
+
+import "bufio"
+import "encoding/xml"
+import "fmt"
+import "io"
+import "os"
+import "path/filepath"
+import "strconv"
+import "strings"
+import "time"
+
+// Service level objective on one statistic of a metric, written in SLO file as e.g.
+// "validatePipelineRunCondition p90 < 8m", "createApplication error_rate < 2%" or "KPI mean < 5m"
+type SLO struct {
+	Line      string  // as written in SLO file
+	Metric    string  // measured function or "KPI"
+	Stat      string  // min, mean, max, p50, p90, p99, samples, error_rate; for KPI mean, errors or error_rate
+	Op        string  // <, <=, > or >=
+	Threshold float64 // seconds for durations, fraction for error rate, count for samples and errors
+}
+
+// Outcome of SLO check
+type SLOResult struct {
+	SLO    SLO
+	Value  *float64 // nil when there was nothing to compute the statistic from
+	Passed bool
+}
+
+var durationSLOStats = map[string]bool{"min": true, "mean": true, "max": true, "p50": true, "p90": true, "p99": true}
+
+// Parse SLOs, one per line, empty lines and lines starting with '#' are ignored
+func ParseSLOs(r io.Reader) ([]SLO, error) {
+	var slos []SLO
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		slo, err := parseSLO(text)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d of SLO file: %v", line, err)
+		}
+		slos = append(slos, slo)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Error reading SLO file: %v", err)
+	}
+	return slos, nil
+}
+
+func parseSLO(text string) (SLO, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 4 {
+		return SLO{}, fmt.Errorf("expected '<metric> <statistic> <operator> <threshold>', got %q", text)
+	}
+	slo := SLO{Line: text, Metric: fields[0], Stat: fields[1], Op: fields[2]}
+
+	switch slo.Op {
+	case "<", "<=", ">", ">=":
+	default:
+		return slo, fmt.Errorf("unknown operator %q", slo.Op)
+	}
+
+	var err error
+	threshold := fields[3]
+	switch {
+	case slo.Stat == "error_rate":
+		if !strings.HasSuffix(threshold, "%") {
+			return slo, fmt.Errorf("error rate threshold %q must be in percent, e.g. '2%%'", threshold)
+		}
+		slo.Threshold, err = strconv.ParseFloat(strings.TrimSuffix(threshold, "%"), 64)
+		slo.Threshold /= 100
+	case slo.Stat == "samples" || (slo.Metric == "KPI" && slo.Stat == "errors"):
+		slo.Threshold, err = strconv.ParseFloat(threshold, 64)
+	case durationSLOStats[slo.Stat] && (slo.Metric != "KPI" || slo.Stat == "mean"):
+		var d time.Duration
+		d, err = time.ParseDuration(threshold)
+		slo.Threshold = d.Seconds()
+	default:
+		return slo, fmt.Errorf("unknown statistic %q of metric %s", slo.Stat, slo.Metric)
+	}
+	if err != nil {
+		return slo, fmt.Errorf("invalid threshold %q: %v", threshold, err)
+	}
+	return slo, nil
+}
+
+// Load SLOs from given file
+func LoadSLOFile(path string) ([]SLO, error) {
+	fd, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("Error opening SLO file: %v", err)
+	}
+	defer fd.Close()
+	return ParseSLOs(fd)
+}
+
+// KPI metrics and all the metrics SLOs are defined on, to be passed to Evaluate
+func SLOMetrics(slos []SLO) []string {
+	metrics := append([]string{}, KPIMetrics...)
+	for _, slo := range slos {
+		known := slo.Metric == "KPI"
+		for _, m := range metrics {
+			known = known || m == slo.Metric
+		}
+		if !known {
+			metrics = append(metrics, slo.Metric)
+		}
+	}
+	return metrics
+}
+
+// Check SLOs against evaluated results, statistic which can not be computed fails the SLO
+func CheckSLOs(result *Result, slos []SLO) []SLOResult {
+	var results []SLOResult
+	for _, slo := range slos {
+		r := SLOResult{SLO: slo, Value: sloValue(result, slo)}
+		if r.Value != nil {
+			v := *r.Value
+			switch slo.Op {
+			case "<":
+				r.Passed = v < slo.Threshold
+			case "<=":
+				r.Passed = v <= slo.Threshold
+			case ">":
+				r.Passed = v > slo.Threshold
+			case ">=":
+				r.Passed = v >= slo.Threshold
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func sloValue(result *Result, slo SLO) *float64 {
+	if slo.Metric == "KPI" {
+		return kpiValue(result, slo.Stat)
+	}
+
+	stats, ok := result.Stats[slo.Metric]
+	if !ok {
+		return nil
+	}
+	d := stats.Pass.Duration
+	switch slo.Stat {
+	case "min":
+		return d.Min
+	case "mean":
+		return d.Mean
+	case "max":
+		return d.Max
+	case "p50":
+		return d.P50
+	case "p90":
+		return d.P90
+	case "p99":
+		return d.P99
+	case "samples":
+		samples := float64(d.Samples)
+		return &samples
+	case "error_rate":
+		return stats.ErrorRate
+	}
+	return nil
+}
+
+func kpiValue(result *Result, stat string) *float64 {
+	switch stat {
+	case "mean":
+		if result.KPI.Mean == -1 {
+			return nil
+		}
+		mean := result.KPI.Mean
+		return &mean
+	case "errors":
+		errors := float64(result.KPI.Errors)
+		return &errors
+	case "error_rate":
+		total := 0
+		for _, m := range KPIMetrics {
+			stats := result.Stats[m]
+			total += stats.Pass.Duration.Samples + stats.Fail.Duration.Samples
+		}
+		if total == 0 {
+			return nil
+		}
+		rate := float64(result.KPI.Errors) / float64(total)
+		return &rate
+	}
+	return nil
+}
+
+// Human readable description of the outcome
+func (r SLOResult) String() string {
+	format := func(v float64) string {
+		switch {
+		case r.SLO.Stat == "error_rate":
+			return fmt.Sprintf("%.2f%%", v*100)
+		case r.SLO.Stat == "samples" || r.SLO.Stat == "errors":
+			return fmt.Sprintf("%.0f", v)
+		default:
+			return time.Duration(v * float64(time.Second)).Round(time.Millisecond).String()
+		}
+	}
+	verdict := "PASS"
+	if !r.Passed {
+		verdict = "FAIL"
+	}
+	if r.Value == nil {
+		return fmt.Sprintf("%s: %s (no data)", verdict, r.SLO.Line)
+	}
+	return fmt.Sprintf("%s: %s (actual %s)", verdict, r.SLO.Line, format(*r.Value))
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Write SLO results as JUnit XML report with one test case per SLO
+func WriteJUnit(w io.Writer, results []SLOResult) error {
+	suite := junitTestSuite{Name: "load-test-slo", Tests: len(results), Timestamp: time.Now().UTC().Format(time.RFC3339)}
+	for _, r := range results {
+		tc := junitTestCase{Name: r.SLO.Line, Classname: r.SLO.Metric}
+		if !r.Passed {
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: r.String(), Text: r.String()}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "    ")
+	if err != nil {
+		return fmt.Errorf("Error marshalling JUnit report: %v", err)
+	}
+	_, err = io.WriteString(w, xml.Header+string(data)+"\n")
+	return err
+}
+
+// Evaluate results stored in output directory, check SLOs and store JUnit report there
+func CheckSLOFiles(outputDir string, slos []SLO) ([]SLOResult, error) {
+	result, err := EvaluateFiles(
+		filepath.Join(outputDir, "load-test-timings.csv"),
+		filepath.Join(outputDir, "load-test-errors.csv"),
+		filepath.Join(outputDir, "load-test-timings.json"),
+		SLOMetrics(slos),
+	)
+	if err != nil {
+		return nil, err
+	}
+	results := CheckSLOs(result, slos)
+
+	fd, err := os.Create(filepath.Join(outputDir, "load-test-slo.xml"))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating JUnit report: %v", err)
+	}
+	defer fd.Close()
+	return results, WriteJUnit(fd, results)
+}
@@ -0,0 +1,54 @@
+package evaluate
+
+import "bytes"
+import "strings"
+import "testing"
+
+import "github.com/stretchr/testify/assert"
+
+const sloFile = `# Nightly gate
+HandleUser max < 60s
+HandleUser p90 <= 1m
+createApplication error_rate < 2%
+HandleApplication samples >= 1
+KPI mean < 5m
+validateApplication mean < 1m
+`
+
+func TestParseSLOs(t *testing.T) {
+	slos, err := ParseSLOs(strings.NewReader(sloFile))
+	assert.NoError(t, err)
+	assert.Len(t, slos, 6)
+	assert.Equal(t, SLO{Line: "HandleUser max < 60s", Metric: "HandleUser", Stat: "max", Op: "<", Threshold: 60}, slos[0])
+	assert.Equal(t, 0.02, slos[2].Threshold)
+	assert.Equal(t, 300.0, slos[4].Threshold)
+	assert.Equal(t, append(append([]string{}, KPIMetrics...), "HandleApplication"), SLOMetrics(slos))
+
+	for _, line := range []string{"HandleUser p90 < 8", "HandleUser p95 < 8m", "HandleUser max = 8m", "HandleUser error_rate < 0.02", "KPI max < 5m", "HandleUser max"} {
+		_, err = ParseSLOs(strings.NewReader(line))
+		assert.Error(t, err, line)
+	}
+}
+
+func TestCheckSLOs(t *testing.T) {
+	slos, err := ParseSLOs(strings.NewReader(sloFile))
+	assert.NoError(t, err)
+	result, err := Evaluate(strings.NewReader(timingsCSV), nil, SLOMetrics(slos))
+	assert.NoError(t, err)
+
+	results := CheckSLOs(result, slos)
+	var passed []bool
+	for _, r := range results {
+		passed = append(passed, r.Passed)
+	}
+	assert.Equal(t, []bool{true, true, false, true, false, false}, passed)
+	assert.Equal(t, "FAIL: createApplication error_rate < 2% (actual 50.00%)", results[2].String())
+	assert.Equal(t, "PASS: HandleUser max < 60s (actual 40s)", results[0].String())
+	assert.Equal(t, "FAIL: validateApplication mean < 1m (no data)", results[5].String())
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJUnit(&buf, results))
+	assert.Contains(t, buf.String(), `<testsuite name="load-test-slo" tests="6" failures="3"`)
+	assert.Contains(t, buf.String(), `<testcase name="HandleUser max &lt; 60s" classname="HandleUser"></testcase>`)
+	assert.Contains(t, buf.String(), `<failure message="FAIL: createApplication error_rate &lt; 2% (actual 50.00%)">`)
+}
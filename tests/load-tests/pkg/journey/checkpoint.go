@@ -0,0 +1,216 @@
+package journey
+
+import "encoding/json"
+import "fmt"
+import "os"
+import "path/filepath"
+import "sync"
+import "time"
+
+import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+import options "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/options"
+
+// Name of the checkpoint file in output directory
+const CheckpointFile = "load-test-checkpoint.json"
+
+// State of the load test run persisted to output directory whenever something
+// is created, so a crashed run can be resumed or the resources purged
+type Checkpoint struct {
+	path    string
+	lock    sync.Mutex
+	Started time.Time         `json:"started"`
+	Updated time.Time         `json:"updated"`
+	Stage   bool              `json:"stage"`
+	Users   []*CheckpointUser `json:"users"`
+}
+
+type CheckpointUser struct {
+	ThreadIndex        int                      `json:"threadIndex"`
+	Username           string                   `json:"username"`
+	Namespace          string                   `json:"namespace"`
+	ComponentRepoUrl   string                   `json:"componentRepoUrl,omitempty"` // forked repository
+	JourneyRepeatsDone int                      `json:"journeyRepeatsDone"`
+	Purged             bool                     `json:"purged"`
+	Applications       []*CheckpointApplication `json:"applications,omitempty"`
+}
+
+type CheckpointApplication struct {
+	Name                     string                 `json:"name"`
+	Iteration                int                    `json:"iteration"`
+	IntegrationTestScenarios []string               `json:"integrationTestScenarios,omitempty"`
+	Components               []*CheckpointComponent `json:"components,omitempty"`
+}
+
+type CheckpointComponent struct {
+	Name     string   `json:"name"`
+	Releases []string `json:"releases,omitempty"`
+}
+
+// Checkpoint of the current run, nil when not checkpointing
+var checkpoint *Checkpoint
+
+// Load checkpoint from given file
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading checkpoint file: %v", err)
+	}
+	cp := &Checkpoint{}
+	err = json.Unmarshal(data, cp)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing checkpoint file %s: %v", path, err)
+	}
+	cp.path = path
+	return cp, nil
+}
+
+// Start checkpointing to output directory, with '--resume' continue with existing checkpoint
+func openCheckpoint(opts *options.Opts) (*Checkpoint, error) {
+	path := filepath.Join(opts.OutputDir, CheckpointFile)
+
+	if opts.Resume {
+		cp, err := LoadCheckpoint(path)
+		if err != nil {
+			return nil, err
+		}
+		if cp.Stage != opts.Stage {
+			return nil, fmt.Errorf("Checkpoint %s was created with stage=%v, can not resume with stage=%v", path, cp.Stage, opts.Stage)
+		}
+		return cp, nil
+	}
+
+	// Do not overwrite checkpoint of previous run, it might be needed for purging
+	if info, err := os.Stat(path); err == nil {
+		backup := filepath.Join(opts.OutputDir, fmt.Sprintf("load-test-checkpoint.%s.json", info.ModTime().UTC().Format("20060102T150405")))
+		logging.Logger.Warning("Moving checkpoint of previous run to %s, use --resume to continue previous run instead", backup)
+		err = os.Rename(path, backup)
+		if err != nil {
+			return nil, fmt.Errorf("Error moving checkpoint file: %v", err)
+		}
+	}
+
+	cp := &Checkpoint{path: path, Started: time.Now().UTC(), Stage: opts.Stage}
+	return cp, cp.save()
+}
+
+// Write checkpoint to a temporary file and rename it, so the file is always complete
+func (c *Checkpoint) save() error {
+	c.Updated = time.Now().UTC()
+	data, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return fmt.Errorf("Error marshalling checkpoint: %v", err)
+	}
+	tmp := c.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("Error writing to file: %v", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// Apply change to the checkpoint and persist it, does nothing when not checkpointing
+func updateCheckpoint(change func(c *Checkpoint)) {
+	c := checkpoint
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	change(c)
+	err := c.save()
+	if err != nil {
+		logging.Logger.Error("Failed to save checkpoint: %v", err)
+	}
+}
+
+func (c *Checkpoint) user(threadIndex int) *CheckpointUser {
+	for _, u := range c.Users {
+		if u.ThreadIndex == threadIndex {
+			return u
+		}
+	}
+	u := &CheckpointUser{ThreadIndex: threadIndex}
+	c.Users = append(c.Users, u)
+	return u
+}
+
+func (u *CheckpointUser) application(name string) *CheckpointApplication {
+	for _, a := range u.Applications {
+		if a.Name == name {
+			return a
+		}
+	}
+	a := &CheckpointApplication{Name: name}
+	u.Applications = append(u.Applications, a)
+	return a
+}
+
+func (a *CheckpointApplication) component(name string) *CheckpointComponent {
+	for _, c := range a.Components {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &CheckpointComponent{Name: name}
+	a.Components = append(a.Components, c)
+	return c
+}
+
+// Restore state of user thread from checkpoint of previous run, users already purged start from scratch
+func (c *Checkpoint) restore(ctx *MainContext) {
+	for _, u := range c.Users {
+		if u.ThreadIndex != ctx.ThreadIndex || u.Purged {
+			continue
+		}
+		logging.Logger.Info("Resuming user %s after %d journey repeats", u.Username, u.JourneyRepeatsDone)
+		ctx.Username = u.Username
+		ctx.ComponentRepoUrl = u.ComponentRepoUrl
+		ctx.JourneyRepeatsDone = u.JourneyRepeatsDone
+	}
+}
+
+// Record state of user thread
+func checkpointUser(ctx *MainContext) {
+	updateCheckpoint(func(c *Checkpoint) {
+		u := c.user(ctx.ThreadIndex)
+		if u.Purged && !ctx.Purged {
+			*u = CheckpointUser{ThreadIndex: ctx.ThreadIndex} // purged user is being created again
+		}
+		u.Username = ctx.Username
+		u.Namespace = ctx.Namespace
+		u.ComponentRepoUrl = ctx.ComponentRepoUrl
+		u.JourneyRepeatsDone = ctx.JourneyRepeatsDone
+		u.Purged = ctx.Purged
+	})
+}
+
+// Record created application
+func checkpointApplication(ctx *PerApplicationContext) {
+	updateCheckpoint(func(c *Checkpoint) {
+		a := c.user(ctx.ParentContext.ThreadIndex).application(ctx.ApplicationName)
+		a.Iteration = ctx.ParentContext.JourneyRepeatsCounter
+	})
+}
+
+// Record created integration test scenario
+func checkpointIntegrationTestScenario(ctx *PerApplicationContext, name string) {
+	updateCheckpoint(func(c *Checkpoint) {
+		a := c.user(ctx.ParentContext.ThreadIndex).application(ctx.ApplicationName)
+		a.IntegrationTestScenarios = append(a.IntegrationTestScenarios, name)
+	})
+}
+
+// Record created component
+func checkpointComponent(ctx *PerComponentContext) {
+	updateCheckpoint(func(c *Checkpoint) {
+		c.user(ctx.ParentContext.ParentContext.ThreadIndex).application(ctx.ParentContext.ApplicationName).component(ctx.ComponentName)
+	})
+}
+
+// Record created release
+func checkpointRelease(ctx *PerComponentContext, name string) {
+	updateCheckpoint(func(c *Checkpoint) {
+		comp := c.user(ctx.ParentContext.ParentContext.ThreadIndex).application(ctx.ParentContext.ApplicationName).component(ctx.ComponentName)
+		comp.Releases = append(comp.Releases, name)
+	})
+}
@@ -0,0 +1,65 @@
+package journey
+
+import "os"
+import "path/filepath"
+import "testing"
+
+import options "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/options"
+
+import "github.com/stretchr/testify/assert"
+
+func TestCheckpoint(t *testing.T) {
+	opts := &options.Opts{OutputDir: t.TempDir()}
+	var err error
+	checkpoint, err = openCheckpoint(opts)
+	assert.NoError(t, err)
+	defer func() { checkpoint = nil }()
+
+	user := &MainContext{ThreadIndex: 3, Username: "user-3", Namespace: "user-3-tenant", Opts: opts}
+	checkpointUser(user)
+	user.ComponentRepoUrl = "https://github.com/org/repo-user-3"
+	checkpointUser(user)
+	user.JourneyRepeatsCounter = 1
+	app := &PerApplicationContext{ParentContext: user, ApplicationName: "app-1"}
+	checkpointApplication(app)
+	checkpointIntegrationTestScenario(app, "its-1")
+	comp := &PerComponentContext{ParentContext: app, ComponentName: "comp-1"}
+	checkpointComponent(comp)
+	checkpointRelease(comp, "rel-1")
+	user.JourneyRepeatsDone = 1
+	checkpointUser(user)
+
+	cp, err := LoadCheckpoint(filepath.Join(opts.OutputDir, CheckpointFile))
+	assert.NoError(t, err)
+	assert.Len(t, cp.Users, 1)
+	u := cp.Users[0]
+	assert.Equal(t, "user-3-tenant", u.Namespace)
+	assert.Equal(t, 1, u.JourneyRepeatsDone)
+	assert.Equal(t, []string{"its-1"}, u.Applications[0].IntegrationTestScenarios)
+	assert.Equal(t, 1, u.Applications[0].Iteration)
+	assert.Equal(t, []string{"rel-1"}, u.Applications[0].Components[0].Releases)
+
+	// Resumed thread continues where it ended
+	resumed := &MainContext{ThreadIndex: 3}
+	cp.restore(resumed)
+	assert.Equal(t, "user-3", resumed.Username)
+	assert.Equal(t, "https://github.com/org/repo-user-3", resumed.ComponentRepoUrl)
+	assert.Equal(t, 1, resumed.JourneyRepeatsDone)
+
+	// Purged user starts from scratch
+	user.Purged = true
+	checkpointUser(user)
+	cp, err = LoadCheckpoint(filepath.Join(opts.OutputDir, CheckpointFile))
+	assert.NoError(t, err)
+	resumed = &MainContext{ThreadIndex: 3}
+	cp.restore(resumed)
+	assert.Equal(t, "", resumed.Username)
+
+	// New run keeps checkpoint of previous one
+	_, err = openCheckpoint(opts)
+	assert.NoError(t, err)
+	files, _ := filepath.Glob(filepath.Join(opts.OutputDir, "load-test-checkpoint.*.json"))
+	assert.Len(t, files, 1)
+	_, err = os.Stat(filepath.Join(opts.OutputDir, CheckpointFile))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,201 @@
+package journey
+
+import "encoding/csv"
+import "fmt"
+import "os"
+import "path/filepath"
+import "sort"
+import "strconv"
+import "time"
+
+import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+import options "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/options"
+
+import corev1 "k8s.io/api/core/v1"
+import apis "knative.dev/pkg/apis"
+
+// Name of the cluster samples CSV in output directory
+const ClusterSamplesFile = "load-test-cluster.csv"
+
+// One value of the cluster state time series
+type clusterSample struct {
+	Timestamp time.Time
+	Resource  string // "PipelineRun", "TaskRun", "Pod" or "ControllerRestarts"
+	State     string // run state, pod phase or controller namespace
+	Value     int
+}
+
+func (s clusterSample) GetSliceOfStrings() []string {
+	return []string{s.Timestamp.Format(time.RFC3339Nano), s.Resource, s.State, strconv.Itoa(s.Value)}
+}
+
+// Periodically samples state of user namespaces and controllers while the test runs
+type clusterSampler struct {
+	contexts             []*MainContext
+	controllerNamespaces []string
+	output               string
+	restartsForbidden    bool // stop trying to sample controllers when we can not access them (e.g. on Stage)
+	stop                 chan struct{}
+	done                 chan struct{}
+}
+
+var sampler *clusterSampler
+
+// Start sampling cluster state with '--cluster-sample-interval', users have to be initialized already
+func startClusterSampling(opts *options.Opts, contexts []*MainContext) {
+	if opts.ClusterSampleInterval <= 0 {
+		return
+	}
+	sampler = &clusterSampler{
+		contexts:             contexts,
+		controllerNamespaces: opts.ClusterSampleNamespaces,
+		output:               filepath.Join(opts.OutputDir, ClusterSamplesFile),
+		stop:                 make(chan struct{}),
+		done:                 make(chan struct{}),
+	}
+	go sampler.run(opts.ClusterSampleInterval)
+	logging.Logger.Info("Sampling cluster state every %v to %s", opts.ClusterSampleInterval, sampler.output)
+}
+
+// Take last sample and stop sampling cluster state
+func stopClusterSampling() {
+	if sampler == nil {
+		return
+	}
+	close(sampler.stop)
+	<-sampler.done
+	sampler = nil
+}
+
+func (s *clusterSampler) run(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.write(s.sample())
+		select {
+		case <-s.stop:
+			s.write(s.sample())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// State of Tekton run as shown by 'tkn', based on its Succeeded condition
+func runState(condition *apis.Condition) string {
+	switch {
+	case condition == nil:
+		return "Pending"
+	case condition.IsTrue():
+		return "Succeeded"
+	case condition.IsFalse():
+		return "Failed"
+	default:
+		return "Running"
+	}
+}
+
+// Restarts of all containers of given pods
+func podRestarts(pods []corev1.Pod) int {
+	restarts := 0
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += int(status.RestartCount)
+		}
+	}
+	return restarts
+}
+
+func (s *clusterSampler) sample() []clusterSample {
+	now := time.Now().UTC()
+	counts := map[string]map[string]int{"PipelineRun": {}, "TaskRun": {}, "Pod": {}}
+
+	for _, ctx := range s.contexts {
+		if ctx.Framework == nil || ctx.Namespace == "" {
+			continue
+		}
+		tekton := ctx.Framework.AsKubeAdmin.TektonController
+		prs, err := tekton.ListAllPipelineRuns(ctx.Namespace)
+		if err != nil {
+			logging.Logger.Debug("Failed to sample PipelineRuns in %s: %v", ctx.Namespace, err)
+		} else {
+			for _, pr := range prs.Items {
+				counts["PipelineRun"][runState(pr.Status.GetCondition(apis.ConditionSucceeded))]++
+			}
+		}
+		trs, err := tekton.ListAllTaskRuns(ctx.Namespace)
+		if err != nil {
+			logging.Logger.Debug("Failed to sample TaskRuns in %s: %v", ctx.Namespace, err)
+		} else {
+			for _, tr := range trs.Items {
+				counts["TaskRun"][runState(tr.Status.GetCondition(apis.ConditionSucceeded))]++
+			}
+		}
+		pods, err := ctx.Framework.AsKubeAdmin.CommonController.ListAllPods(ctx.Namespace)
+		if err != nil {
+			logging.Logger.Debug("Failed to sample pods in %s: %v", ctx.Namespace, err)
+		} else {
+			for _, pod := range pods.Items {
+				counts["Pod"][string(pod.Status.Phase)]++
+			}
+		}
+	}
+
+	var samples []clusterSample
+	for _, resource := range []string{"PipelineRun", "TaskRun", "Pod"} {
+		var states []string
+		for state := range counts[resource] {
+			states = append(states, state)
+		}
+		sort.Strings(states)
+		for _, state := range states {
+			samples = append(samples, clusterSample{Timestamp: now, Resource: resource, State: state, Value: counts[resource][state]})
+		}
+	}
+
+	return append(samples, s.sampleControllers(now)...)
+}
+
+func (s *clusterSampler) sampleControllers(now time.Time) []clusterSample {
+	if s.restartsForbidden || len(s.contexts) == 0 || s.contexts[0].Framework == nil {
+		return nil
+	}
+	var samples []clusterSample
+	for _, ns := range s.controllerNamespaces {
+		pods, err := s.contexts[0].Framework.AsKubeAdmin.CommonController.ListAllPods(ns)
+		if err != nil {
+			logging.Logger.Warning("Not sampling controller restarts as we failed to list pods in %s: %v", ns, err)
+			s.restartsForbidden = true
+			return nil
+		}
+		samples = append(samples, clusterSample{Timestamp: now, Resource: "ControllerRestarts", State: ns, Value: podRestarts(pods.Items)})
+	}
+	return samples
+}
+
+// Append samples to the CSV file
+func (s *clusterSampler) write(samples []clusterSample) {
+	err := writeClusterSamples(s.output, samples)
+	if err != nil {
+		logging.Logger.Error("Failed to store cluster samples: %v", err)
+	}
+}
+
+func writeClusterSamples(path string, samples []clusterSample) error {
+	fd, err := os.OpenFile(filepath.Clean(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Error opening file: %v", err)
+	}
+	defer fd.Close()
+
+	writer := csv.NewWriter(fd)
+	for _, sample := range samples {
+		err = writer.Write(sample.GetSliceOfStrings())
+		if err != nil {
+			return fmt.Errorf("Error writing to file: %v", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,32 @@
+package journey
+
+import "os"
+import "path/filepath"
+import "testing"
+import "time"
+
+import "github.com/stretchr/testify/assert"
+
+import corev1 "k8s.io/api/core/v1"
+import apis "knative.dev/pkg/apis"
+
+func TestClusterSampling(t *testing.T) {
+	assert.Equal(t, "Pending", runState(nil))
+	assert.Equal(t, "Running", runState(&apis.Condition{Status: corev1.ConditionUnknown}))
+	assert.Equal(t, "Succeeded", runState(&apis.Condition{Status: corev1.ConditionTrue}))
+	assert.Equal(t, "Failed", runState(&apis.Condition{Status: corev1.ConditionFalse}))
+
+	pods := []corev1.Pod{
+		{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{RestartCount: 2}, {RestartCount: 1}}}},
+		{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{RestartCount: 4}}}},
+	}
+	assert.Equal(t, 7, podRestarts(pods))
+
+	path := filepath.Join(t.TempDir(), ClusterSamplesFile)
+	when := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	assert.NoError(t, writeClusterSamples(path, []clusterSample{{when, "PipelineRun", "Running", 3}}))
+	assert.NoError(t, writeClusterSamples(path, []clusterSample{{when, "ControllerRestarts", "build-service", 7}}))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-05-01T10:00:00Z,PipelineRun,Running,3\n2024-05-01T10:00:00Z,ControllerRestarts,build-service,7\n", string(data))
+}
@@ -16,9 +16,8 @@ func createApplication(f *framework.Framework, namespace string, timeout time.Du
 	return nil
 }
 
-func validateApplication(f *framework.Framework, name, namespace string) error {
+func validateApplication(f *framework.Framework, name, namespace string, timeout time.Duration) error {
 	interval := time.Second * 20
-	timeout := time.Minute * 15
 
 	// TODO It would be much better to watch this resource for a condition
 	err := utils.WaitUntilWithInterval(func() (done bool, err error) {
@@ -34,28 +33,23 @@ func validateApplication(f *framework.Framework, name, namespace string) error {
 	return err
 }
 
-func HandleApplication(ctx *PerApplicationContext) error {
+func HandleApplication(ctx *PerApplicationContext, step *Step) error {
 	var err error
+	start := time.Now()
 
 	logging.Logger.Debug("Creating application %s in namespace %s", ctx.ApplicationName, ctx.ParentContext.Namespace)
 
-	_, err = logging.Measure(
-		createApplication,
-		ctx.Framework,
-		ctx.ParentContext.Namespace,
-		time.Minute*60,
-		ctx.ApplicationName,
-	)
+	err = logging.MeasureErr(ctx.Span, "createApplication", nil, func() error {
+		return createApplication(ctx.Framework, ctx.ParentContext.Namespace, remaining(start, step), ctx.ApplicationName)
+	})
 	if err != nil {
 		return logging.Logger.Fail(30, "Application failed creation: %v", err)
 	}
+	checkpointApplication(ctx)
 
-	_, err = logging.Measure(
-		validateApplication,
-		ctx.Framework,
-		ctx.ApplicationName,
-		ctx.ParentContext.Namespace,
-	)
+	err = logging.MeasureErr(ctx.Span, "validateApplication", nil, func() error {
+		return validateApplication(ctx.Framework, ctx.ApplicationName, ctx.ParentContext.Namespace, remaining(start, step))
+	})
 	if err != nil {
 		return logging.Logger.Fail(31, "Application failed validation: %v", err)
 	}
@@ -31,8 +31,8 @@ func getPRNumberFromPRUrl(prUrl string) (int, error) {
 	return prNumber, nil
 }
 
-// Get PR URL from PaC component annotation "build.appstudio.openshift.io/status"
-func getPaCPull(annotations map[string]string) (string, error) {
+// Get PR URL and time when PaC was configured from PaC component annotation "build.appstudio.openshift.io/status"
+func getPaCPull(annotations map[string]string) (string, time.Time, error) {
 	var buildStatusAnn string = "build.appstudio.openshift.io/status"
 	var buildStatusValue string
 	var buildStatusMap map[string]interface{}
@@ -40,13 +40,13 @@ func getPaCPull(annotations map[string]string) (string, error) {
 	// Get annotation we are interested in
 	buildStatusValue, exists := annotations[buildStatusAnn]
 	if !exists {
-		return "", nil
+		return "", time.Time{}, nil
 	}
 
 	// Parse JSON
 	err := json.Unmarshal([]byte(buildStatusValue), &buildStatusMap)
 	if err != nil {
-		return "", fmt.Errorf("Error unmarshalling JSON: %v", err)
+		return "", time.Time{}, fmt.Errorf("Error unmarshalling JSON: %v", err)
 	}
 
 	// Access the nested value using type assertion
@@ -59,24 +59,30 @@ func getPaCPull(annotations map[string]string) (string, error) {
 		// Check "state" is "enabled"
 		if data, ok = pac["state"].(string); ok {
 			if data != "enabled" {
-				return "", fmt.Errorf("Incorrect state: %s", buildStatusValue)
+				return "", time.Time{}, fmt.Errorf("Incorrect state: %s", buildStatusValue)
 			}
 		} else {
-			return "", fmt.Errorf("Failed parsing state: %s", buildStatusValue)
+			return "", time.Time{}, fmt.Errorf("Failed parsing state: %s", buildStatusValue)
+		}
+
+		// Get "configuration-time", it is not essential so ignore when missing
+		var configured time.Time
+		if data, ok = pac["configuration-time"].(string); ok {
+			configured, _ = time.Parse(time.RFC1123, data)
 		}
 
 		// Get "merge-url"
 		if data, ok = pac["merge-url"].(string); ok {
-			return data, nil
+			return data, configured, nil
 		} else {
-			return "", fmt.Errorf("Failed parsing state: %s", buildStatusValue)
+			return "", time.Time{}, fmt.Errorf("Failed parsing state: %s", buildStatusValue)
 		}
 	} else {
-		return "", fmt.Errorf("Failed parsing: %s", buildStatusValue)
+		return "", time.Time{}, fmt.Errorf("Failed parsing: %s", buildStatusValue)
 	}
 }
 
-func createComponent(f *framework.Framework, namespace, name, repoUrl, repoRevision, containerContext, containerFile, buildPipelineSelector, appName string, mintmakerDisabled bool) error {
+func createComponent(f *framework.Framework, namespace, name, repoUrl, repoRevision, containerContext, containerFile, buildPipelineSelector, appName string, mintmakerDisabled bool) (*appstudioApi.Component, error) {
 	// Prepare annotations to add to component
 	annotationsMap := constants.DefaultDockerBuildPipelineBundle
 	if buildPipelineSelector != "" {
@@ -104,18 +110,24 @@ func createComponent(f *framework.Framework, namespace, name, repoUrl, repoRevis
 		},
 	}
 
-	_, err := f.AsKubeDeveloper.HasController.CreateComponent(componentObj, namespace, "", "", appName, false, annotationsMap)
+	comp, err := f.AsKubeDeveloper.HasController.CreateComponent(componentObj, namespace, "", "", appName, false, annotationsMap)
 	if err != nil {
-		return fmt.Errorf("Unable to create the Component %s: %v", name, err)
+		return nil, fmt.Errorf("Unable to create the Component %s: %v", name, err)
 	}
-	return nil
+	return comp, nil
+}
+
+// PaC pull request opened for the component
+type pacPull struct {
+	Number     int
+	Configured time.Time // zero when not known
 }
 
-func getPaCPullNumber(f *framework.Framework, namespace, name string) (int, error) {
+func getPaCPullNumber(f *framework.Framework, namespace, name string, timeout time.Duration) (pacPull, error) {
 	interval := time.Second * 20
-	timeout := time.Minute * 15
 	var comp *appstudioApi.Component
 	var pull string
+	var configured time.Time
 	var pullNumber int
 
 	// TODO It would be much better to watch this resource for a condition
@@ -127,7 +139,7 @@ func getPaCPullNumber(f *framework.Framework, namespace, name string) (int, erro
 		}
 
 		// Check for right annotation
-		pull, err = getPaCPull(comp.Annotations)
+		pull, configured, err = getPaCPull(comp.Annotations)
 		if err != nil {
 			return false, fmt.Errorf("PaC component %s in namespace %s failed on PR annotation: %v", name, namespace, err)
 		}
@@ -139,24 +151,23 @@ func getPaCPullNumber(f *framework.Framework, namespace, name string) (int, erro
 		return true, nil
 	}, interval, timeout)
 	if err != nil {
-		return -1, fmt.Errorf("Unable to get PaC pull number for component %s in namespace %s: %v", name, namespace, err)
+		return pacPull{Number: -1}, fmt.Errorf("Unable to get PaC pull number for component %s in namespace %s: %v", name, namespace, err)
 	}
 
 	// Get merge request number
 	pullNumber, err = getPRNumberFromPRUrl(pull)
 	if err != nil {
-		return -1, fmt.Errorf("Parsing merge request number failed: %+v", err)
+		return pacPull{Number: -1}, fmt.Errorf("Parsing merge request number failed: %+v", err)
 	}
 
-	return pullNumber, err
+	return pacPull{Number: pullNumber, Configured: configured}, err
 }
 
-func listPipelineRunsWithTimeout(f *framework.Framework, namespace, appName, compName, sha string, expectedCount int) (*[]pipeline.PipelineRun, error) {
+func listPipelineRunsWithTimeout(f *framework.Framework, namespace, appName, compName, sha string, expectedCount int, timeout time.Duration) (*[]pipeline.PipelineRun, error) {
 	var prs *[]pipeline.PipelineRun
 	var err error
 
 	interval := time.Second * 20
-	timeout := time.Minute * 60
 
 	err = utils.WaitUntilWithInterval(func() (done bool, err error) {
 		prs, err = f.AsKubeDeveloper.HasController.GetComponentPipelineRunsWithType(compName, appName, namespace, "build", sha)
@@ -178,11 +189,11 @@ func listPipelineRunsWithTimeout(f *framework.Framework, namespace, appName, com
 	return prs, nil
 }
 
-func listAndDeletePipelineRunsWithTimeout(f *framework.Framework, namespace, appName, compName, sha string, expectedCount int) error {
+func listAndDeletePipelineRunsWithTimeout(f *framework.Framework, namespace, appName, compName, sha string, expectedCount int, timeout time.Duration) error {
 	var prs *[]pipeline.PipelineRun
 	var err error
 
-	prs, err = listPipelineRunsWithTimeout(f, namespace, appName, compName, sha, expectedCount)
+	prs, err = listPipelineRunsWithTimeout(f, namespace, appName, compName, sha, expectedCount, timeout)
 	if err != nil {
 		return err
 	}
@@ -198,12 +209,12 @@ func listAndDeletePipelineRunsWithTimeout(f *framework.Framework, namespace, app
 }
 
 // This handles post-component creation tasks for multi-arch PaC workflow
-func utilityRepoTemplatingComponentCleanup(f *framework.Framework, namespace, appName, compName, repoUrl, repoRev string, mergeReqNum int, placeholders *map[string]string) error {
+func utilityRepoTemplatingComponentCleanup(f *framework.Framework, namespace, appName, compName, repoUrl, repoRev string, mergeReqNum int, placeholders *map[string]string, deadline time.Time) error {
 	var repoName string
 	var err error
 
 	// Delete on-pull-request default pipeline run
-	err = listAndDeletePipelineRunsWithTimeout(f, namespace, appName, compName, "", 1)
+	err = listAndDeletePipelineRunsWithTimeout(f, namespace, appName, compName, "", 1, time.Until(deadline))
 	if err != nil {
 		return fmt.Errorf("Error deleting on-pull-request default PipelineRun in namespace %s: %v", namespace, err)
 	}
@@ -221,7 +232,7 @@ func utilityRepoTemplatingComponentCleanup(f *framework.Framework, namespace, ap
 	logging.Logger.Debug("Repo-templating workflow: Merged PR %d in %s", mergeReqNum, repoName)
 
 	// Delete all pipeline runs as we do not care about these
-	err = listAndDeletePipelineRunsWithTimeout(f, namespace, appName, compName, "", 1)
+	err = listAndDeletePipelineRunsWithTimeout(f, namespace, appName, compName, "", 1, time.Until(deadline))
 	if err != nil {
 		return fmt.Errorf("Error deleting on-push merged PipelineRun in namespace %s: %v", namespace, err)
 	}
@@ -237,7 +248,7 @@ func utilityRepoTemplatingComponentCleanup(f *framework.Framework, namespace, ap
 	// Delete pipeline run we do not care about
 	for file, sha := range *shaMap {
 		if ! strings.HasSuffix(file, "-push.yaml") {
-			err = listAndDeletePipelineRunsWithTimeout(f, namespace, appName, compName, sha, 1)
+			err = listAndDeletePipelineRunsWithTimeout(f, namespace, appName, compName, sha, 1, time.Until(deadline))
 			if err != nil {
 				return fmt.Errorf("Error deleting on-push merged PipelineRun in namespace %s: %v", namespace, err)
 			}
@@ -248,46 +259,35 @@ func utilityRepoTemplatingComponentCleanup(f *framework.Framework, namespace, ap
 	return nil
 }
 
-func HandleComponent(ctx *PerComponentContext) error {
+func HandleComponent(ctx *PerComponentContext, step *Step) error {
 	var err error
+	start := time.Now()
 
 	logging.Logger.Debug("Creating component %s in namespace %s", ctx.ComponentName, ctx.ParentContext.ParentContext.Namespace)
 
 	// Create component
-	_, err = logging.Measure(
-		createComponent,
-		ctx.Framework,
-		ctx.ParentContext.ParentContext.Namespace,
-		ctx.ComponentName,
-		ctx.ParentContext.ParentContext.ComponentRepoUrl,
-		ctx.ParentContext.ParentContext.Opts.ComponentRepoRevision,
-		ctx.ParentContext.ParentContext.Opts.ComponentContainerContext,
-		ctx.ParentContext.ParentContext.Opts.ComponentContainerFile,
-		ctx.ParentContext.ParentContext.Opts.BuildPipelineSelectorBundle,
-		ctx.ParentContext.ApplicationName,
-		ctx.ParentContext.ParentContext.Opts.PipelineMintmakerDisabled,
-	)
+	comp, err := logging.Measure(ctx.Span, "createComponent", logging.Labels{"repo": ctx.ParentContext.ParentContext.ComponentRepoUrl}, func() (*appstudioApi.Component, error) {
+		return createComponent(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ComponentName, ctx.ParentContext.ParentContext.ComponentRepoUrl, ctx.ParentContext.ParentContext.Opts.ComponentRepoRevision, ctx.ParentContext.ParentContext.Opts.ComponentContainerContext, ctx.ParentContext.ParentContext.Opts.ComponentContainerFile, ctx.ParentContext.ParentContext.Opts.BuildPipelineSelectorBundle, ctx.ParentContext.ApplicationName, ctx.ParentContext.ParentContext.Opts.PipelineMintmakerDisabled)
+	})
 	if err != nil {
 		return logging.Logger.Fail(60, "Component failed creation: %v", err)
 	}
+	checkpointComponent(ctx)
+	recordTimeline(ctx, func(t *componentTimeline) {
+		t.ComponentCreated = comp.CreationTimestamp.Time
+	})
 
-	var pullIface interface{}
-	pullIface, err = logging.Measure(
-		getPaCPullNumber,
-		ctx.Framework,
-		ctx.ParentContext.ParentContext.Namespace,
-		ctx.ComponentName,
-	)
+	// Get merge request number
+	pull, err := logging.Measure(ctx.Span, "getPaCPullNumber", nil, func() (pacPull, error) {
+		return getPaCPullNumber(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ComponentName, remaining(start, step))
+	})
 	if err != nil {
 		return logging.Logger.Fail(61, "Component failed validation: %v", err)
 	}
-
-	// Get merge request number
-	var ok bool
-	ctx.MergeRequestNumber, ok = pullIface.(int)
-	if !ok {
-		return logging.Logger.Fail(62, "Type assertion failed on pull: %+v", pullIface)
-	}
+	ctx.MergeRequestNumber = pull.Number
+	recordTimeline(ctx, func(t *componentTimeline) {
+		t.PullConfigured = pull.Configured
+	})
 
 	// If this is multi-arch build, we do not care about this build, we just merge it, update pipelines and trigger actual multi-arch build
 	if ctx.ParentContext.ParentContext.Opts.PipelineRepoTemplating {
@@ -301,17 +301,9 @@ func HandleComponent(ctx *PerComponentContext) error {
 		}
 
 		// Skip what we do not care about
-		_, err = logging.Measure(
-			utilityRepoTemplatingComponentCleanup,
-			ctx.Framework,
-			ctx.ParentContext.ParentContext.Namespace,
-			ctx.ParentContext.ApplicationName,
-			ctx.ComponentName,
-			ctx.ParentContext.ParentContext.ComponentRepoUrl,
-			ctx.ParentContext.ParentContext.Opts.ComponentRepoRevision,
-			ctx.MergeRequestNumber,
-			placeholders,
-		)
+		err = logging.MeasureErr(ctx.Span, "utilityRepoTemplatingComponentCleanup", logging.Labels{"pull": fmt.Sprint(ctx.MergeRequestNumber)}, func() error {
+			return utilityRepoTemplatingComponentCleanup(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ParentContext.ApplicationName, ctx.ComponentName, ctx.ParentContext.ParentContext.ComponentRepoUrl, ctx.ParentContext.ParentContext.Opts.ComponentRepoRevision, ctx.MergeRequestNumber, placeholders, start.Add(step.Timeout))
+		})
 		if err != nil {
 			return logging.Logger.Fail(63, "Repo-templating workflow component cleanup failed: %v", err)
 		}
@@ -27,9 +27,8 @@ func createIntegrationTestScenario(f *framework.Framework, namespace, name, appN
 	return nil
 }
 
-func validateIntegrationTestScenario(f *framework.Framework, namespace, name, appName string) error {
+func validateIntegrationTestScenario(f *framework.Framework, namespace, name, appName string, timeout time.Duration) error {
 	interval := time.Second * 20
-	timeout := time.Minute * 15
 	var its integrationApi.IntegrationTestScenario
 
 	// TODO It would be much better to watch this resource for a condition
@@ -64,33 +63,24 @@ func validateIntegrationTestScenario(f *framework.Framework, namespace, name, ap
 
 }
 
-func HandleIntegrationTestScenario(ctx *PerApplicationContext) error {
+func HandleIntegrationTestScenario(ctx *PerApplicationContext, step *Step) error {
 	var err error
+	start := time.Now()
 
 	name := fmt.Sprintf("%s-its-%s", ctx.ParentContext.Username, util.GenerateRandomString(5))
 	logging.Logger.Debug("Creating integration test scenario %s for application %s in namespace %s", name, ctx.ApplicationName, ctx.ParentContext.Namespace)
 
-	_, err = logging.Measure(
-		createIntegrationTestScenario,
-		ctx.Framework,
-		ctx.ParentContext.Namespace,
-		name,
-		ctx.ApplicationName,
-		ctx.ParentContext.Opts.TestScenarioGitURL,
-		ctx.ParentContext.Opts.TestScenarioRevision,
-		ctx.ParentContext.Opts.TestScenarioPathInRepo,
-	)
+	err = logging.MeasureErr(ctx.Span, "createIntegrationTestScenario", logging.Labels{"scenario": name}, func() error {
+		return createIntegrationTestScenario(ctx.Framework, ctx.ParentContext.Namespace, name, ctx.ApplicationName, ctx.ParentContext.Opts.TestScenarioGitURL, ctx.ParentContext.Opts.TestScenarioRevision, ctx.ParentContext.Opts.TestScenarioPathInRepo)
+	})
 	if err != nil {
 		return logging.Logger.Fail(40, "Integration test scenario failed creation: %v", err)
 	}
+	checkpointIntegrationTestScenario(ctx, name)
 
-	_, err = logging.Measure(
-		validateIntegrationTestScenario,
-		ctx.Framework,
-		ctx.ParentContext.Namespace,
-		name,
-		ctx.ApplicationName,
-	)
+	err = logging.MeasureErr(ctx.Span, "validateIntegrationTestScenario", logging.Labels{"scenario": name}, func() error {
+		return validateIntegrationTestScenario(ctx.Framework, ctx.ParentContext.Namespace, name, ctx.ApplicationName, remaining(start, step))
+	})
 	if err != nil {
 		return logging.Logger.Fail(41, "Integration test scenario failed validation: %v", err)
 	}
@@ -8,7 +8,7 @@ import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
 import framework "github.com/konflux-ci/e2e-tests/pkg/framework"
 import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-func collectPersistentVolumeClaims(f *framework.Framework, namespace string) error {
+func collectPersistentVolumeClaims(f *framework.Framework, namespace string, span *logging.Span) error {
 	pvcs, err := f.AsKubeAdmin.TektonController.KubeInterface().CoreV1().PersistentVolumeClaims(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		return fmt.Errorf("Error getting PVC: %v\n", err)
@@ -20,7 +20,7 @@ func collectPersistentVolumeClaims(f *framework.Framework, namespace string) err
 			continue
 		}
 		waittime := (pv.ObjectMeta.CreationTimestamp.Time).Sub(pvc.ObjectMeta.CreationTimestamp.Time)
-		logging.LogMeasurement("PVC_to_PV_CreationTimestamp", map[string]string{"pv.Name": pv.Name}, waittime, "", nil)
+		logging.LogMeasurement(span, "PVC_to_PV_CreationTimestamp", logging.Labels{"pv": pv.Name}, waittime, nil)
 	}
 	return nil
 }
@@ -41,6 +41,7 @@ func HandlePersistentVolumeClaim(ctx *MainContext) error {
 	err = collectPersistentVolumeClaims(
 		ctx.Framework,
 		ctx.Namespace,
+		ctx.Span,
 	)
 	if err != nil {
 		return logging.Logger.Fail(75, "Collecting persistent volume claim failed: %v", err)
@@ -10,9 +10,8 @@ import framework "github.com/konflux-ci/e2e-tests/pkg/framework"
 import utils "github.com/konflux-ci/e2e-tests/pkg/utils"
 import pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 
-func validatePipelineRunCreation(f *framework.Framework, namespace, appName, compName string) error {
+func validatePipelineRunCreation(f *framework.Framework, namespace, appName, compName string, timeout time.Duration) error {
 	interval := time.Second * 20
-	timeout := time.Minute * 30
 
 	// TODO It would be much better to watch this resource for a condition
 	err := utils.WaitUntilWithInterval(func() (done bool, err error) {
@@ -27,9 +26,8 @@ func validatePipelineRunCreation(f *framework.Framework, namespace, appName, com
 	return err
 }
 
-func validatePipelineRunCondition(f *framework.Framework, namespace, appName, compName string) error {
+func validatePipelineRunCondition(f *framework.Framework, namespace, appName, compName string, timeout time.Duration) (*pipeline.PipelineRun, error) {
 	interval := time.Second * 20
-	timeout := time.Minute * 60
 	var pr *pipeline.PipelineRun
 
 	// TODO It would be much better to watch this resource for a condition
@@ -63,12 +61,11 @@ func validatePipelineRunCondition(f *framework.Framework, namespace, appName, co
 		return false, nil
 	}, interval, timeout)
 
-	return err
+	return pr, err
 }
 
-func validatePipelineRunSignature(f *framework.Framework, namespace, appName, compName string) error {
+func validatePipelineRunSignature(f *framework.Framework, namespace, appName, compName string, timeout time.Duration) error {
 	interval := time.Second * 20
-	timeout := time.Minute * 60
 	var pr *pipeline.PipelineRun
 
 	// TODO It would be much better to watch this resource for a condition
@@ -102,44 +99,32 @@ func validatePipelineRunSignature(f *framework.Framework, namespace, appName, co
 	return err
 }
 
-func HandlePipelineRun(ctx *PerComponentContext) error {
-	if !ctx.ParentContext.ParentContext.Opts.WaitPipelines {
-		return nil
-	}
-
+func HandlePipelineRun(ctx *PerComponentContext, step *Step) error {
 	var err error
+	start := time.Now()
 
 	logging.Logger.Debug("Creating build pipeline run for component %s in namespace %s", ctx.ComponentName, ctx.ParentContext.ParentContext.Namespace)
 
-	_, err = logging.Measure(
-		validatePipelineRunCreation,
-		ctx.Framework,
-		ctx.ParentContext.ParentContext.Namespace,
-		ctx.ParentContext.ApplicationName,
-		ctx.ComponentName,
-	)
+	err = logging.MeasureErr(ctx.Span, "validatePipelineRunCreation", nil, func() error {
+		return validatePipelineRunCreation(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ParentContext.ApplicationName, ctx.ComponentName, remaining(start, step))
+	})
 	if err != nil {
 		return logging.Logger.Fail(70, "Build Pipeline Run failed creation: %v", err)
 	}
 
-	_, err = logging.Measure(
-		validatePipelineRunCondition,
-		ctx.Framework,
-		ctx.ParentContext.ParentContext.Namespace,
-		ctx.ParentContext.ApplicationName,
-		ctx.ComponentName,
-	)
+	pr, err := logging.Measure(ctx.Span, "validatePipelineRunCondition", nil, func() (*pipeline.PipelineRun, error) {
+		return validatePipelineRunCondition(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ParentContext.ApplicationName, ctx.ComponentName, remaining(start, step))
+	})
 	if err != nil {
 		return logging.Logger.Fail(71, "Build Pipeline Run failed run: %v", err)
 	}
+	recordTimeline(ctx, func(t *componentTimeline) {
+		t.BuildCreated, t.BuildStarted, t.BuildCompleted = pipelineRunTimes(pr)
+	})
 
-	_, err = logging.Measure(
-		validatePipelineRunSignature,
-		ctx.Framework,
-		ctx.ParentContext.ParentContext.Namespace,
-		ctx.ParentContext.ApplicationName,
-		ctx.ComponentName,
-	)
+	err = logging.MeasureErr(ctx.Span, "validatePipelineRunSignature", nil, func() error {
+		return validatePipelineRunSignature(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ParentContext.ApplicationName, ctx.ComponentName, remaining(start, step))
+	})
 	if err != nil {
 		return logging.Logger.Fail(72, "Build Pipeline Run failed signing: %v", err)
 	}
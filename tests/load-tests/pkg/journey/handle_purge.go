@@ -1,11 +1,21 @@
 package journey
 
+import "context"
 import "fmt"
 import "time"
 
 import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+import options "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/options"
 
 import framework "github.com/konflux-ci/e2e-tests/pkg/framework"
+import kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
+import sandbox "github.com/konflux-ci/e2e-tests/pkg/sandbox"
+import integrationApi "github.com/konflux-ci/integration-service/api/v1beta2"
+import releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+import pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+import k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import crclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 func purgeStage(f *framework.Framework, namespace string) error {
 	var err error
@@ -41,6 +51,174 @@ func purgeCi(f *framework.Framework, username string) error {
 	return nil
 }
 
+// Delete resources of Stage user recorded in checkpoint, leaving anything else in the namespace alone
+func purgeStageCheckpoint(f *framework.Framework, user *CheckpointUser) error {
+	var err error
+	namespace := user.Namespace
+
+	for _, app := range user.Applications {
+		for _, comp := range app.Components {
+			for _, rel := range comp.Releases {
+				err = f.AsKubeDeveloper.ReleaseController.KubeRest().Delete(context.Background(), &releaseApi.Release{ObjectMeta: metav1.ObjectMeta{Name: rel, Namespace: namespace}})
+				if err != nil && !k8sErrors.IsNotFound(err) {
+					return fmt.Errorf("Error when deleting release %s in namespace %s: %v", rel, namespace, err)
+				}
+			}
+
+			err = f.AsKubeDeveloper.TektonController.KubeRest().DeleteAllOf(context.Background(), &pipeline.PipelineRun{}, crclient.InNamespace(namespace), crclient.MatchingLabels{"appstudio.openshift.io/component": comp.Name})
+			if err != nil {
+				return fmt.Errorf("Error when deleting pipeline runs of component %s in namespace %s: %v", comp.Name, namespace, err)
+			}
+
+			err = f.AsKubeDeveloper.HasController.DeleteComponent(comp.Name, namespace, false)
+			if err != nil {
+				return fmt.Errorf("Error when deleting component %s in namespace %s: %v", comp.Name, namespace, err)
+			}
+		}
+
+		for _, its := range app.IntegrationTestScenarios {
+			err = f.AsKubeDeveloper.IntegrationController.DeleteIntegrationTestScenario(&integrationApi.IntegrationTestScenario{ObjectMeta: metav1.ObjectMeta{Name: its, Namespace: namespace}}, namespace)
+			if err != nil && !k8sErrors.IsNotFound(err) {
+				return fmt.Errorf("Error when deleting integration test scenario %s in namespace %s: %v", its, namespace, err)
+			}
+		}
+
+		err = f.AsKubeDeveloper.HasController.DeleteApplication(app.Name, namespace, false)
+		if err != nil {
+			return fmt.Errorf("Error when deleting application %s in namespace %s: %v", app.Name, namespace, err)
+		}
+	}
+
+	logging.Logger.Debug("Finished purging checkpointed resources in namespace %s", namespace)
+	return nil
+}
+
+// Delete repository forked for the user
+func purgeFork(f *framework.Framework, repoUrl string) error {
+	name, err := getRepoNameFromRepoUrl(repoUrl)
+	if err != nil {
+		return err
+	}
+	err = f.AsKubeAdmin.CommonController.Github.DeleteRepositoryIfExists(name)
+	if err != nil {
+		return fmt.Errorf("Error when deleting forked repository %s: %v", repoUrl, err)
+	}
+	return nil
+}
+
+// Framework with admin clients only, enough to delete users and their forks without provisioning the users again
+func adminFramework() (*framework.Framework, error) {
+	adminClient, err := kubeCl.NewAdminKubernetesClient()
+	if err != nil {
+		return nil, fmt.Errorf("Error when creating admin client: %v", err)
+	}
+	sandboxController, err := sandbox.NewDevSandboxController(adminClient.KubeInterface(), adminClient.KubeRest())
+	if err != nil {
+		return nil, fmt.Errorf("Error when creating sandbox controller: %v", err)
+	}
+	asAdmin, err := framework.InitControllerHub(adminClient)
+	if err != nil {
+		return nil, fmt.Errorf("Error when initializing controllers for admin: %v", err)
+	}
+	return &framework.Framework{AsKubeAdmin: asAdmin, SandboxController: sandboxController}, nil
+}
+
+// Purge exactly what was recorded in checkpoint of a previous (possibly crashed) run
+func PurgeCheckpoint(cp *Checkpoint) error {
+	checkpoint = cp
+
+	opts := &options.Opts{Stage: cp.Stage}
+	var err error
+	var admin *framework.Framework
+	if cp.Stage {
+		err = openStageUserPool()
+		if err != nil {
+			return err
+		}
+	} else {
+		admin, err = adminFramework()
+		if err != nil {
+			return err
+		}
+	}
+
+	errCounter := 0
+
+	for _, user := range cp.Users {
+		if user.Purged || user.Username == "" {
+			continue
+		}
+		logging.Logger.Info("Purging checkpointed resources of user %s", user.Username)
+
+		// Stage users can only be accessed with their own token, CI users are deleted by admin
+		f := admin
+		var ctx *MainContext
+		if cp.Stage {
+			ctx = &MainContext{
+				ThreadIndex: user.ThreadIndex,
+				Opts:        opts,
+			}
+			cp.restore(ctx)
+			err = HandleUser(ctx)
+			if err != nil {
+				logging.Logger.Error("Error when accessing user %s: %v", user.Username, err)
+				errCounter++
+				continue
+			}
+			f = ctx.Framework
+		}
+
+		if user.ComponentRepoUrl != "" {
+			err = purgeFork(f, user.ComponentRepoUrl)
+			if err != nil {
+				logging.Logger.Error("Error when purging fork of user %s: %v", user.Username, err)
+				errCounter++
+			}
+		}
+
+		if cp.Stage {
+			err = purgeStageCheckpoint(f, user)
+			stopFrameworks(ctx)
+		} else {
+			err = purgeCi(f, user.Username)
+		}
+		if err != nil {
+			logging.Logger.Error("Error when purging user %s: %v", user.Username, err)
+			errCounter++
+			continue
+		}
+
+		updateCheckpoint(func(c *Checkpoint) {
+			user.Purged = true
+		})
+	}
+
+	if errCounter > 0 {
+		return fmt.Errorf("Hit %d errors when purging resources from checkpoint", errCounter)
+	}
+	logging.Logger.Info("No errors when purging resources from checkpoint")
+	return nil
+}
+
+// Purge resources of a single user as a journey step
+func HandleUserPurge(ctx *MainContext, step *Step) error {
+	var err error
+
+	if ctx.Opts.Stage {
+		err = purgeStage(ctx.Framework, ctx.Namespace)
+	} else {
+		err = purgeCi(ctx.Framework, ctx.Username)
+	}
+	if err != nil {
+		return logging.Logger.Fail(95, "Purging user %s failed: %v", ctx.Username, err)
+	}
+
+	ctx.Purged = true
+	checkpointUser(ctx)
+
+	return nil
+}
+
 func Purge() error {
 	if !MainContexts[0].Opts.Purge {
 		return nil
@@ -49,19 +227,26 @@ func Purge() error {
 	errCounter := 0
 
 	for _, ctx := range MainContexts {
+		if ctx.Purged {
+			continue // already purged by 'purge' journey step
+		}
 		if ctx.Opts.Stage {
 			err := purgeStage(ctx.Framework, ctx.Namespace)
 			if err != nil {
 				logging.Logger.Error("Error when purging Stage: %v", err)
 				errCounter++
+				continue
 			}
 		} else {
 			err := purgeCi(ctx.Framework, ctx.Username)
 			if err != nil {
 				logging.Logger.Error("Error when purging CI: %v", err)
 				errCounter++
+				continue
 			}
 		}
+		ctx.Purged = true
+		checkpointUser(ctx)
 	}
 
 	if errCounter > 0 {
@@ -0,0 +1,72 @@
+package journey
+
+import "fmt"
+import "time"
+
+import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+
+import framework "github.com/konflux-ci/e2e-tests/pkg/framework"
+import utils "github.com/konflux-ci/e2e-tests/pkg/utils"
+import util "github.com/devfile/library/v2/pkg/util"
+import releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+
+func createRelease(f *framework.Framework, namespace, name, snapName, releasePlan string) error {
+	_, err := f.AsKubeDeveloper.ReleaseController.CreateRelease(name, namespace, snapName, releasePlan)
+	if err != nil {
+		return fmt.Errorf("Unable to create the Release %s: %v", name, err)
+	}
+	return nil
+}
+
+func validateReleaseCondition(f *framework.Framework, namespace, name string, timeout time.Duration) error {
+	interval := time.Second * 20
+	var release *releaseApi.Release
+
+	err := utils.WaitUntilWithInterval(func() (done bool, err error) {
+		release, err = f.AsKubeDeveloper.ReleaseController.GetRelease(name, "", namespace)
+		if err != nil {
+			logging.Logger.Debug("Unable to get created Release %s in namespace %s: %v", name, namespace, err)
+			return false, nil
+		}
+
+		if !release.HasReleaseFinished() {
+			logging.Logger.Trace("Still waiting for Release %s in namespace %s to finish", name, namespace)
+			return false, nil
+		}
+
+		if !release.IsReleased() {
+			return false, fmt.Errorf("Release %s in namespace %s failed: %+v", name, namespace, release.Status.Conditions)
+		}
+
+		return true, nil
+	}, interval, timeout)
+
+	return err
+}
+
+func HandleRelease(ctx *PerComponentContext, step *Step) error {
+	var err error
+	start := time.Now()
+
+	name := fmt.Sprintf("%s-rel-%s", ctx.ComponentName, util.GenerateRandomString(5))
+	logging.Logger.Debug("Creating release %s of snapshot %s in namespace %s", name, ctx.SnapshotName, ctx.ParentContext.ParentContext.Namespace)
+
+	labels := logging.Labels{"release": name, "snapshot": ctx.SnapshotName}
+
+	err = logging.MeasureErr(ctx.Span, "createRelease", labels, func() error {
+		return createRelease(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, name, ctx.SnapshotName, step.ReleasePlan)
+	})
+	if err != nil {
+		return logging.Logger.Fail(90, "Release failed creation: %v", err)
+	}
+	checkpointRelease(ctx, name)
+
+	err = logging.MeasureErr(ctx.Span, "validateReleaseCondition", labels, func() error {
+		return validateReleaseCondition(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, name, remaining(start, step))
+	})
+	if err != nil {
+		return logging.Logger.Fail(91, "Release failed: %v", err)
+	}
+
+	return nil
+}
@@ -129,6 +129,7 @@ func HandleRepoForking(ctx *MainContext) error {
 	}
 
 	ctx.ComponentRepoUrl = forkUrl
+	checkpointUser(ctx)
 
 	return nil
 }
@@ -11,9 +11,8 @@ import framework "github.com/konflux-ci/e2e-tests/pkg/framework"
 import utils "github.com/konflux-ci/e2e-tests/pkg/utils"
 import pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 
-func validateSnapshotCreation(f *framework.Framework, namespace, compName string) (string, error) {
+func validateSnapshotCreation(f *framework.Framework, namespace, compName string, timeout time.Duration) (*appstudioApi.Snapshot, error) {
 	interval := time.Second * 20
-	timeout := time.Minute * 30
 	var snap *appstudioApi.Snapshot
 
 	// TODO It would be much better to watch this resource for a condition
@@ -25,13 +24,15 @@ func validateSnapshotCreation(f *framework.Framework, namespace, compName string
 		}
 		return true, nil
 	}, interval, timeout)
+	if err != nil {
+		return nil, err
+	}
 
-	return snap.Name, err
+	return snap, nil
 }
 
-func validateTestPipelineRunCreation(f *framework.Framework, namespace, itsName, snapName string) error {
+func validateTestPipelineRunCreation(f *framework.Framework, namespace, itsName, snapName string, timeout time.Duration) error {
 	interval := time.Second * 20
-	timeout := time.Minute * 30
 
 	// TODO It would be much better to watch this resource for a condition
 	err := utils.WaitUntilWithInterval(func() (done bool, err error) {
@@ -46,9 +47,8 @@ func validateTestPipelineRunCreation(f *framework.Framework, namespace, itsName,
 	return err
 }
 
-func validateTestPipelineRunCondition(f *framework.Framework, namespace, itsName, snapName string) error {
+func validateTestPipelineRunCondition(f *framework.Framework, namespace, itsName, snapName string, timeout time.Duration) (*pipeline.PipelineRun, error) {
 	interval := time.Second * 20
-	timeout := time.Minute * 60
 	var pr *pipeline.PipelineRun
 
 	// TODO It would be much better to watch this resource for a condition
@@ -79,54 +79,51 @@ func validateTestPipelineRunCondition(f *framework.Framework, namespace, itsName
 		return false, nil
 	}, interval, timeout)
 
-	return err
+	return pr, err
 }
 
-func HandleTest(ctx *PerComponentContext) error {
-	if !ctx.ParentContext.ParentContext.Opts.WaitPipelines || !ctx.ParentContext.ParentContext.Opts.WaitIntegrationTestsPipelines {
-		return nil
+func HandleSnapshot(ctx *PerComponentContext, step *Step) error {
+	logging.Logger.Debug("Waiting for snapshot for component %s in namespace %s", ctx.ComponentName, ctx.ParentContext.ParentContext.Namespace)
+
+	snap, err := logging.Measure(ctx.Span, "validateSnapshotCreation", nil, func() (*appstudioApi.Snapshot, error) {
+		return validateSnapshotCreation(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ComponentName, step.Timeout)
+	})
+	if err != nil {
+		return logging.Logger.Fail(80, "Snapshot failed creation: %v", err)
+	}
+	if snap.Name == "" {
+		return logging.Logger.Fail(81, "Snapshot name is empty")
 	}
+	ctx.SnapshotName = snap.Name
+	recordTimeline(ctx, func(t *componentTimeline) {
+		t.SnapshotCreated = snap.CreationTimestamp.Time
+	})
 
+	return nil
+}
+
+func HandleTest(ctx *PerComponentContext, step *Step) error {
 	var err error
-	var ok bool
+	start := time.Now()
 
 	logging.Logger.Debug("Creating test pipeline run for component %s in namespace %s", ctx.ComponentName, ctx.ParentContext.ParentContext.Namespace)
 
-	result1, err1 := logging.Measure(
-		validateSnapshotCreation,
-		ctx.Framework,
-		ctx.ParentContext.ParentContext.Namespace,
-		ctx.ComponentName,
-	)
-	if err1 != nil {
-		return logging.Logger.Fail(80, "Snapshot failed creation: %v", err1)
-	}
-	ctx.SnapshotName, ok = result1.(string)
-	if !ok {
-		return logging.Logger.Fail(81, "Snapshot name type assertion failed")
-	}
-
-	_, err = logging.Measure(
-		validateTestPipelineRunCreation,
-		ctx.Framework,
-		ctx.ParentContext.ParentContext.Namespace,
-		ctx.ParentContext.IntegrationTestScenarioName,
-		ctx.SnapshotName,
-	)
+	err = logging.MeasureErr(ctx.Span, "validateTestPipelineRunCreation", logging.Labels{"snapshot": ctx.SnapshotName}, func() error {
+		return validateTestPipelineRunCreation(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ParentContext.IntegrationTestScenarioName, ctx.SnapshotName, remaining(start, step))
+	})
 	if err != nil {
 		return logging.Logger.Fail(82, "Test Pipeline Run failed creation: %v", err)
 	}
 
-	_, err = logging.Measure(
-		validateTestPipelineRunCondition,
-		ctx.Framework,
-		ctx.ParentContext.ParentContext.Namespace,
-		ctx.ParentContext.IntegrationTestScenarioName,
-		ctx.SnapshotName,
-	)
+	pr, err := logging.Measure(ctx.Span, "validateTestPipelineRunCondition", logging.Labels{"snapshot": ctx.SnapshotName}, func() (*pipeline.PipelineRun, error) {
+		return validateTestPipelineRunCondition(ctx.Framework, ctx.ParentContext.ParentContext.Namespace, ctx.ParentContext.IntegrationTestScenarioName, ctx.SnapshotName, remaining(start, step))
+	})
 	if err != nil {
 		return logging.Logger.Fail(83, "Test Pipeline Run failed run: %v", err)
 	}
+	recordTimeline(ctx, func(t *componentTimeline) {
+		t.TestCreated, t.TestStarted, t.TestCompleted = pipelineRunTimes(pr)
+	})
 
 	return nil
 }
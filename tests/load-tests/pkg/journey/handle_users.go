@@ -4,27 +4,53 @@ import "fmt"
 import "time"
 
 import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+import loadtestutils "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/loadtestutils"
 
 import "github.com/konflux-ci/e2e-tests/pkg/framework"
 import "github.com/konflux-ci/e2e-tests/pkg/utils"
 
+// Framework options for Stage user, offline token is the latest one refreshed by the user pool
+func stageFrameworkOptions(user loadtestutils.User) utils.Options {
+	return utils.Options{
+		ToolchainApiUrl: user.APIURL,
+		KeycloakUrl:     user.SSOURL,
+		OfflineToken:    user.Token,
+	}
+}
+
 func HandleUser(ctx *MainContext) error {
 	var err error
 
 	// TODO E.g. when token is incorrect, timeout does not work as expected
 	if ctx.Opts.Stage {
-		user := (*ctx.StageUsers)[ctx.ThreadIndex]
-		ctx.Username = user.Username
-		ctx.Framework, err = framework.NewFrameworkWithTimeout(
-			ctx.Username,
-			time.Minute*60,
-			utils.Options{
-				ToolchainApiUrl: user.APIURL,
-				KeycloakUrl:     user.SSOURL,
-				OfflineToken:    user.Token,
-			})
+		resumed := ctx.Username != ""
+		for {
+			ctx.UserLease, err = leaseStageUser(ctx)
+			if err != nil {
+				return logging.Logger.Fail(10, "Unable to lease user: %v", err)
+			}
+			user := ctx.UserLease.User()
+			ctx.Username = user.Username
+			ctx.Framework, err = framework.NewFrameworkWithTimeout(
+				ctx.Username,
+				time.Minute*60,
+				stageFrameworkOptions(user))
+			if err == nil {
+				break
+			}
+
+			// User with broken token or namespace must not be leased again
+			ctx.UserLease.Quarantine(fmt.Sprintf("Unable to provision: %v", err))
+			ctx.UserLease = nil
+			if resumed {
+				break // resources of resumed user are in its namespace, other user would not help
+			}
+			logging.Logger.Warning("Quarantined user %s, trying another one: %v", ctx.Username, err)
+		}
 	} else {
-		ctx.Username = fmt.Sprintf("%s-%04d", ctx.Opts.UsernamePrefix, ctx.ThreadIndex)
+		if ctx.Username == "" {
+			ctx.Username = fmt.Sprintf("%s-%04d", ctx.Opts.UsernamePrefix, ctx.ThreadIndex)
+		}
 		ctx.Framework, err = framework.NewFrameworkWithTimeout(ctx.Username, time.Minute*60)
 	}
 
@@ -33,6 +59,7 @@ func HandleUser(ctx *MainContext) error {
 	}
 
 	ctx.Namespace = ctx.Framework.UserNamespace
+	checkpointUser(ctx)
 
 	return nil
 }
@@ -42,15 +69,10 @@ func HandleNewFrameworkForComp(ctx *PerComponentContext) error {
 
 	// TODO This framework generation code is duplicate to above
 	if ctx.ParentContext.ParentContext.Opts.Stage {
-		user := (*ctx.ParentContext.ParentContext.StageUsers)[ctx.ParentContext.ParentContext.ThreadIndex]
 		ctx.Framework, err = framework.NewFrameworkWithTimeout(
 			ctx.ParentContext.ParentContext.Username,
 			time.Minute*60,
-			utils.Options{
-				ToolchainApiUrl: user.APIURL,
-				KeycloakUrl:     user.SSOURL,
-				OfflineToken:    user.Token,
-			})
+			stageFrameworkOptions(ctx.ParentContext.ParentContext.UserLease.User()))
 	} else {
 		ctx.Framework, err = framework.NewFrameworkWithTimeout(ctx.ParentContext.ParentContext.Username, time.Minute*60)
 	}
@@ -67,15 +89,10 @@ func HandleNewFrameworkForApp(ctx *PerApplicationContext) error {
 
 	// TODO This framework generation code is duplicate to above
 	if ctx.ParentContext.Opts.Stage {
-		user := (*ctx.ParentContext.StageUsers)[ctx.ParentContext.ThreadIndex]
 		ctx.Framework, err = framework.NewFrameworkWithTimeout(
 			ctx.ParentContext.Username,
 			time.Minute*60,
-			utils.Options{
-				ToolchainApiUrl: user.APIURL,
-				KeycloakUrl:     user.SSOURL,
-				OfflineToken:    user.Token,
-			})
+			stageFrameworkOptions(ctx.ParentContext.UserLease.User()))
 	} else {
 		ctx.Framework, err = framework.NewFrameworkWithTimeout(ctx.ParentContext.Username, time.Minute*60)
 	}
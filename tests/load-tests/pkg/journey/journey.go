@@ -18,13 +18,23 @@ type MainContext struct {
 	ThreadsWG              *sync.WaitGroup
 	ThreadIndex            int
 	JourneyRepeatsCounter  int
+	JourneyRepeatsDone     int // journey repeats finished, including these of resumed run
 	Opts                   *options.Opts
-	StageUsers             *[]loadtestutils.User
+	Spec                   *JourneySpec
+	UserLease              *loadtestutils.Lease // Stage user leased from the user pool
 	Framework              *framework.Framework
 	Username               string
 	Namespace              string
 	ComponentRepoUrl       string // overrides same value from Opts, needed when templating repos
+	Purged                 bool   // resources were purged by journey step
 	PerApplicationContexts []*PerApplicationContext
+	Span                   *logging.Span // measurements of this user thread are nested in this span
+	profile                loadProfile
+}
+
+// Block until this user thread can start next journey iteration according to load profile, false means we are done
+func (ctx *MainContext) NextIteration() bool {
+	return ctx.profile.nextIteration(ctx)
 }
 
 // Just to create user
@@ -34,7 +44,9 @@ func initUserThread(threadCtx *MainContext) {
 	var err error
 
 	// Create user if needed
-	_, err = logging.Measure(HandleUser, threadCtx)
+	err = logging.MeasureErr(threadCtx.Span, "HandleUser", nil, func() error {
+		return HandleUser(threadCtx)
+	})
 	if err != nil {
 		logging.Logger.Error("Thread failed: %v", err)
 		return
@@ -43,16 +55,22 @@ func initUserThread(threadCtx *MainContext) {
 
 // Start all the user journey threads
 // TODO split this to two functions and get PurgeOnly code out
-func Setup(fn func(*MainContext), opts *options.Opts) (string, error) {
+func Setup(fn func(*MainContext), opts *options.Opts, spec *JourneySpec) (string, error) {
 	threadsWG := &sync.WaitGroup{}
 	threadsWG.Add(opts.Concurrency)
+	profile := newLoadProfile(opts)
 
-	var stageUsers []loadtestutils.User
 	var err error
+
+	// Persist what we create so crashed run can be resumed or purged
+	checkpoint, err = openCheckpoint(opts)
+	if err != nil {
+		return "", err
+	}
 	if opts.Stage {
-		stageUsers, err = loadtestutils.LoadStageUsers("users.json")
+		err = openStageUserPool()
 		if err != nil {
-			logging.Logger.Fatal("Failed to load Stage users: %v", err)
+			logging.Logger.Fatal("%v", err)
 		}
 	}
 
@@ -64,9 +82,15 @@ func Setup(fn func(*MainContext), opts *options.Opts) (string, error) {
 			ThreadsWG:        threadsWG,
 			ThreadIndex:      threadIndex,
 			Opts:             opts,
-			StageUsers:       &stageUsers,
+			Spec:             spec,
 			Username:         "",
 			Namespace:        "",
+			profile:          profile,
+			Span:             logging.StartSpan(nil, "perUserThread", logging.Labels{"thread": fmt.Sprint(threadIndex)}),
+		}
+
+		if opts.Resume {
+			checkpoint.restore(threadCtx)
 		}
 
 		MainContexts = append(MainContexts, threadCtx)
@@ -87,18 +111,24 @@ func Setup(fn func(*MainContext), opts *options.Opts) (string, error) {
 
 	// Fork repositories sequentially as GitHub do not allow more than 3 running forks in parallel anyway
 	for _, threadCtx := range MainContexts {
-		_, err = logging.Measure(HandleRepoForking, threadCtx)
+		if threadCtx.ComponentRepoUrl != "" {
+			logging.Logger.Debug("Reusing repository %s forked for user %s by resumed run", threadCtx.ComponentRepoUrl, threadCtx.Username)
+			continue
+		}
+		err = logging.MeasureErr(threadCtx.Span, "HandleRepoForking", nil, func() error {
+			return HandleRepoForking(threadCtx)
+		})
 		if err != nil {
 			return "", err
 		}
 	}
 
-	threadsWG.Add(opts.Concurrency)
+	// Sample cluster state while the journeys run
+	startClusterSampling(opts, MainContexts)
+	defer stopClusterSampling()
 
-	// Run actual user thread function
-	for _, threadCtx := range MainContexts {
-		go fn(threadCtx)
-	}
+	// Run actual user thread function, load profile decides when each thread starts
+	profile.start(MainContexts, fn)
 
 	threadsWG.Wait()
 
@@ -114,6 +144,7 @@ type PerApplicationContext struct {
 	ApplicationName             string
 	IntegrationTestScenarioName string
 	PerComponentContexts        []*PerComponentContext
+	Span                        *logging.Span // measurements of this application thread are nested in this span
 }
 
 // Start all the threads to process all applications per user
@@ -130,6 +161,7 @@ func PerApplicationSetup(fn func(*PerApplicationContext), parentContext *MainCon
 			ParentContext:    parentContext,
 			ApplicationName:  fmt.Sprintf("%s-app-%s", parentContext.Username, util.GenerateRandomString(5)),
 		}
+		perApplicationCtx.Span = logging.StartSpan(parentContext.Span, "perApplicationThread", logging.Labels{"iteration": fmt.Sprint(parentContext.JourneyRepeatsCounter), "app": perApplicationCtx.ApplicationName})
 
 		parentContext.PerApplicationContexts = append(parentContext.PerApplicationContexts, perApplicationCtx)
 
@@ -138,6 +170,9 @@ func PerApplicationSetup(fn func(*PerApplicationContext), parentContext *MainCon
 
 	perApplicationWG.Wait()
 
+	parentContext.JourneyRepeatsDone = parentContext.JourneyRepeatsCounter
+	checkpointUser(parentContext)
+
 	return "", nil
 }
 
@@ -150,6 +185,8 @@ type PerComponentContext struct {
 	ComponentName      string
 	SnapshotName       string
 	MergeRequestNumber int
+	Span               *logging.Span     // measurements of this component thread are nested in this span
+	Timeline           componentTimeline // server side timestamps of objects created for the component
 }
 
 // Start all the threads to process all components per application
@@ -166,6 +203,7 @@ func PerComponentSetup(fn func(*PerComponentContext), parentContext *PerApplicat
 			ParentContext:  parentContext,
 			ComponentName:  fmt.Sprintf("%s-comp-%d", parentContext.ApplicationName, componentIndex),
 		}
+		perComponentCtx.Span = logging.StartSpan(parentContext.Span, "perComponentThread", logging.Labels{"component": perComponentCtx.ComponentName})
 
 		parentContext.PerComponentContexts = append(parentContext.PerComponentContexts, perComponentCtx)
 
@@ -0,0 +1,307 @@
+package journey
+
+import "encoding/json"
+import "fmt"
+import "os"
+import "sort"
+import "strings"
+import "sync"
+import "time"
+
+import evaluate "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/evaluate"
+import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+import options "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/options"
+
+// Load profile decides when user threads start and when they may start next journey iteration
+type loadProfile interface {
+	// Start user threads, returns once no more threads will be started
+	start(contexts []*MainContext, fn func(*MainContext))
+	// Block until given user thread can start next journey iteration, false means the thread should stop
+	nextIteration(ctx *MainContext) bool
+}
+
+func newLoadProfile(opts *options.Opts) loadProfile {
+	switch opts.LoadProfile {
+	case options.LoadProfileRampUp:
+		return &rampUpProfile{duration: opts.RampUpDuration}
+	case options.LoadProfileArrivalRate:
+		return &arrivalRateProfile{rate: opts.ArrivalRate, tokens: make(chan struct{})}
+	case options.LoadProfileStep:
+		return &stepProfile{}
+	default:
+		return &rampUpProfile{}
+	}
+}
+
+func startThread(ctx *MainContext, fn func(*MainContext)) {
+	logging.Logger.Debug("Starting user thread %d", ctx.ThreadIndex)
+	ctx.ThreadsWG.Add(1)
+	go fn(ctx)
+}
+
+// Check journey repeats and '--journey-duration' the way constant concurrency always did
+func iterationAllowed(ctx *MainContext, checkRepeats bool) bool {
+	if checkRepeats && ctx.JourneyRepeatsCounter > ctx.Opts.JourneyRepeats {
+		return false
+	}
+	if ctx.JourneyRepeatsCounter > 1 && time.Now().UTC().After(ctx.Opts.JourneyUntil) {
+		logging.Logger.Debug("Done with user journey because of timeout")
+		return false
+	}
+	return true
+}
+
+// Delay of start of thread with given index when all threads are started evenly over given duration
+func rampUpDelay(threadIndex, threads int, duration time.Duration) time.Duration {
+	if threads <= 1 {
+		return 0
+	}
+	return duration * time.Duration(threadIndex) / time.Duration(threads-1)
+}
+
+// Linear ramp-up of user threads, with zero duration all threads start at once (constant concurrency)
+type rampUpProfile struct {
+	duration time.Duration
+}
+
+func (p *rampUpProfile) start(contexts []*MainContext, fn func(*MainContext)) {
+	begin := time.Now()
+	for i, ctx := range contexts {
+		time.Sleep(time.Until(begin.Add(rampUpDelay(i, len(contexts), p.duration))))
+		startThread(ctx, fn)
+	}
+}
+
+func (p *rampUpProfile) nextIteration(ctx *MainContext) bool {
+	return iterationAllowed(ctx, true)
+}
+
+// Constant arrival rate of journeys no matter how long they take. Journeys are run by the pool
+// of user threads, when no thread is free at the time journey should start, it is dropped.
+type arrivalRateProfile struct {
+	rate   float64 // journeys per minute
+	tokens chan struct{}
+}
+
+func (p *arrivalRateProfile) start(contexts []*MainContext, fn func(*MainContext)) {
+	for _, ctx := range contexts {
+		startThread(ctx, fn)
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Minute) / p.rate))
+	defer ticker.Stop()
+
+	started, dropped := 0, 0
+	for time.Now().UTC().Before(contexts[0].Opts.JourneyUntil) {
+		// Journey can start late, but only within its time slot so the rate is never exceeded
+		select {
+		case p.tokens <- struct{}{}:
+			started++
+			<-ticker.C
+		case <-ticker.C:
+			dropped++
+			logging.JourneyIterationDropped()
+			logging.Logger.Warning("Dropping journey iteration as all %d user threads are busy", len(contexts))
+		}
+	}
+	close(p.tokens)
+
+	logging.Logger.Info("Arrival rate load profile started %d and dropped %d journey iterations", started, dropped)
+}
+
+func (p *arrivalRateProfile) nextIteration(ctx *MainContext) bool {
+	_, ok := <-p.tokens
+	return ok
+}
+
+// Measurements of step load profile window used to check SLO
+type sloWindow struct {
+	lock    sync.Mutex
+	metrics []string
+	passed  map[string][]float64
+	failed  int
+}
+
+func newSloWindow(metrics []string) *sloWindow {
+	w := &sloWindow{metrics: metrics}
+	w.reset()
+	return w
+}
+
+func (w *sloWindow) reset() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.passed = map[string][]float64{}
+	w.failed = 0
+}
+
+func (w *sloWindow) add(entry logging.MeasurementEntry) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, m := range w.metrics {
+		if !evaluate.MetricMatches(entry.Metric, m) {
+			continue
+		}
+		if entry.Error != nil {
+			w.failed++
+		} else {
+			w.passed[m] = append(w.passed[m], entry.Duration.Seconds())
+		}
+	}
+}
+
+// Result of one step of step load profile
+type StepResult struct {
+	Concurrency int       `json:"concurrency"`
+	Started     time.Time `json:"started"`
+	Ended       time.Time `json:"ended"`
+	Value       float64   `json:"value"` // SLO statistic in seconds, -1 if some metric had no passed samples
+	Passed      int       `json:"passed"`
+	Failed      int       `json:"failed"`
+	ErrorRate   float64   `json:"errorRate"` // in percent
+	Violated    bool      `json:"violated"`
+}
+
+// Sum of SLO statistic (mean, or percentile if greater than 0) over all the metrics, same as KPI mean in evaluate
+func (w *sloWindow) evaluate(percentile, threshold, maxErrorRate float64) StepResult {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	result := StepResult{Failed: w.failed}
+	for _, m := range w.metrics {
+		data := append([]float64{}, w.passed[m]...)
+		result.Passed += len(data)
+		if len(data) == 0 {
+			result.Value = -1
+			continue
+		}
+		if result.Value == -1 {
+			continue
+		}
+		sort.Float64s(data)
+		if percentile > 0 {
+			result.Value += evaluate.Percentile(data, percentile)
+		} else {
+			sum := 0.0
+			for _, d := range data {
+				sum += d
+			}
+			result.Value += sum / float64(len(data))
+		}
+	}
+	if total := result.Passed + result.Failed; total > 0 {
+		result.ErrorRate = float64(result.Failed) / float64(total) * 100
+	}
+	result.Violated = result.Value == -1 || result.Value > threshold || result.ErrorRate > maxErrorRate
+	return result
+}
+
+// Outcome of step load profile, fields mirror what 'run-max-concurrency.sh' used to store
+type MaxConcurrencyResult struct {
+	Started               time.Time    `json:"started"`
+	Ended                 time.Time    `json:"ended"`
+	MaxThreads            int          `json:"maxThreads"`
+	MaxConcurrencySteps   string       `json:"maxConcurrencySteps"`
+	SloMetric             string       `json:"sloMetric"`
+	SloPercentile         float64      `json:"sloPercentile"`
+	Threshold             float64      `json:"threshold"`
+	ThresholdErrors       float64      `json:"thresholdErrors"`
+	MaxConcurrencyReached int          `json:"maxConcurrencyReached"`
+	ComputedConcurrency   float64      `json:"computedConcurrency"`
+	WorkloadKPI           float64      `json:"workloadKPI"`
+	ErrorsTotal           int          `json:"errorsTotal"`
+	Steps                 []StepResult `json:"steps"`
+}
+
+// Record step result, returns false when the SLO was violated and no more steps should run
+func (r *MaxConcurrencyResult) addStep(step StepResult) bool {
+	r.Steps = append(r.Steps, step)
+	if !step.Violated {
+		r.MaxConcurrencyReached = step.Concurrency
+		r.ComputedConcurrency = float64(step.Concurrency)
+		r.WorkloadKPI = step.Value
+		r.ErrorsTotal = step.Failed
+		return true
+	}
+	// Interpolate concurrency where the statistic crossed the threshold between last good step and this one
+	if step.Value > r.Threshold && step.Value > r.WorkloadKPI {
+		r.ComputedConcurrency = (r.Threshold-r.WorkloadKPI)/((step.Value-r.WorkloadKPI)/float64(step.Concurrency-r.MaxConcurrencyReached)) + float64(r.MaxConcurrencyReached)
+	}
+	return false
+}
+
+// Increase number of user threads every step until SLO is violated or all the threads are running
+type stepProfile struct {
+	lock    sync.Mutex
+	stopped bool
+}
+
+func (p *stepProfile) start(contexts []*MainContext, fn func(*MainContext)) {
+	opts := contexts[0].Opts
+	metrics := []string{opts.SloMetric}
+	if opts.SloMetric == "KPI" {
+		metrics = evaluate.KPIMetrics
+	}
+	window := newSloWindow(metrics)
+	logging.AddMeasurementListener(window.add)
+
+	var steps []string
+	for _, c := range opts.StepConcurrency {
+		steps = append(steps, fmt.Sprint(c))
+	}
+	result := &MaxConcurrencyResult{
+		Started:             time.Now().UTC(),
+		MaxThreads:          len(contexts),
+		MaxConcurrencySteps: strings.Join(steps, " "),
+		SloMetric:           opts.SloMetric,
+		SloPercentile:       opts.SloPercentile,
+		Threshold:           opts.SloThreshold,
+		ThresholdErrors:     opts.SloMaxErrorRate,
+	}
+
+	running := 0
+	for _, concurrency := range opts.StepConcurrency {
+		if concurrency > len(contexts) || time.Now().UTC().After(opts.JourneyUntil) {
+			break
+		}
+
+		logging.Logger.Info("Starting load step with concurrency %d", concurrency)
+		window.reset()
+		stepStart := time.Now().UTC()
+		for ; running < concurrency; running++ {
+			startThread(contexts[running], fn)
+		}
+		time.Sleep(opts.StepDuration)
+
+		step := window.evaluate(opts.SloPercentile, opts.SloThreshold, opts.SloMaxErrorRate)
+		step.Concurrency = concurrency
+		step.Started = stepStart
+		step.Ended = time.Now().UTC()
+		if !result.addStep(step) {
+			logging.Logger.Info("SLO on %s violated with concurrency %d (value %.2fs, error rate %.2f%%)", opts.SloMetric, concurrency, step.Value, step.ErrorRate)
+			break
+		}
+		logging.Logger.Info("SLO on %s holds with concurrency %d (value %.2fs, error rate %.2f%%)", opts.SloMetric, concurrency, step.Value, step.ErrorRate)
+	}
+	result.Ended = time.Now().UTC()
+
+	p.lock.Lock()
+	p.stopped = true
+	p.lock.Unlock()
+
+	data, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		logging.Logger.Error("Error marshalling max concurrency result: %v", err)
+		return
+	}
+	err = os.WriteFile(opts.OutputDir+"/load-test.max-concurrency.json", data, 0600)
+	if err != nil {
+		logging.Logger.Error("Error writing to file: %v", err)
+	}
+}
+
+func (p *stepProfile) nextIteration(ctx *MainContext) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return !p.stopped && iterationAllowed(ctx, false)
+}
@@ -0,0 +1,67 @@
+package journey
+
+import "fmt"
+import "testing"
+import "time"
+
+import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+
+import "github.com/stretchr/testify/assert"
+
+func TestRampUpDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), rampUpDelay(0, 1, time.Minute))
+	assert.Equal(t, time.Duration(0), rampUpDelay(0, 5, time.Minute))
+	assert.Equal(t, 15*time.Second, rampUpDelay(1, 5, time.Minute))
+	assert.Equal(t, time.Minute, rampUpDelay(4, 5, time.Minute))
+}
+
+func TestSloWindow(t *testing.T) {
+	window := newSloWindow([]string{"createApplication", "createComponent"})
+	measure := func(metric string, seconds int, err error) {
+		window.add(logging.MeasurementEntry{Metric: "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/journey." + metric, Duration: time.Duration(seconds) * time.Second, Error: err})
+	}
+
+	measure("createApplication", 10, nil)
+	result := window.evaluate(0, 100, 10)
+	assert.Equal(t, -1.0, result.Value, "no samples of createComponent")
+	assert.True(t, result.Violated)
+
+	measure("createApplication", 20, nil)
+	measure("createComponent", 30, nil)
+	measure("createComponent", 50, nil)
+	measure("HandleComponent", 500, nil)
+	result = window.evaluate(0, 100, 10)
+	assert.Equal(t, 55.0, result.Value, "sum of means")
+	assert.Equal(t, 4, result.Passed)
+	assert.False(t, result.Violated)
+
+	result = window.evaluate(90, 50, 10)
+	assert.Equal(t, 19.0+48.0, result.Value, "sum of 90th percentiles")
+	assert.True(t, result.Violated)
+
+	measure("createComponent", 1, fmt.Errorf("failed"))
+	result = window.evaluate(0, 100, 10)
+	assert.Equal(t, 20.0, result.ErrorRate)
+	assert.True(t, result.Violated)
+
+	window.reset()
+	assert.Equal(t, 0, window.evaluate(0, 100, 10).Passed)
+}
+
+func TestMaxConcurrencyResult(t *testing.T) {
+	result := &MaxConcurrencyResult{Threshold: 300}
+	assert.True(t, result.addStep(StepResult{Concurrency: 5, Value: 200, Failed: 1}))
+	assert.True(t, result.addStep(StepResult{Concurrency: 10, Value: 250}))
+	assert.False(t, result.addStep(StepResult{Concurrency: 20, Value: 350, Violated: true}))
+
+	assert.Equal(t, 10, result.MaxConcurrencyReached)
+	assert.Equal(t, 250.0, result.WorkloadKPI)
+	assert.Equal(t, 15.0, result.ComputedConcurrency)
+	assert.Len(t, result.Steps, 3)
+
+	// Error rate violation does not interpolate
+	result = &MaxConcurrencyResult{Threshold: 300}
+	assert.True(t, result.addStep(StepResult{Concurrency: 5, Value: 200}))
+	assert.False(t, result.addStep(StepResult{Concurrency: 10, Value: 250, ErrorRate: 50, Violated: true}))
+	assert.Equal(t, 5.0, result.ComputedConcurrency)
+}
@@ -0,0 +1,322 @@
+package journey
+
+import "fmt"
+import "os"
+import "path/filepath"
+import "time"
+
+import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+import options "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/options"
+
+import yaml "gopkg.in/yaml.v2"
+
+// Types of journey steps
+const (
+	StepCreateApplication             = "create-application"
+	StepCreateIntegrationTestScenario = "create-integration-test-scenario"
+	StepCreateComponent               = "create-component"
+	StepWaitBuild                     = "wait-build"
+	StepWaitSnapshot                  = "wait-snapshot"
+	StepWaitTests                     = "wait-tests"
+	StepRelease                       = "release"
+	StepPurge                         = "purge"
+)
+
+// Level of the journey on which a step runs
+type stepScope int
+
+const (
+	applicationScope stepScope = iota
+	componentScope
+	userScope
+)
+
+// Description of each step type: where it runs, what it needs to run before it and its default timeout
+type stepType struct {
+	scope      stepScope
+	requires   []string
+	timeout    time.Duration
+	repeatable bool
+}
+
+var stepTypes = map[string]stepType{
+	StepCreateApplication:             {scope: applicationScope, timeout: time.Minute * 15},
+	StepCreateIntegrationTestScenario: {scope: applicationScope, requires: []string{StepCreateApplication}, timeout: time.Minute * 15},
+	StepCreateComponent:               {scope: componentScope, requires: []string{StepCreateApplication}, timeout: time.Minute * 90},
+	StepWaitBuild:                     {scope: componentScope, requires: []string{StepCreateComponent}, timeout: time.Minute * 150, repeatable: true},
+	StepWaitSnapshot:                  {scope: componentScope, requires: []string{StepCreateComponent}, timeout: time.Minute * 30, repeatable: true},
+	StepWaitTests:                     {scope: componentScope, requires: []string{StepCreateIntegrationTestScenario, StepWaitSnapshot}, timeout: time.Minute * 90, repeatable: true},
+	StepRelease:                       {scope: componentScope, requires: []string{StepWaitSnapshot}, timeout: time.Minute * 60, repeatable: true},
+	StepPurge:                         {scope: userScope, timeout: time.Minute * 15},
+}
+
+// Journey definition loaded from YAML file given by '--journey'
+type JourneySpec struct {
+	Name         string `yaml:"name"`
+	Applications int    `yaml:"applications,omitempty"` // overrides '--applications-count' when set
+	Components   int    `yaml:"components,omitempty"`   // overrides '--components-count' when set
+	Steps        []Step `yaml:"steps"`
+}
+
+// Single step of the journey
+type Step struct {
+	Step        string        `yaml:"step"`
+	Timeout     time.Duration `yaml:"timeout,omitempty"`     // deadline for the whole step, defaults depend on step type
+	Repeats     int           `yaml:"repeats,omitempty"`     // how many times to run the step, defaults to 1
+	ThinkTime   time.Duration `yaml:"thinkTime,omitempty"`   // how long to sleep after each run of the step
+	ReleasePlan string        `yaml:"releasePlan,omitempty"` // release plan to use for 'release' step
+}
+
+// Journey equivalent to the one driven by command line options
+func DefaultJourneySpec(opts *options.Opts) *JourneySpec {
+	spec := &JourneySpec{
+		Name: "default",
+		Steps: []Step{
+			{Step: StepCreateApplication},
+			{Step: StepCreateIntegrationTestScenario},
+			{Step: StepCreateComponent},
+		},
+	}
+	if opts.WaitPipelines {
+		spec.Steps = append(spec.Steps, Step{Step: StepWaitBuild})
+		if opts.WaitIntegrationTestsPipelines {
+			spec.Steps = append(spec.Steps, Step{Step: StepWaitSnapshot}, Step{Step: StepWaitTests})
+		}
+	}
+	spec.setDefaults()
+	return spec
+}
+
+// Load journey from given file, or build default one from options if no file was given.
+// Options are updated to match the journey, so code outside of journey steps sees consistent values.
+// Used journey is dumped to output directory for reference.
+func LoadJourneySpec(opts *options.Opts) (*JourneySpec, error) {
+	var spec *JourneySpec
+
+	if opts.JourneySpecFile == "" {
+		spec = DefaultJourneySpec(opts)
+	} else {
+		data, err := os.ReadFile(filepath.Clean(opts.JourneySpecFile))
+		if err != nil {
+			return nil, fmt.Errorf("Error reading journey file: %v", err)
+		}
+		spec, err = ParseJourneySpec(data)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing journey file %s: %v", opts.JourneySpecFile, err)
+		}
+
+		if spec.Applications > 0 {
+			opts.ApplicationsCount = spec.Applications
+		}
+		if spec.Components > 0 {
+			opts.ComponentsCount = spec.Components
+		}
+		opts.WaitPipelines = spec.Has(StepWaitBuild)
+		opts.WaitIntegrationTestsPipelines = spec.Has(StepWaitTests)
+	}
+
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling journey: %v", err)
+	}
+	err = os.WriteFile(opts.OutputDir+"/load-test-journey.yaml", data, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Error writing to file: %v", err)
+	}
+
+	return spec, nil
+}
+
+// Parse and validate journey YAML
+func ParseJourneySpec(data []byte) (*JourneySpec, error) {
+	spec := &JourneySpec{}
+	err := yaml.UnmarshalStrict(data, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.setDefaults()
+
+	err = spec.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+func (s *JourneySpec) setDefaults() {
+	for i := range s.Steps {
+		if s.Steps[i].Repeats == 0 {
+			s.Steps[i].Repeats = 1
+		}
+		if s.Steps[i].Timeout == 0 {
+			s.Steps[i].Timeout = stepTypes[s.Steps[i].Step].timeout
+		}
+	}
+}
+
+// Check step types are known, steps run after steps they depend on and steps of application
+// level come before steps of component level which come before steps of user level
+func (s *JourneySpec) validate() error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("Journey has no steps")
+	}
+
+	seen := map[string]bool{}
+	var previous *Step
+	for i := range s.Steps {
+		step := &s.Steps[i]
+		st, ok := stepTypes[step.Step]
+		if !ok {
+			return fmt.Errorf("Step %d has unknown type %q", i+1, step.Step)
+		}
+		if seen[step.Step] {
+			return fmt.Errorf("Step %d: step %s is in the journey more than once", i+1, step.Step)
+		}
+		for _, required := range st.requires {
+			if !seen[required] {
+				return fmt.Errorf("Step %d: step %s requires step %s to run before it", i+1, step.Step, required)
+			}
+		}
+		if previous != nil && stepTypes[previous.Step].scope > st.scope {
+			return fmt.Errorf("Step %d: step %s can not run after step %s (application steps go first, then component steps, then user steps)", i+1, step.Step, previous.Step)
+		}
+		if step.Repeats < 0 || (step.Repeats > 1 && !st.repeatable) {
+			return fmt.Errorf("Step %d: step %s can not be repeated %d times (use 'applications' and 'components' to create more objects)", i+1, step.Step, step.Repeats)
+		}
+		if step.Timeout < 0 || step.ThinkTime < 0 {
+			return fmt.Errorf("Step %d: step %s has negative timeout or think time", i+1, step.Step)
+		}
+		if step.Step == StepRelease && step.ReleasePlan == "" {
+			return fmt.Errorf("Step %d: step %s requires 'releasePlan'", i+1, step.Step)
+		}
+		if step.Step != StepRelease && step.ReleasePlan != "" {
+			return fmt.Errorf("Step %d: 'releasePlan' is only valid for step %s", i+1, StepRelease)
+		}
+		seen[step.Step] = true
+		previous = step
+	}
+
+	return nil
+}
+
+// Check if the journey contains step of given type
+func (s *JourneySpec) Has(stepType string) bool {
+	for _, step := range s.Steps {
+		if step.Step == stepType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *JourneySpec) stepsOfScope(scope stepScope) []*Step {
+	var steps []*Step
+	for i := range s.Steps {
+		if stepTypes[s.Steps[i].Step].scope == scope {
+			steps = append(steps, &s.Steps[i])
+		}
+	}
+	return steps
+}
+
+// Run the step given number of times, sleeping for think time after each run
+func runStep(step *Step, run func() error) error {
+	for i := 0; i < step.Repeats; i++ {
+		err := run()
+		if err != nil {
+			return err
+		}
+		if step.ThinkTime > 0 {
+			logging.Logger.Trace("Thinking for %v after step %s", step.ThinkTime, step.Step)
+			time.Sleep(step.ThinkTime)
+		}
+	}
+	return nil
+}
+
+// Time left to finish the step started at given time
+func remaining(start time.Time, step *Step) time.Duration {
+	left := time.Until(start.Add(step.Timeout))
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// Run journey steps of application level
+func RunApplicationSteps(ctx *PerApplicationContext) error {
+	for _, step := range ctx.ParentContext.Spec.stepsOfScope(applicationScope) {
+		var handler func(*PerApplicationContext, *Step) error
+		var metric string
+		switch step.Step {
+		case StepCreateApplication:
+			handler, metric = HandleApplication, "HandleApplication"
+		case StepCreateIntegrationTestScenario:
+			handler, metric = HandleIntegrationTestScenario, "HandleIntegrationTestScenario"
+		}
+
+		err := runStep(step, func() error {
+			return logging.MeasureErr(ctx.Span, metric, logging.Labels{"step": step.Step}, func() error {
+				return handler(ctx, step)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run journey steps of component level
+func RunComponentSteps(ctx *PerComponentContext) error {
+	for _, step := range ctx.ParentContext.ParentContext.Spec.stepsOfScope(componentScope) {
+		var handler func(*PerComponentContext, *Step) error
+		var metric string
+		switch step.Step {
+		case StepCreateComponent:
+			handler, metric = HandleComponent, "HandleComponent"
+		case StepWaitBuild:
+			handler, metric = HandlePipelineRun, "HandlePipelineRun"
+		case StepWaitSnapshot:
+			handler, metric = HandleSnapshot, "HandleSnapshot"
+		case StepWaitTests:
+			handler, metric = HandleTest, "HandleTest"
+		case StepRelease:
+			handler, metric = HandleRelease, "HandleRelease"
+		}
+
+		err := runStep(step, func() error {
+			return logging.MeasureErr(ctx.Span, metric, logging.Labels{"step": step.Step}, func() error {
+				return handler(ctx, step)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run journey steps of user level, these run once all the journey repeats of the user are done
+func RunUserSteps(ctx *MainContext) error {
+	for _, step := range ctx.Spec.stepsOfScope(userScope) {
+		var handler func(*MainContext, *Step) error
+		var metric string
+		switch step.Step {
+		case StepPurge:
+			handler, metric = HandleUserPurge, "HandleUserPurge"
+		}
+
+		err := runStep(step, func() error {
+			return logging.MeasureErr(ctx.Span, metric, logging.Labels{"step": step.Step}, func() error {
+				return handler(ctx, step)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package journey
+
+import "time"
+
+import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+
+import pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+
+// Server side timestamps of objects created for the component. Unlike measured waits,
+// differences between them do not include polling intervals of the test.
+type componentTimeline struct {
+	ComponentCreated time.Time
+	PullConfigured   time.Time // PaC configured the component and opened the PR
+	BuildCreated     time.Time
+	BuildStarted     time.Time
+	BuildCompleted   time.Time
+	SnapshotCreated  time.Time
+	TestCreated      time.Time
+	TestStarted      time.Time
+	TestCompleted    time.Time
+}
+
+// Hop between two timeline timestamps, recorded as a metric once both are known
+type timelineHop struct {
+	metric string
+	from   func(t *componentTimeline) time.Time
+	to     func(t *componentTimeline) time.Time
+}
+
+var timelineHops = []timelineHop{
+	{"timelineComponentToPull", func(t *componentTimeline) time.Time { return t.ComponentCreated }, func(t *componentTimeline) time.Time { return t.PullConfigured }},
+	{"timelinePullToBuildCreated", func(t *componentTimeline) time.Time { return t.PullConfigured }, func(t *componentTimeline) time.Time { return t.BuildCreated }},
+	{"timelineBuildCreatedToStarted", func(t *componentTimeline) time.Time { return t.BuildCreated }, func(t *componentTimeline) time.Time { return t.BuildStarted }},
+	{"timelineBuildStartedToCompleted", func(t *componentTimeline) time.Time { return t.BuildStarted }, func(t *componentTimeline) time.Time { return t.BuildCompleted }},
+	{"timelineBuildCompletedToSnapshot", func(t *componentTimeline) time.Time { return t.BuildCompleted }, func(t *componentTimeline) time.Time { return t.SnapshotCreated }},
+	{"timelineSnapshotToTestCreated", func(t *componentTimeline) time.Time { return t.SnapshotCreated }, func(t *componentTimeline) time.Time { return t.TestCreated }},
+	{"timelineTestCreatedToStarted", func(t *componentTimeline) time.Time { return t.TestCreated }, func(t *componentTimeline) time.Time { return t.TestStarted }},
+	{"timelineTestStartedToCompleted", func(t *componentTimeline) time.Time { return t.TestStarted }, func(t *componentTimeline) time.Time { return t.TestCompleted }},
+	{"timelineComponentToTestCompleted", func(t *componentTimeline) time.Time { return t.ComponentCreated }, func(t *componentTimeline) time.Time { return t.TestCompleted }},
+}
+
+// Update timeline and return hops which just became known
+func (t *componentTimeline) update(change func(t *componentTimeline)) map[string]time.Duration {
+	before := *t
+	change(t)
+
+	hops := map[string]time.Duration{}
+	for _, hop := range timelineHops {
+		from, to := hop.from(t), hop.to(t)
+		if from.IsZero() || to.IsZero() {
+			continue
+		}
+		if !hop.from(&before).IsZero() && !hop.to(&before).IsZero() {
+			continue // already recorded
+		}
+		if to.Before(from) {
+			logging.Logger.Debug("Skipping %s as %v is before %v", hop.metric, to, from)
+			continue
+		}
+		hops[hop.metric] = to.Sub(from)
+	}
+	return hops
+}
+
+// Update timeline of the component and record hops which just became known
+func recordTimeline(ctx *PerComponentContext, change func(t *componentTimeline)) {
+	for metric, elapsed := range ctx.Timeline.update(change) {
+		logging.LogMeasurement(ctx.Span, metric, nil, elapsed, nil)
+	}
+}
+
+// Creation, start and completion timestamps of PipelineRun, zero when not yet known
+func pipelineRunTimes(pr *pipeline.PipelineRun) (time.Time, time.Time, time.Time) {
+	var started, completed time.Time
+	if pr.Status.StartTime != nil {
+		started = pr.Status.StartTime.Time
+	}
+	if pr.Status.CompletionTime != nil {
+		completed = pr.Status.CompletionTime.Time
+	}
+	return pr.CreationTimestamp.Time, started, completed
+}
@@ -0,0 +1,47 @@
+package journey
+
+import "testing"
+import "time"
+
+import "github.com/stretchr/testify/assert"
+
+import pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+func TestTimeline(t *testing.T) {
+	at := func(s int) time.Time { return time.Date(2024, 5, 23, 7, 0, s, 0, time.UTC) }
+	timeline := &componentTimeline{}
+
+	assert.Empty(t, timeline.update(func(t *componentTimeline) { t.ComponentCreated = at(0) }))
+	assert.Equal(t, map[string]time.Duration{"timelineComponentToPull": 3 * time.Second}, timeline.update(func(t *componentTimeline) { t.PullConfigured = at(3) }))
+
+	pr := &pipeline.PipelineRun{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(at(5))}}
+	pr.Status.StartTime = &metav1.Time{Time: at(6)}
+	created, started, completed := pipelineRunTimes(pr)
+	assert.True(t, completed.IsZero())
+	pr.Status.CompletionTime = &metav1.Time{Time: at(20)}
+	hops := timeline.update(func(t *componentTimeline) { t.BuildCreated, t.BuildStarted, t.BuildCompleted = pipelineRunTimes(pr) })
+	assert.Equal(t, map[string]time.Duration{
+		"timelinePullToBuildCreated":      2 * time.Second,
+		"timelineBuildCreatedToStarted":   time.Second,
+		"timelineBuildStartedToCompleted": 14 * time.Second,
+	}, hops)
+	assert.Equal(t, at(5), created)
+	assert.Equal(t, at(6), started)
+
+	// Hop already recorded is not recorded again, hop going back in time is skipped
+	hops = timeline.update(func(t *componentTimeline) { t.PullConfigured = at(4); t.SnapshotCreated = at(19) })
+	assert.Empty(t, hops)
+}
+
+func TestGetPaCPull(t *testing.T) {
+	pull, configured, err := getPaCPull(map[string]string{"build.appstudio.openshift.io/status": `{"pac":{"state":"enabled","merge-url":"https://github.com/org/repo/pull/1","configuration-time":"Thu, 23 May 2024 07:06:43 UTC"},"message":"done"}`})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/org/repo/pull/1", pull)
+	assert.True(t, configured.Equal(time.Date(2024, 5, 23, 7, 6, 43, 0, time.UTC)))
+
+	pull, configured, err = getPaCPull(map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", pull)
+	assert.True(t, configured.IsZero())
+}
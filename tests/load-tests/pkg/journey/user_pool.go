@@ -0,0 +1,89 @@
+package journey
+
+import "context"
+import "encoding/json"
+import "fmt"
+import "os"
+import "path/filepath"
+import "time"
+
+import logging "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/logging"
+import loadtestutils "github.com/konflux-ci/e2e-tests/tests/load-tests/pkg/loadtestutils"
+
+// Name of the user pool health report in output directory
+const UserPoolFile = "load-test-user-pool.json"
+
+// Pool of Stage users threads lease their users from, nil when not running on Stage
+var stageUserPool *loadtestutils.UserPool
+
+// Load Stage users from 'users.json', tokens of leased users are refreshed by their frameworks
+func openStageUserPool() error {
+	pool, err := loadtestutils.NewStageUserPool("users.json")
+	if err != nil {
+		return fmt.Errorf("Failed to load Stage users: %v", err)
+	}
+	pool.OnChange = func(health loadtestutils.PoolHealth) {
+		logging.SetUserPoolUsers(health.Available, health.Leased, health.Quarantined)
+	}
+	stageUserPool = pool
+	return nil
+}
+
+// Lease Stage user for the thread, resumed thread gets the user it had before
+func leaseStageUser(ctx *MainContext) (*loadtestutils.Lease, error) {
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+
+	holder := fmt.Sprintf("thread-%d", ctx.ThreadIndex)
+	if ctx.Username != "" {
+		return stageUserPool.AcquireUser(waitCtx, holder, ctx.Username)
+	}
+	return stageUserPool.Acquire(waitCtx, holder)
+}
+
+// Stop token refresh of all the frameworks of the user thread
+func stopFrameworks(ctx *MainContext) {
+	for _, appCtx := range ctx.PerApplicationContexts {
+		for _, compCtx := range appCtx.PerComponentContexts {
+			if compCtx.Framework != nil {
+				compCtx.Framework.Stop()
+			}
+		}
+		if appCtx.Framework != nil {
+			appCtx.Framework.Stop()
+		}
+	}
+	if ctx.Framework != nil {
+		ctx.Framework.Stop()
+	}
+}
+
+// Stop token refresh of users, return all leased users and store pool health report to output directory
+func CloseUserPool(outputDir string) {
+	if stageUserPool == nil {
+		return
+	}
+	for _, ctx := range MainContexts {
+		stopFrameworks(ctx)
+		if ctx.UserLease != nil {
+			ctx.UserLease.Release()
+			ctx.UserLease = nil
+		}
+	}
+
+	health := stageUserPool.Health()
+	for username, reason := range health.Reasons {
+		logging.Logger.Warning("User %s was quarantined: %s", username, reason)
+	}
+	logging.Logger.Info("User pool health: %d users, %d quarantined", health.Total, health.Quarantined)
+
+	data, err := json.MarshalIndent(health, "", "    ")
+	if err != nil {
+		logging.Logger.Error("Error marshalling user pool health: %v", err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(outputDir, UserPoolFile), data, 0600)
+	if err != nil {
+		logging.Logger.Error("Error writing to file: %v", err)
+	}
+}
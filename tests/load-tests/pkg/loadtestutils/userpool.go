@@ -0,0 +1,237 @@
+package loadtestutils
+
+import "context"
+import "fmt"
+import "sync"
+import "time"
+
+import sandbox "github.com/konflux-ci/e2e-tests/pkg/sandbox"
+
+// States of user in the pool
+const (
+	UserAvailable   = "available"
+	UserLeased      = "leased"
+	UserQuarantined = "quarantined"
+)
+
+// Something that can exchange offline token for access token, e.g. sandbox.SandboxController
+type TokenRefresher interface {
+	GetKeycloakTokenStage(userName, tokenURL, refreshToken string) (*sandbox.KeycloakAuth, error)
+}
+
+type pooledUser struct {
+	user        User
+	state       string
+	holder      string
+	reason      string // why the user was quarantined
+	accessToken string
+	expiry      time.Time
+	failures    int        // token refreshes failed in a row
+	lastErr     error      // error of the last failed token refresh
+	refreshLock sync.Mutex // only one token refresh of the user at a time
+}
+
+// Pool of precreated (e.g. Stage) users. Each user is leased to a single thread at a time,
+// its offline token is checked before it is leased and users with broken token or namespace
+// are quarantined so nobody uses them again. Access tokens of leased users are refreshed
+// by the token source of the framework created for the user.
+type UserPool struct {
+	lock               sync.Mutex
+	users              []*pooledUser
+	changed            chan struct{} // closed and replaced whenever some user is released
+	refresher          TokenRefresher
+	RefreshBefore      time.Duration           // refresh access token when it expires sooner than this
+	MaxRefreshFailures int                     // user is quarantined when released after this many token refreshes failed in a row
+	OnChange           func(health PoolHealth) // called whenever state of some user changes, e.g. to update metrics
+}
+
+// Lease of a user from the pool
+type Lease struct {
+	pool *UserPool
+	user *pooledUser
+}
+
+// Pool health report
+type PoolHealth struct {
+	Total       int               `json:"total"`
+	Available   int               `json:"available"`
+	Leased      int               `json:"leased"`
+	Quarantined int               `json:"quarantined"`
+	Reasons     map[string]string `json:"reasons,omitempty"` // why were users quarantined, by username
+}
+
+func NewUserPool(users []User, refresher TokenRefresher) *UserPool {
+	pool := &UserPool{
+		changed:            make(chan struct{}),
+		refresher:          refresher,
+		RefreshBefore:      time.Minute * 2,
+		MaxRefreshFailures: 3,
+	}
+	for _, u := range users {
+		pool.users = append(pool.users, &pooledUser{user: u, state: UserAvailable})
+	}
+	return pool
+}
+
+// Load users from given file (e.g. Stage 'users.json') to a pool refreshing tokens using Keycloak
+func NewStageUserPool(filePath string) (*UserPool, error) {
+	users, err := LoadStageUsers(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading users: %v", err)
+	}
+	controller, err := sandbox.NewDevSandboxStageController()
+	if err != nil {
+		return nil, fmt.Errorf("Error creating sandbox controller: %v", err)
+	}
+	return NewUserPool(users, controller), nil
+}
+
+// Lease any healthy user, blocks until some user is released or context is done
+func (p *UserPool) Acquire(ctx context.Context, holder string) (*Lease, error) {
+	return p.acquire(ctx, holder, func(u User) bool { return true })
+}
+
+// Lease user with given username, e.g. when resuming a run
+func (p *UserPool) AcquireUser(ctx context.Context, holder, username string) (*Lease, error) {
+	return p.acquire(ctx, holder, func(u User) bool { return u.Username == username })
+}
+
+func (p *UserPool) acquire(ctx context.Context, holder string, match func(User) bool) (*Lease, error) {
+	for {
+		p.lock.Lock()
+		var candidate *pooledUser
+		healthy := 0
+		for _, u := range p.users {
+			if !match(u.user) || u.state == UserQuarantined {
+				continue
+			}
+			healthy++
+			if u.state == UserAvailable {
+				candidate = u
+				break
+			}
+		}
+		if candidate == nil {
+			wait := p.changed
+			p.lock.Unlock()
+			if healthy == 0 {
+				return nil, fmt.Errorf("No healthy user available in the pool")
+			}
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return nil, fmt.Errorf("Timed out waiting for user from the pool: %v", ctx.Err())
+			}
+		}
+		candidate.state = UserLeased
+		candidate.holder = holder
+		p.lock.Unlock()
+		p.notify()
+
+		// Never hand out user with stale token, user failing repeatedly is quarantined on release
+		lease := &Lease{pool: p, user: candidate}
+		err := lease.refresh(false)
+		if err != nil {
+			lease.Release()
+			continue
+		}
+		return lease, nil
+	}
+}
+
+// User leased, including latest offline token
+func (l *Lease) User() User {
+	l.user.refreshLock.Lock()
+	defer l.user.refreshLock.Unlock()
+	return l.user.user
+}
+
+// Exchange offline token for access token unless current one is valid long enough
+func (l *Lease) refresh(force bool) error {
+	u := l.user
+	u.refreshLock.Lock()
+	defer u.refreshLock.Unlock()
+
+	if !force && u.accessToken != "" && time.Until(u.expiry) > l.pool.RefreshBefore {
+		return nil
+	}
+	if l.pool.refresher == nil {
+		return nil
+	}
+
+	auth, err := l.pool.refresher.GetKeycloakTokenStage(u.user.Username, u.user.SSOURL, u.user.Token)
+	if err == nil && (auth == nil || auth.AccessToken == "") {
+		err = fmt.Errorf("Keycloak returned no access token for user %s", u.user.Username)
+	}
+	if err != nil {
+		u.failures++
+		u.lastErr = err
+		return err
+	}
+	u.failures = 0
+	u.lastErr = nil
+	u.accessToken = auth.AccessToken
+	u.expiry = sandbox.TokenExpiry(auth.AccessToken)
+	if auth.RefreshToken != "" {
+		u.user.Token = auth.RefreshToken // offline tokens can be rotated by Keycloak
+	}
+	return nil
+}
+
+// Return the user to the pool, user whose token refresh failed too many times in a row is quarantined instead
+func (l *Lease) Release() {
+	l.user.refreshLock.Lock()
+	failures, lastErr := l.user.failures, l.user.lastErr
+	l.user.refreshLock.Unlock()
+
+	if l.pool.MaxRefreshFailures > 0 && failures >= l.pool.MaxRefreshFailures {
+		l.Quarantine(fmt.Sprintf("Token refresh failed %d times: %v", failures, lastErr))
+		return
+	}
+	l.setState(UserAvailable, "")
+}
+
+// Take the user out of the pool for good, e.g. because its token or namespace is broken
+func (l *Lease) Quarantine(reason string) {
+	l.setState(UserQuarantined, reason)
+}
+
+func (l *Lease) setState(state, reason string) {
+	p := l.pool
+	p.lock.Lock()
+	if l.user.state != UserQuarantined {
+		l.user.state = state
+		l.user.reason = reason
+	}
+	l.user.holder = ""
+	close(p.changed)
+	p.changed = make(chan struct{})
+	p.lock.Unlock()
+	p.notify()
+}
+
+func (p *UserPool) notify() {
+	if p.OnChange != nil {
+		p.OnChange(p.Health())
+	}
+}
+
+// Current state of the pool
+func (p *UserPool) Health() PoolHealth {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	health := PoolHealth{Total: len(p.users), Reasons: map[string]string{}}
+	for _, u := range p.users {
+		switch u.state {
+		case UserAvailable:
+			health.Available++
+		case UserLeased:
+			health.Leased++
+		case UserQuarantined:
+			health.Quarantined++
+			health.Reasons[u.user.Username] = u.reason
+		}
+	}
+	return health
+}
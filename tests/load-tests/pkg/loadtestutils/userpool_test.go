@@ -0,0 +1,90 @@
+package loadtestutils
+
+import "context"
+import "encoding/base64"
+import "fmt"
+import "testing"
+import "time"
+
+import sandbox "github.com/konflux-ci/e2e-tests/pkg/sandbox"
+
+import "github.com/stretchr/testify/assert"
+
+type fakeRefresher struct {
+	calls    map[string]int
+	broken   map[string]bool
+	failures map[string]int // number of next refreshes failing
+	expiry   time.Time
+}
+
+func (f *fakeRefresher) GetKeycloakTokenStage(userName, tokenURL, refreshToken string) (*sandbox.KeycloakAuth, error) {
+	f.calls[userName]++
+	if f.broken[userName] {
+		return nil, fmt.Errorf("invalid offline token")
+	}
+	if f.failures[userName] > 0 {
+		f.failures[userName]--
+		return nil, fmt.Errorf("service unavailable")
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, f.expiry.Unix())))
+	return &sandbox.KeycloakAuth{AccessToken: "eyJ." + payload + ".sig", RefreshToken: refreshToken + "-rotated"}, nil
+}
+
+func TestUserPool(t *testing.T) {
+	refresher := &fakeRefresher{calls: map[string]int{}, broken: map[string]bool{"user-1": true}, expiry: time.Now().Add(time.Hour)}
+	pool := NewUserPool([]User{{Username: "user-1", Token: "t1"}, {Username: "user-2", Token: "t2"}, {Username: "user-3", Token: "t3"}}, refresher)
+	ctx := context.Background()
+
+	// User with broken token is quarantined after repeated failures and next one leased
+	a, err := pool.Acquire(ctx, "thread-0")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-2", a.User().Username)
+	assert.Equal(t, "t2-rotated", a.User().Token)
+	assert.Equal(t, 3, refresher.calls["user-1"])
+	assert.Equal(t, PoolHealth{Total: 3, Leased: 1, Available: 1, Quarantined: 1, Reasons: map[string]string{"user-1": "Token refresh failed 3 times: invalid offline token"}}, pool.Health())
+
+	b, err := pool.Acquire(ctx, "thread-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-3", b.User().Username)
+
+	// Leased users are exclusive
+	timeout, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	_, err = pool.AcquireUser(timeout, "thread-2", "user-2")
+	assert.Error(t, err)
+
+	// Released user can be leased again, token valid long enough is not refreshed again
+	a.Release()
+	c, err := pool.AcquireUser(ctx, "thread-2", "user-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-2", c.User().Username)
+	assert.Equal(t, 1, refresher.calls["user-2"])
+
+	// No healthy users left
+	b.Quarantine("namespace not found")
+	c.Quarantine("namespace not found")
+	_, err = pool.Acquire(ctx, "thread-3")
+	assert.Error(t, err)
+	assert.Equal(t, 3, pool.Health().Quarantined)
+}
+
+func TestUserPoolTransientRefreshFailure(t *testing.T) {
+	refresher := &fakeRefresher{calls: map[string]int{}, failures: map[string]int{"user-1": 2}, expiry: time.Now()}
+	pool := NewUserPool([]User{{Username: "user-1", Token: "t1"}}, refresher)
+	ctx := context.Background()
+
+	// Failures below the limit don't take the user out of the pool
+	a, err := pool.Acquire(ctx, "thread-0")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", a.User().Username)
+	assert.Equal(t, 3, refresher.calls["user-1"])
+	a.Release()
+	assert.Equal(t, 0, pool.Health().Quarantined)
+
+	// Failures are counted in a row, the successful refresh reset them
+	refresher.failures["user-1"] = 2
+	b, err := pool.Acquire(ctx, "thread-1")
+	assert.NoError(t, err)
+	b.Release()
+	assert.Equal(t, PoolHealth{Total: 1, Available: 1, Reasons: map[string]string{}}, pool.Health())
+}
@@ -70,5 +70,6 @@ func (l *logger) Fail(errCode int, msg string, params ...interface{}) error {
 		Message:   fmt.Sprintf(errorMessage, params...),
 	}
 	errorsQueue <- data
+	observeFailure(errCode)
 	return fmt.Errorf(errorMessage, params...)
 }
@@ -0,0 +1,84 @@
+package logging
+
+import "sort"
+import "strconv"
+import "strings"
+import "sync/atomic"
+import "time"
+
+// Labels identifying what was measured, e.g. "thread", "iteration", "app" and "component"
+type Labels map[string]string
+
+// Labels as sorted "key:value" pairs separated by space, values redacted
+func (l Labels) String() string {
+	var keys []string
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		pairs = append(pairs, k+":"+redactLabel(k, l[k]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+var lastSpanID atomic.Uint64
+
+// Measured piece of work. Spans nest: span inherits labels of its parent and
+// remembers parent's ID, so e.g. component's build wait can be attributed to
+// the component, its application and its user journey.
+type Span struct {
+	ID       string
+	ParentID string
+	Metric   string
+	Labels   Labels
+	start    time.Time
+}
+
+// Start span with given metric name, labels are added to labels of parent span (parent can be nil)
+func StartSpan(parent *Span, metric string, labels Labels) *Span {
+	span := &Span{
+		ID:     strconv.FormatUint(lastSpanID.Add(1), 10),
+		Metric: metric,
+		Labels: Labels{},
+		start:  time.Now(),
+	}
+	if parent != nil {
+		span.ParentID = parent.ID
+		for k, v := range parent.Labels {
+			span.Labels[k] = v
+		}
+	}
+	for k, v := range labels {
+		span.Labels[k] = v
+	}
+	return span
+}
+
+// Finish the span and store its measurement
+func (s *Span) End(err error) {
+	logMeasurement(s, time.Since(s.start), err)
+}
+
+// Measure duration of given function as a child span of parent and return what the function returned
+func Measure[T any](parent *Span, metric string, labels Labels, fn func() (T, error)) (T, error) {
+	span := StartSpan(parent, metric, labels)
+	result, err := fn()
+	span.End(err)
+	return result, err
+}
+
+// Measure duration of given function which only returns error
+func MeasureErr(parent *Span, metric string, labels Labels, fn func() error) error {
+	_, err := Measure(parent, metric, labels, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// Store measurement of duration which was not measured by a span, e.g. difference of two timestamps
+func LogMeasurement(parent *Span, metric string, labels Labels, elapsed time.Duration, err error) {
+	logMeasurement(StartSpan(parent, metric, labels), elapsed, err)
+}
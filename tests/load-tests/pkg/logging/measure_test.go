@@ -0,0 +1,62 @@
+package logging
+
+import "encoding/json"
+import "fmt"
+import "os"
+import "strings"
+import "testing"
+
+import "github.com/stretchr/testify/assert"
+
+func TestMeasure(t *testing.T) {
+	dir := t.TempDir()
+	MeasurementsStart(dir)
+
+	user := StartSpan(nil, "perUserThread", Labels{"thread": "0"})
+	component := StartSpan(user, "perComponentThread", Labels{"component": "comp-0", "token": "secret"})
+	number, err := Measure(component, "getPaCPullNumber", Labels{"repo": "https://ghp_abc123@github.com/org/repo"}, func() (int, error) {
+		return 42, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, number)
+	err = MeasureErr(component, "validatePipelineRunCondition", nil, func() error {
+		return fmt.Errorf("Failed with token sha256~abcDEF-123")
+	})
+	assert.Error(t, err)
+	component.End(nil)
+	user.End(nil)
+	assert.Error(t, Logger.Fail(10, "Failed to log in with %s", "Bearer ghp_abc123"))
+
+	MeasurementsStop()
+
+	data, err := os.ReadFile(dir + "/load-test-timings.jsonl")
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 4)
+
+	entries := map[string]measurementJSON{}
+	for _, line := range lines {
+		var entry measurementJSON
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries[entry.Metric] = entry
+	}
+
+	pull := entries["getPaCPullNumber"]
+	assert.Equal(t, component.ID, pull.ParentSpanID)
+	assert.Equal(t, user.ID, entries["perComponentThread"].ParentSpanID)
+	assert.Equal(t, map[string]string{"thread": "0", "component": "comp-0", "token": "redacted", "repo": "https://redacted@github.com/org/repo"}, pull.Labels)
+	assert.Nil(t, pull.Error)
+	assert.Equal(t, "Failed with token redacted", *entries["validatePipelineRunCondition"].Error)
+
+	csv, err := os.ReadFile(dir + "/load-test-timings.csv")
+	assert.NoError(t, err)
+	assert.Contains(t, string(csv), ",getPaCPullNumber,")
+	assert.Contains(t, string(csv), ",component:comp-0 repo:https://redacted@github.com/org/repo thread:0 token:redacted,<nil>,")
+	assert.NotContains(t, string(csv), "secret")
+	assert.NotContains(t, string(csv), "sha256~")
+
+	errors, err := os.ReadFile(dir + "/load-test-errors.csv")
+	assert.NoError(t, err)
+	assert.Contains(t, string(errors), ",10,FAIL(10): Failed to log in with redacted")
+	assert.NotContains(t, string(errors), "ghp_")
+}
@@ -0,0 +1,138 @@
+package logging
+
+import "context"
+import "errors"
+import "fmt"
+import "net/http"
+import "path"
+import "strconv"
+import "time"
+
+import prometheus "github.com/prometheus/client_golang/prometheus"
+import promhttp "github.com/prometheus/client_golang/prometheus/promhttp"
+
+// Registry with live load test metrics, exposed over HTTP by MetricsStart
+var MetricsRegistry = prometheus.NewRegistry()
+
+var metricsServer *http.Server
+
+var (
+	measurementDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "load_test_measurement_duration_seconds",
+		Help:    "Duration of measured functions",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 17), // 0.1s to ~1.8h
+	}, []string{"function", "result"})
+
+	failuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "load_test_failures_total",
+		Help: "Failures logged by Logger.Fail by error code",
+	}, []string{"code"})
+
+	activeThreads = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "load_test_active_threads",
+		Help: "Number of running threads by journey level (user, application, component)",
+	}, []string{"level"})
+
+	journeyIterations = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "load_test_journey_iterations_total",
+		Help: "Number of finished user journey iterations",
+	})
+
+	droppedIterations = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "load_test_dropped_iterations_total",
+		Help: "Number of journey iterations not started by arrival-rate load profile because no user thread was free",
+	})
+
+	userPoolUsers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "load_test_user_pool_users",
+		Help: "Number of users in the Stage user pool by state (available, leased, quarantined)",
+	}, []string{"state"})
+)
+
+func init() {
+	MetricsRegistry.MustRegister(measurementDuration, failuresTotal, activeThreads, journeyIterations, droppedIterations, userPoolUsers)
+}
+
+// Start HTTP server exposing metrics on "/metrics" at given address, e.g. ":9090"
+func MetricsStart(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(MetricsRegistry, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: time.Second * 10,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	// Give the server a moment to fail on e.g. address already in use
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("Failed to start metrics server on %s: %v", address, err)
+	case <-time.After(time.Millisecond * 100):
+	}
+	metricsServer = server
+
+	go func() {
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			Logger.Error("Metrics server failed: %v", err)
+		}
+	}()
+
+	Logger.Info("Serving metrics on %s/metrics", address)
+	return nil
+}
+
+// Stop metrics HTTP server if it was started
+func MetricsStop() {
+	if metricsServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		Logger.Warning("Failed to stop metrics server: %v", err)
+	}
+	metricsServer = nil
+}
+
+// Record measurement of a metric, metric name is shortened to last path element in case it is full function name
+func observeMeasurement(metric string, elapsed time.Duration, err error) {
+	result := "pass"
+	if err != nil {
+		result = "fail"
+	}
+	measurementDuration.WithLabelValues(path.Base(metric), result).Observe(elapsed.Seconds())
+}
+
+func observeFailure(errCode int) {
+	failuresTotal.WithLabelValues(strconv.Itoa(errCode)).Inc()
+}
+
+// Count running thread of given journey level, call returned function when the thread finishes
+func TrackThread(level string) func() {
+	gauge := activeThreads.WithLabelValues(level)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// Count finished user journey iteration
+func JourneyIterationDone() {
+	journeyIterations.Inc()
+}
+
+// Count journey iteration which was not started because no user thread was free
+func JourneyIterationDropped() {
+	droppedIterations.Inc()
+}
+
+// Record number of users in the user pool by state
+func SetUserPoolUsers(available, leased, quarantined int) {
+	userPoolUsers.WithLabelValues("available").Set(float64(available))
+	userPoolUsers.WithLabelValues("leased").Set(float64(leased))
+	userPoolUsers.WithLabelValues("quarantined").Set(float64(quarantined))
+}
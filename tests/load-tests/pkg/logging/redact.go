@@ -0,0 +1,50 @@
+package logging
+
+import "fmt"
+import "regexp"
+import "sync"
+
+const redacted = "redacted"
+
+// Labels with matching keys are never stored
+var redactKeys = regexp.MustCompile(`(?i)token|password|secret|credential|kubeconfig`)
+
+// Matching parts of label values and error messages are replaced before storing
+var redactValues = []*regexp.Regexp{
+	regexp.MustCompile(`sha256~[\w-]+`),                // OpenShift OAuth tokens
+	regexp.MustCompile(`gh[pousr]_\w+`),                // GitHub tokens
+	regexp.MustCompile(`(?i)bearer\s+[\w.~+/=-]+`),     // Authorization headers
+	regexp.MustCompile(`eyJ[\w-]+\.eyJ[\w-]+\.[\w-]*`), // JWTs, e.g. Keycloak tokens
+}
+var redactLock sync.RWMutex
+
+// Add regular expressions of secrets to be redacted from stored measurements (see '--redact')
+func AddRedactPatterns(patterns []string) error {
+	redactLock.Lock()
+	defer redactLock.Unlock()
+	for _, p := range patterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("Invalid redact pattern %q: %v", p, err)
+		}
+		redactValues = append(redactValues, r)
+	}
+	return nil
+}
+
+// Replace secrets in given string
+func Redact(s string) string {
+	redactLock.RLock()
+	defer redactLock.RUnlock()
+	for _, r := range redactValues {
+		s = r.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+func redactLabel(key, value string) string {
+	if redactKeys.MatchString(key) {
+		return redacted
+	}
+	return Redact(value)
+}
@@ -2,37 +2,71 @@ package logging
 
 import "fmt"
 import "path/filepath"
-import "reflect"
-import "runtime"
-import "sort"
-import "strings"
 import "time"
 import "os"
 import "encoding/csv"
+import "encoding/json"
 import "sync"
 
 var measurementsQueue chan MeasurementEntry // channel to send measurements to
 var errorsQueue chan ErrorEntry // chanel to send failures to
 
 var measurementsOutput string // path to CSV where to save measurements
+var measurementsJSONOutput string // path to JSON lines file where to save measurements
 var errorsOutput string // path to CSV where to save measurements
 
 var writerWaitGroup sync.WaitGroup
 
+var measurementListeners []func(MeasurementEntry) // functions called with every measurement, e.g. to evaluate SLOs while the test runs
+var measurementListenersLock sync.Mutex
+
 var batchSize int // when we accumulate this many of records, we dump them to CSV (this is to batch writest to the file, possibly make it faster)
 
-// Represents the data about measurement we want to store to CSV
+// Represents the data about measurement we want to store to CSV and JSON lines
 type MeasurementEntry struct {
-	Timestamp  time.Time
-	Metric     string
-	Duration   time.Duration
-	Parameters string
-	Error      error
+	Timestamp    time.Time
+	Metric       string
+	Duration     time.Duration
+	Labels       Labels
+	SpanID       string
+	ParentSpanID string
+	Error        error
 }
 
 // Helper function to convert struct to slice of string which is needed when converting to CSV
 func (e *MeasurementEntry) GetSliceOfStrings() []string {
-	return []string{e.Timestamp.Format(time.RFC3339Nano), e.Metric, fmt.Sprintf("%f", e.Duration.Seconds()), e.Parameters, fmt.Sprintf("%v", e.Error)}
+	return []string{e.Timestamp.Format(time.RFC3339Nano), e.Metric, fmt.Sprintf("%f", e.Duration.Seconds()), e.Labels.String(), Redact(fmt.Sprintf("%v", e.Error)), e.SpanID, e.ParentSpanID}
+}
+
+// Shape of measurement in JSON lines file
+type measurementJSON struct {
+	Timestamp    time.Time         `json:"timestamp"`
+	Metric       string            `json:"metric"`
+	Duration     float64           `json:"duration"`
+	Labels       map[string]string `json:"labels"`
+	SpanID       string            `json:"span"`
+	ParentSpanID string            `json:"parent,omitempty"`
+	Error        *string           `json:"error"`
+}
+
+// Helper function to convert struct to single line of JSON
+func (e *MeasurementEntry) GetJSON() ([]byte, error) {
+	data := measurementJSON{
+		Timestamp:    e.Timestamp,
+		Metric:       e.Metric,
+		Duration:     e.Duration.Seconds(),
+		Labels:       map[string]string{},
+		SpanID:       e.SpanID,
+		ParentSpanID: e.ParentSpanID,
+	}
+	for k, v := range e.Labels {
+		data.Labels[k] = redactLabel(k, v)
+	}
+	if e.Error != nil {
+		msg := Redact(e.Error.Error())
+		data.Error = &msg
+	}
+	return json.Marshal(data)
 }
 
 // Represents the data about failure we want to store to CSV
@@ -44,7 +78,7 @@ type ErrorEntry struct {
 
 // Helper function to convert struct to slice of string which is needed when converting to CSV
 func (e *ErrorEntry) GetSliceOfStrings() []string {
-	return []string{e.Timestamp.Format(time.RFC3339Nano), fmt.Sprintf("%d", e.Code), e.Message}
+	return []string{e.Timestamp.Format(time.RFC3339Nano), fmt.Sprintf("%d", e.Code), Redact(e.Message)}
 }
 
 
@@ -56,6 +90,7 @@ func MeasurementsStart(directory string) {
 
 	measurementsQueue = make(chan MeasurementEntry)
 	measurementsOutput = directory + "/load-test-timings.csv"
+	measurementsJSONOutput = directory + "/load-test-timings.jsonl"
 	go measurementsWriter()
 
 	errorsQueue = make(chan ErrorEntry)
@@ -91,12 +126,53 @@ func writeToCSV(outfile string, batch [][]string) error {
 	return nil
 }
 
-// Process measurements comming via channel, batching writes to CSV file
+// Append lines to a JSON lines file
+func writeToJSONLines(outfile string, batch [][]byte) error {
+	outfile = filepath.Clean(outfile)
+	file, err := os.OpenFile(outfile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, d := range batch {
+		if _, err := file.Write(append(d, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Process measurements comming via channel, batching writes to CSV and JSON lines files
 func measurementsWriter() {
 	defer writerWaitGroup.Done()
 
 	var counter int
-	var batch [][]string
+	var batch []MeasurementEntry
+
+	write := func() {
+		var rows [][]string
+		var lines [][]byte
+		for i := range batch {
+			rows = append(rows, batch[i].GetSliceOfStrings())
+			line, err := batch[i].GetJSON()
+			if err != nil {
+				Logger.Error("Error marshalling measurement: %v", err)
+				continue
+			}
+			lines = append(lines, line)
+		}
+		err := writeToCSV(measurementsOutput, rows)
+		if err != nil {
+			Logger.Error("Error writing to CSV file: %v", err)
+		}
+		err = writeToJSONLines(measurementsJSONOutput, lines)
+		if err != nil {
+			Logger.Error("Error writing to JSON lines file: %v", err)
+		}
+		batch = nil
+	}
 
 	for {
 		event, ok := <-measurementsQueue
@@ -104,23 +180,16 @@ func measurementsWriter() {
 			// Handle channel closure
 			break
 		}
-		batch = append(batch, event.GetSliceOfStrings())
+		batch = append(batch, event)
 		counter++
 		if len(batch) == batchSize {
-			err := writeToCSV(measurementsOutput, batch)
-			if err != nil {
-				Logger.Error("Error writing to CSV file: %v", err)
-			}
-			batch = make([][]string, batchSize)
+			write()
 		}
 	}
 
-	// Write any remaining data to the CSV file
+	// Write any remaining data to the files
 	if len(batch) > 0 {
-		err := writeToCSV(measurementsOutput, batch)
-		if err != nil {
-			Logger.Error("Error writing to CSV file: %v", err)
-		}
+		write()
 	}
 
 	Logger.Debug("Finished measurementsWriter, %d measurements processed", counter)
@@ -147,7 +216,7 @@ func errorsWriter() {
 			if err != nil {
 				Logger.Error("Error writing to CSV file: %v", err)
 			}
-			batch = make([][]string, batchSize)
+			batch = nil
 		}
 	}
 
@@ -162,96 +231,30 @@ func errorsWriter() {
 	Logger.Debug("Finished errorsWriter, %d errors processed", counter)
 }
 
-// Measure duration of a given function run with given parameters and return what function returned
-// This only returns first (data) and last (error) returned value. Maybe this
-// can be generalized completely, but it is good enough for our needs.
-func Measure(fn interface{}, params ...interface{}) (interface{}, error) {
-	funcValue := reflect.ValueOf(fn)
-
-	// Construct arguments for the function call
-	numParams := len(params)
-	args := make([]reflect.Value, numParams)
-	for i := 0; i < numParams; i++ {
-		args[i] = reflect.ValueOf(params[i])
-	}
-
-	// Create map of parameters with key being parameter type (I do not
-	// know how to access parameter name) and value parameter value
-	// Because of that we are adding index to key to ensure we capture
-	// all params even when multiple of params have same type.
-	paramsStorable := make(map[string]string)
-	for i := 0; i < numParams; i++ {
-		x := 1
-		key := fmt.Sprintf("%v", reflect.TypeOf(params[i]))
-		value := fmt.Sprintf("%+v", reflect.ValueOf(params[i]))
-		for {
-			keyFull := key + fmt.Sprint(x)
-			if _, ok := paramsStorable[keyFull]; !ok {
-				paramsStorable[keyFull] = value
-				break
-			}
-			x++
-		}
-	}
-
-	var errInterValue error
-	var resultInterValue interface{}
-
-	// Get function name
-	funcName := runtime.FuncForPC(funcValue.Pointer()).Name()
-
-	startTime := time.Now()
-
-	defer func() {
-		elapsed := time.Since(startTime)
-		LogMeasurement(funcName, paramsStorable, elapsed, fmt.Sprintf("%+v", resultInterValue), errInterValue)
-	}()
-
-	// Call the function with provided arguments
-	results := funcValue.Call(args)
-
-	// Extract and return results
-	if len(results) == 0 {
-		return nil, nil
-	}
-	errInter := results[len(results)-1]
-	if errInter.Interface() == nil {
-		resultInterValue = results[0].Interface()
-		return resultInterValue, nil
-	}
-	errInterValue = errInter.Interface().(error)
-	return nil, errInterValue
+// Register function to be called with every measurement
+func AddMeasurementListener(fn func(MeasurementEntry)) {
+	measurementListenersLock.Lock()
+	defer measurementListenersLock.Unlock()
+	measurementListeners = append(measurementListeners, fn)
 }
 
-// Store given measurement
-func LogMeasurement(metric string, params map[string]string, elapsed time.Duration, result string, err error) {
-	// Extract parameter keys into a slice so we can sort them
-	var paramsKeys []string
-	for k := range params {
-		paramsKeys = append(paramsKeys, k)
-	}
-
-	// Sort parameter keys alphabetically
-	sort.Strings(paramsKeys)
-
-	// Construct string showing parameters except for framework that contains token, so we hide it
-	var params_string string = ""
-	for _, k := range paramsKeys {
-		if strings.HasPrefix(k, "*framework.Framework") {
-			params_string = params_string + fmt.Sprintf(" %s:redacted", k)
-		} else {
-			params_string = params_string + fmt.Sprintf(" %s:%s", k, params[k])
-		}
-	}
-	params_string = strings.TrimLeft(params_string, " ")
-
-	Logger.Trace("Measured function: %s, Duration: %s, Params: %s, Result: %s, Error: %v\n", metric, elapsed, params_string, result, err)
+// Store measurement of given span
+func logMeasurement(span *Span, elapsed time.Duration, err error) {
+	Logger.Trace("Measured function: %s, Duration: %s, Labels: %s, Error: %v\n", span.Metric, elapsed, span.Labels, err)
+	observeMeasurement(span.Metric, elapsed, err)
 	data := MeasurementEntry{
-		Timestamp:  time.Now(),
-		Metric:     metric,
-		Duration:   elapsed,
-		Parameters: params_string,
-		Error:      err,
+		Timestamp:    time.Now(),
+		Metric:       span.Metric,
+		Duration:     elapsed,
+		Labels:       span.Labels,
+		SpanID:       span.ID,
+		ParentSpanID: span.ParentID,
+		Error:        err,
+	}
+	measurementListenersLock.Lock()
+	for _, fn := range measurementListeners {
+		fn(data)
 	}
+	measurementListenersLock.Unlock()
 	measurementsQueue <- data
 }
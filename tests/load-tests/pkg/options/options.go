@@ -5,10 +5,21 @@ import "fmt"
 import "os"
 import "time"
 
+// Load profiles, see '--load-profile'
+const (
+	LoadProfileConstant    = "constant"
+	LoadProfileRampUp      = "ramp-up"
+	LoadProfileArrivalRate = "arrival-rate"
+	LoadProfileStep        = "step"
+)
+
 // Struct to hold command line options
 type Opts struct {
 	ApplicationsCount             int
+	ArrivalRate                   float64
 	BuildPipelineSelectorBundle   string
+	ClusterSampleInterval         time.Duration
+	ClusterSampleNamespaces       []string
 	ComponentContainerContext     string
 	ComponentContainerFile        string
 	ComponentRepoRevision         string
@@ -18,17 +29,30 @@ type Opts struct {
 	FailFast                      bool
 	JourneyDuration               string
 	JourneyRepeats                int
+	JourneySpecFile               string
 	JourneyUntil                  time.Time
+	LoadProfile                   string
 	LogDebug                      bool
 	LogTrace                      bool
 	LogInfo                       bool
+	MetricsAddress                string
 	OutputDir                     string
 	PipelineMintmakerDisabled     bool
 	PipelineRepoTemplating        bool
 	Purge                         bool
 	PurgeOnly                     bool
 	QuayRepo                      string
+	RampUpDuration                time.Duration
+	RedactPatterns                []string
+	Resume                        bool
+	SloFile                       string
+	SloMaxErrorRate               float64
+	SloMetric                     string
+	SloPercentile                 float64
+	SloThreshold                  float64
 	Stage                         bool
+	StepConcurrency               []int
+	StepDuration                  time.Duration
 	TestScenarioGitURL            string
 	TestScenarioPathInRepo        string
 	TestScenarioRevision          string
@@ -51,6 +75,33 @@ func (o *Opts) ProcessOptions() error {
 		o.Purge = true
 	}
 
+	// Check options of chosen load profile
+	switch o.LoadProfile {
+	case LoadProfileConstant:
+	case LoadProfileRampUp:
+		if o.RampUpDuration <= 0 {
+			return fmt.Errorf("Load profile %s requires positive '--ramp-up-duration'", o.LoadProfile)
+		}
+	case LoadProfileArrivalRate:
+		if o.ArrivalRate <= 0 {
+			return fmt.Errorf("Load profile %s requires positive '--arrival-rate'", o.LoadProfile)
+		}
+	case LoadProfileStep:
+		if o.StepDuration <= 0 || len(o.StepConcurrency) == 0 {
+			return fmt.Errorf("Load profile %s requires positive '--step-duration' and non-empty '--step-concurrency'", o.LoadProfile)
+		}
+		for i, c := range o.StepConcurrency {
+			if c <= 0 || (i > 0 && c <= o.StepConcurrency[i-1]) {
+				return fmt.Errorf("Option '--step-concurrency' has to be increasing list of positive numbers")
+			}
+		}
+		if o.StepConcurrency[0] > o.Concurrency {
+			return fmt.Errorf("First step of '--step-concurrency' is above '--concurrency' %d", o.Concurrency)
+		}
+	default:
+		return fmt.Errorf("Unknown load profile %q", o.LoadProfile)
+	}
+
 	// Convert options struct to pretty JSON
 	jsonOptions, err2 := json.MarshalIndent(o, "", "  ")
 	if err2 != nil {
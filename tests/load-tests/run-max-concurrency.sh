@@ -1,20 +1,23 @@
 #!/bin/bash
 export MY_GITHUB_ORG GITHUB_TOKEN
 
+# Max concurrency is searched in-process by the 'step' load profile of loadtest.go: concurrency is increased
+# per MAX_CONCURRENCY_STEPS every STEP_DURATION until the KPI crosses THRESHOLD or the error rate THRESHOLD_ERR.
+# The outcome, including results of every step, is stored to load-test.max-concurrency.json.
+
 OUTPUT_DIR="${OUTPUT_DIR:-.}"
 USER_PREFIX=${USER_PREFIX:-testuser}
 
-export TEKTON_PERF_PROFILE_CPU_PERIOD=${TEKTON_PERF_PROFILE_CPU_PERIOD:-${THRESHOLD:-300}}
+if [ "${RUN_ON_STAGE:-}" == "true" ]; then
+    export THRESHOLD=${THRESHOLD:-3500}
+    export THRESHOLD_ERR=${THRESHOLD_ERR:-30}
+fi
 
-OPENSHIFT_API="${OPENSHIFT_API:-$(yq '.clusters[0].cluster.server' "$KUBECONFIG")}"
-OPENSHIFT_USERNAME="${OPENSHIFT_USERNAME:-kubeadmin}"
-OPENSHIFT_PASSWORD="${OPENSHIFT_PASSWORD:-$(cat "$KUBEADMIN_PASSWORD_FILE")}"
+export TEKTON_PERF_PROFILE_CPU_PERIOD=${TEKTON_PERF_PROFILE_CPU_PERIOD:-${THRESHOLD:-300}}
 
-load_test() {
-    local workdir threads index
+start_profiling() {
+    local workdir
     workdir=${1:-/tmp}
-    threads=${2:-1}
-    index=$(printf "%04d" "$threads")
     ## Enable CPU profiling in Tekton
     if [ "${TEKTON_PERF_ENABLE_CPU_PROFILING:-}" == "true" ]; then
         echo "Starting CPU profiling with pprof"
@@ -48,6 +51,68 @@ load_test() {
             echo $! >"$workdir/$file.pid"
         done
     fi
+}
+
+finish_profiling() {
+    local workdir
+    workdir=${1:-/tmp}
+    if [ "${TEKTON_PERF_ENABLE_CPU_PROFILING:-}" == "true" ] || [ "${TEKTON_PERF_ENABLE_MEMORY_PROFILING:-}" == "true" ]; then
+        echo "[$(date --utc -Ins)] Waiting for the Tekton profiling to finish up to ${TEKTON_PERF_PROFILE_CPU_PERIOD}s"
+        for pid_file in $(find "$workdir" -name 'tekton*.pid'); do
+            wait "$(cat "$pid_file")"
+            rm -rvf "$pid_file"
+        done
+        echo "[$(date --utc -Ins)] Getting Tekton controller goroutine dump"
+        for p in $(oc get pods -n openshift-pipelines -l app=tekton-pipelines-controller -o name); do
+            pod="${p##*/}"
+            for i in 0 1 2; do
+                file="tekton-pipelines-controller.$pod.goroutine-dump-$i"
+                oc exec -n tekton-results "$p" -- bash -c "curl -SsL localhost:8008/debug/pprof/goroutine?debug=$i | base64" | base64 -d >"$workdir/$file.pprof"
+            done
+        done
+        echo "[$(date --utc -Ins)] Getting Tekton results watcher goroutine dump"
+        for p in $(oc get pods -n tekton-results -l app.kubernetes.io/name=tekton-results-watcher -o name); do
+            pod="${p##*/}"
+            for i in 0 1 2; do
+                file="tekton-results-watcher.$pod.goroutine-dump-$i"
+                oc exec -n tekton-results "$p" -c watcher -- bash -c "curl -SsL localhost:8008/debug/pprof/goroutine?debug=$i | base64" | base64 -d >"$workdir/$file.pprof"
+            done
+        done
+    fi
+}
+
+max_concurrency() {
+    local workdir
+    workdir="$OUTPUT_DIR"
+    environment_options=()
+    if [ "${RUN_ON_STAGE:-}" == "true" ]; then
+        echo "[$(date --utc -Ins)] Starting max-concurrency test on Stage"
+        environment_options+=(--stage)
+        component_repo_default="https://github.com/rhtap-perf-test/nodejs-devfile-sample"
+    else
+        # Max length of compliant username is 20 characters. We add "-XXXX" suffix for the test users' name so max length of the prefix is 15.
+        # See https://github.com/codeready-toolchain/toolchain-common/blob/master/pkg/usersignup/usersignup.go#L16
+        if [ ${#USER_PREFIX} -gt 15 ]; then
+            echo "Maximal allowed length of user prefix is 15 characters. The '$USER_PREFIX' length of ${#USER_PREFIX} exceeds the limit."
+            exit 1
+        fi
+        environment_options+=(--username "$USER_PREFIX")
+        component_repo_default="https://github.com/nodeshift-starters/devfile-sample"
+    fi
+
+    {
+        python3 -m venv venv
+        set +u
+        source venv/bin/activate
+        set -u
+        python3 -m pip install -U pip
+        python3 -m pip install -e "git+https://github.com/redhat-performance/opl.git#egg=opl-rhcloud-perf-team-core&subdirectory=core"
+        python3 -m pip install tabulate
+        python3 -m pip install matplotlib
+        deactivate
+    } &>"$OUTPUT_DIR/monitoring-setup.log"
+
+    start_profiling "$workdir"
     rm -rvf "$workdir/load-test.json"
     rm -rvf "$workdir/load-test.log"
 
@@ -55,27 +120,32 @@ load_test() {
     go run loadtest.go \
         --applications-count "${APPLICATIONS_COUNT:-1}" \
         --build-pipeline-selector-bundle "${BUILD_PIPELINE_SELECTOR_BUNDLE:-}" \
-        --component-repo "${COMPONENT_REPO:-https://github.com/nodeshift-starters/devfile-sample}" \
+        --component-repo "${COMPONENT_REPO:-$component_repo_default}" \
         --component-repo-container-context "${COMPONENT_REPO_CONTAINER_CONTEXT:-/}" \
         --component-repo-container-file "${COMPONENT_REPO_CONTAINER_FILE:-Dockerfile}" \
         --component-repo-revision "${COMPONENT_REPO_REVISION:-main}" \
         --components-count "${COMPONENTS_COUNT:-1}" \
-        --concurrency "$threads" \
-        --journey-duration "${JOURNEY_DURATION:-1h}" \
-        --journey-repeats "${JOURNEY_REPEATS:-1}" \
+        --concurrency "${MAX_THREADS:-10}" \
+        --load-profile step \
+        --step-concurrency "$(echo "${MAX_CONCURRENCY_STEPS:-1 5 10 25 50 100 150 200}" | tr -s ' ' ',')" \
+        --step-duration "${STEP_DURATION:-30m}" \
+        --slo-metric KPI \
+        --slo-threshold "${THRESHOLD:-300}" \
+        --slo-max-error-rate "${THRESHOLD_ERR:-10}" \
+        --journey-duration "${JOURNEY_DURATION:-24h}" \
         --log-info \
         --pipeline-repo-templating="${PIPELINE_REPO_TEMPLATING:-false}" \
-        --output-dir "${workdir:-/tmp}" \
+        --output-dir "$workdir" \
         --purge="${PURGE:-true}" \
         --quay-repo "${QUAY_REPO:-stonesoup_perfscale}" \
         --test-scenario-git-url "${TEST_SCENARIO_GIT_URL:-https://github.com/konflux-ci/integration-examples.git}" \
         --test-scenario-path-in-repo "${TEST_SCENARIO_PATH_IN_REPO:-pipelines/integration_resolver_pipeline_pass.yaml}" \
         --test-scenario-revision "${TEST_SCENARIO_REVISION:-main}" \
-        --username "$USER_PREFIX-$index" \
         --waitintegrationtestspipelines="${WAIT_INTEGRATION_TESTS:-true}" \
         --waitpipelines="${WAIT_PIPELINES:-true}" \
+        "${environment_options[@]}" \
         2>&1 | tee "$workdir/load-test.log"
-    
+
     # Capture and exit if there are unexpected errors in loadtest.go
     LOADTEST_EXIT_STATUS=${PIPESTATUS[0]}
     if [ ${LOADTEST_EXIT_STATUS} -ne 0 ]; then
@@ -90,7 +160,7 @@ load_test() {
     set -u
 
     echo "[$(date --utc -Ins)] Create summary JSON with timings"
-    ./evaluate.py "$workdir/load-test-timings.csv" "$workdir/load-test-timings.json"
+    go run loadtest.go evaluate --output-dir "$workdir"
 
     echo "[$(date --utc -Ins)] Creating main status data file"
     STATUS_DATA_FILE="$workdir/load-test.json"
@@ -101,134 +171,10 @@ load_test() {
 
     deactivate
 
-    if [ "${TEKTON_PERF_ENABLE_CPU_PROFILING:-}" == "true" ] || [ "${TEKTON_PERF_ENABLE_MEMORY_PROFILING:-}" == "true" ]; then
-        echo "[$(date --utc -Ins)] Waiting for the Tekton profiling to finish up to ${TEKTON_PERF_PROFILE_CPU_PERIOD}s"
-        for pid_file in $(find "$workdir" -name 'tekton*.pid'); do
-            wait "$(cat "$pid_file")"
-            rm -rvf "$pid_file"
-        done
-        echo "[$(date --utc -Ins)] Getting Tekton controller goroutine dump"
-        for p in $(oc get pods -n openshift-pipelines -l app=tekton-pipelines-controller -o name); do
-            pod="${p##*/}"
-            for i in 0 1 2; do
-                file="tekton-pipelines-controller.$pod.goroutine-dump-$i"
-                oc exec -n tekton-results "$p" -- bash -c "curl -SsL localhost:8008/debug/pprof/goroutine?debug=$i | base64" | base64 -d >"$workdir/$file.pprof"
-            done
-        done
-        echo "[$(date --utc -Ins)] Getting Tekton results watcher goroutine dump"
-        for p in $(oc get pods -n tekton-results -l app.kubernetes.io/name=tekton-results-watcher -o name); do
-            pod="${p##*/}"
-            for i in 0 1 2; do
-                file="tekton-results-watcher.$pod.goroutine-dump-$i"
-                oc exec -n tekton-results "$p" -c watcher -- bash -c "curl -SsL localhost:8008/debug/pprof/goroutine?debug=$i | base64" | base64 -d >"$workdir/$file.pprof"
-            done
-        done
-    fi
+    finish_profiling "$workdir"
 
+    echo "[$(date --utc -Ins)] Max concurrency reached: $(jq '.maxConcurrencyReached' "$workdir/load-test.max-concurrency.json"), computed concurrency: $(jq '.computedConcurrency' "$workdir/load-test.max-concurrency.json")"
     echo "[$(date --utc -Ins)] Finished processing results"
 }
 
-remove_finalizers() {
-    res=$1
-    while [ "$(oc get "$res" -A -o json | jq -rc '.items[] | select(.metadata.namespace | startswith("'"$USER_PREFIX"'"))' | wc -l)" != "0" ]; do
-        echo "## Removing finalizers for all $res"
-        while read -r line; do
-            echo "$line '{\"metadata\":{\"finalizers\":[]}}' --type=merge;"
-        done <<<"$(oc get "$res" -A -o json | jq -rc '.items[] | select(.metadata.namespace | startswith("'"$USER_PREFIX"'")) | "oc patch '"$res"' " + .metadata.name + " -n " + .metadata.namespace + " -p "')" | bash -s
-    done
-}
-
-clean_namespaces() {
-    echo "Deleting resources from previous steps"
-    for res in pipelineruns.tekton.dev components.appstudio.redhat.com componentdetectionqueries.appstudio.redhat.com snapshotenvironmentbindings.appstudio.redhat.com applications.appstudio.redhat.com; do
-        echo -e " * $res"
-        if [ -n "${DELETE_INCLUDE_FINALIZERS:-}" ]; then
-            remove_finalizers "$res" &
-            echo "## Deleting all $res"
-        fi
-        oc get "$res" -A -o json | jq -rc '.items[] | select(.metadata.namespace | startswith("'"$USER_PREFIX"'"))| "oc delete '"$res"' " + .metadata.name + " -n " + .metadata.namespace + " --ignore-not-found=true;"' | bash -s
-    done
-    oc get usersignups.toolchain.dev.openshift.com -A -o name | grep "$USER_PREFIX" | xargs oc delete -n toolchain-host-operator --ignore-not-found=true
-    attempts=60
-    attempt=1
-    sleep="5s"
-    while [ "$attempt" -le "$attempts" ]; do
-        echo " * Waiting $sleep until all namespaces with '$USER_PREFIX' prefix are gone [attempt $attempt/$attempts]"
-        oc get ns | grep -E "^$USER_PREFIX" >/dev/null 2>&1 || break 1
-        sleep "$sleep"
-        attempt=$((attempt + 1))
-    done
-    if [ "$attempt" -le "$attempts" ]; then
-        echo " * All the namespaces with '$USER_PREFIX' are gone!"
-    else
-        echo " * WARNING: Timeout waiting for namespaces with '$USER_PREFIX' to be gone. The following namespaces still exist:"
-        oc get ns | grep -E "^$USER_PREFIX"
-    fi
-}
-
-max_concurrency() {
-    local iteration index iteration_index
-    # Max length of compliant username is 20 characters. We add "-XXXX-XXXX" suffix for the test users' name so max length of the prefix is 10.
-    # See https://github.com/codeready-toolchain/toolchain-common/blob/master/pkg/usersignup/usersignup.go#L16
-    if [ ${#USER_PREFIX} -gt 10 ]; then
-        echo "Maximal allowed length of user prefix is 10 characters. The '$USER_PREFIX' length of ${#USER_PREFIX} exceeds the limit."
-        exit 1
-    else
-        output="$OUTPUT_DIR/load-test.max-concurrency.json"
-        IFS="," read -r -a maxConcurrencySteps <<<"$(echo "${MAX_CONCURRENCY_STEPS:-1\ 5\ 10\ 25\ 50\ 100\ 150\ 200}" | sed 's/ /,/g')"
-        maxThreads=${MAX_THREADS:-10}      # Do not go above this concurrency.
-        threshold_sec=${THRESHOLD:-300}    # In seconds. If KPI crosses this duration, stop.
-        threshold_err=${THRESHOLD_ERR:-10} # Failure ratio. When crossed, stop.
-        echo '{"started":"'"$(date +%FT%T%:z)"'", "maxThreads": '"$maxThreads"', "maxConcurrencySteps": "'"${maxConcurrencySteps[*]}"'", "threshold": '"$threshold_sec"', "thresholdErrors": '"$threshold_err"', "maxConcurrencyReached": 0, "computedConcurrency": 0, "workloadKPI": 0, "ended": "", "errorsTotal": -1}' | jq >"$output"
-        iteration=0
-
-        {
-            python3 -m venv venv
-            set +u
-            source venv/bin/activate
-            set -u
-            python3 -m pip install -U pip
-            python3 -m pip install -e "git+https://github.com/redhat-performance/opl.git#egg=opl-rhcloud-perf-team-core&subdirectory=core"
-            python3 -m pip install tabulate
-            python3 -m pip install matplotlib
-            deactivate
-        } &>"$OUTPUT_DIR/monitoring-setup.log"
-
-        for t in "${maxConcurrencySteps[@]}"; do
-            iteration="$((iteration + 1))"
-            if (("$t" > "$maxThreads")); then
-                break
-            fi
-            echo "[$(date --utc -Ins)] Starting iteration ${iteration} with concurrency ${t}"
-            oc login "$OPENSHIFT_API" -u "$OPENSHIFT_USERNAME" -p "$OPENSHIFT_PASSWORD"
-            clean_namespaces
-            iteration_index="$(printf "%04d" "$iteration")-$(printf "%04d" "$t")"
-            workdir="${OUTPUT_DIR}/iteration-${iteration_index}"
-            mkdir "${workdir}"
-            load_test "$workdir" "$t"
-            jq ".metadata.\"max-concurrency\".iteration = \"$(printf "%04d" "$iteration")\"" "$workdir/load-test.json" >"$OUTPUT_DIR/$$.json" && mv -f "$OUTPUT_DIR/$$.json" "$workdir/load-test.json"
-            workloadKPI=$(jq '.results.measurements.KPI.mean' "$workdir/load-test.json")
-            workloadKPIerrors=$(jq '.results.measurements.KPI.errors' "$workdir/load-test.json")
-            if [ -z "$workloadKPI" ] || [ -z "$workloadKPIerrors" ] || [ "$workloadKPI" = "null" ] || [ "$workloadKPIerrors" = "null" ]; then
-                echo "[$(date --utc -Ins)] Error getting test iteration results: $workloadKPI/$workloadKPIerrors"
-                exit 1
-            elif awk "BEGIN { exit !($workloadKPI == -1 || $workloadKPI > $threshold_sec || $workloadKPIerrors / $t * 100 > $threshold_err)}"; then
-                echo "[$(date --utc -Ins)] The average time a workload took to succeed (${workloadKPI}s) or error rate (${workloadKPIerrors}/${t}) has exceeded a threshold of ${threshold_sec}s or ${threshold_err} error rate with $t threads."
-                workloadKPIOld=$(jq '.workloadKPI' "$output")
-                threadsOld=$(jq '.maxConcurrencyReached' "$output")
-                computedConcurrency=$(python3 -c "import sys; t = float(sys.argv[1]); a = float(sys.argv[2]); b = float(sys.argv[3]); c = float(sys.argv[4]); d = float(sys.argv[5]); print((t - b) / ((d - b) / (c - a)) + a)" "$threshold_sec" "$threadsOld" "$workloadKPIOld" "$t" "$workloadKPI")
-                jq ".computedConcurrency = $computedConcurrency" "$output" >"$OUTPUT_DIR/$$.json" && mv -f "$OUTPUT_DIR/$$.json" "$output"
-                break
-            else
-                echo "[$(date --utc -Ins)] The average time a workload took to succeed (${workloadKPI}s) and error rate (${workloadKPIerrors}/${t}) looks good with $t threads."
-                jq ".maxConcurrencyReached = $t" "$output" >"$OUTPUT_DIR/$$.json" && mv -f "$OUTPUT_DIR/$$.json" "$output"
-                jq ".workloadKPI = $workloadKPI" "$output" >"$OUTPUT_DIR/$$.json" && mv -f "$OUTPUT_DIR/$$.json" "$output"
-                jq ".computedConcurrency = $t" "$output" >"$OUTPUT_DIR/$$.json" && mv -f "$OUTPUT_DIR/$$.json" "$output"
-                jq '.ended = "'"$(date +%FT%T%:z)"'"' "$output" >"$OUTPUT_DIR/$$.json" && mv -f "$OUTPUT_DIR/$$.json" "$output"
-                jq ".errorsTotal = $workloadKPIerrors" "$output" >"$OUTPUT_DIR/$$.json" && mv -f "$OUTPUT_DIR/$$.json" "$output"
-            fi
-        done
-    fi
-}
-
 max_concurrency
@@ -26,6 +26,7 @@ var _ = framework.ReleaseServiceSuiteDescribe("[HACBS-2469]test-releaseplan-owne
 	BeforeAll(func() {
 		fw, err = framework.NewFramework(utils.GetGeneratedNamespace("rp-ownerref"))
 		Expect(err).NotTo(HaveOccurred())
+		fw.DeleteUserOnCleanup()
 		devNamespace = fw.UserNamespace
 
 		_, err = fw.AsKubeAdmin.HasController.CreateApplication(releasecommon.ApplicationNameDefault, devNamespace)
@@ -35,12 +36,6 @@ var _ = framework.ReleaseServiceSuiteDescribe("[HACBS-2469]test-releaseplan-owne
 		Expect(err).NotTo(HaveOccurred())
 	})
 
-	AfterAll(func() {
-		if !CurrentSpecReport().Failed() {
-			Expect(fw.SandboxController.DeleteUserSignup(fw.UserName)).To(BeTrue())
-		}
-	})
-
 	var _ = Describe("ReleasePlan verification", Ordered, func() {
 		It("verifies that the ReleasePlan has an owner reference for the application", func() {
 			Eventually(func() error {
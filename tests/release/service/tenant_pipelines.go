@@ -41,6 +41,7 @@ var _ = framework.ReleaseServiceSuiteDescribe("Release service tenant pipeline",
 		// Initialize the tests controllers
 		fw, err = framework.NewFramework(utils.GetGeneratedNamespace("tenant-dev"))
 		Expect(err).NotTo(HaveOccurred())
+		fw.DeleteUserOnCleanup()
 		devNamespace = fw.UserNamespace
 
 		sourceAuthJson := utils.GetEnv("QUAY_TOKEN", "")
@@ -93,12 +94,6 @@ var _ = framework.ReleaseServiceSuiteDescribe("Release service tenant pipeline",
 		Expect(err).ShouldNot(HaveOccurred())
 	})
 
-	AfterAll(func() {
-		if !CurrentSpecReport().Failed() {
-			Expect(fw.SandboxController.DeleteUserSignup(fw.UserName)).To(BeTrue())
-		}
-	})
-
 	var _ = Describe("Post-release verification", func() {
 
 		It("verifies that a Release CR should have been created in the dev namespace", func() {